@@ -0,0 +1,2096 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"bscli/internal/cli"
+)
+
+// testVersion is the version reported by the subprocess CLI
+const testVersion = "1.2.3-test"
+
+// The tests re-run the test binary as the CLI so exit codes can be observed.
+// When BSCLI_MAIN_ARGS is set the binary behaves like bscli with those
+// (newline separated) arguments.
+func TestMain(m *testing.M) {
+	if args, ok := os.LookupEnv("BSCLI_MAIN_ARGS"); ok {
+		cli.Version = testVersion
+		os.Args = append([]string{"bscli"}, strings.Split(args, "\n")...)
+		main()
+		os.Exit(0)
+	}
+
+	// Keep the subprocesses' auth cache out of the user's cache directory
+	cacheDir, err := os.MkdirTemp("", "bscli-cache-")
+	if err != nil {
+		panic(err)
+	}
+	os.Setenv("BSCLI_CACHE_DIR", cacheDir)
+	code := m.Run()
+	os.RemoveAll(cacheDir)
+	os.Exit(code)
+}
+
+// runMain runs bscli in a subprocess and returns its stdout, stderr and exit code
+func runMain(t *testing.T, args ...string) (string, string, int) {
+	t.Helper()
+	return runMainWithInput(t, "", args...)
+}
+
+// runMainWithInput is like runMain but feeds stdin to the subprocess
+func runMainWithInput(t *testing.T, stdin string, args ...string) (string, string, int) {
+	t.Helper()
+
+	cmd := exec.Command(os.Args[0])
+	cmd.Env = append(os.Environ(), "BSCLI_MAIN_ARGS="+strings.Join(args, "\n"))
+	cmd.Stdin = strings.NewReader(stdin)
+
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return stdout.String(), stderr.String(), exitErr.ExitCode()
+	} else if err != nil {
+		t.Fatalf("Failed to run subprocess: %v", err)
+	}
+
+	return stdout.String(), stderr.String(), 0
+}
+
+func TestExitCodes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/health/":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"data":{"result":{"status":"running"}}}`))
+		case "/api/v1/time/":
+			w.Header().Set("WWW-Authenticate", `Digest realm="BrightSign", nonce="abc123", qop="auth"`)
+			w.WriteHeader(http.StatusUnauthorized)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	host := server.URL[7:]
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closedHost := closed.URL[7:]
+	closed.Close()
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected int
+	}{
+		{"Success", []string{host, "-p", "pw", "info", "health"}, 0},
+		{"AuthFailure", []string{host, "-p", "pw", "info", "time"}, 2},
+		{"NotFound", []string{host, "-p", "pw", "info", "device"}, 3},
+		{"NetworkFailure", []string{closedHost, "-p", "pw", "info", "health"}, 4},
+		{"UnknownCommand", []string{host, "-p", "pw", "bogus"}, 5},
+		{"WrongArgCount", []string{host, "-p", "pw", "registry", "get", "only-section"}, 5},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stdout, stderr, code := runMain(t, test.args...)
+			if code != test.expected {
+				t.Errorf("Expected exit code %d, got %d\nstdout: %s\nstderr: %s", test.expected, code, stdout, stderr)
+			}
+		})
+	}
+}
+
+func TestJSONErrorGoesToStderr(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		w.Write([]byte("no such file"))
+	}))
+	defer server.Close()
+
+	stdout, stderr, code := runMain(t, server.URL[7:], "-p", "pw", "--json", "file", "list", "/storage/usb1/")
+
+	if code != 3 {
+		t.Errorf("Expected exit code 3, got %d", code)
+	}
+
+	if stdout != "" {
+		t.Errorf("Expected empty stdout in JSON error mode, got %q", stdout)
+	}
+
+	var errorObj struct {
+		Error string `json:"error"`
+		Code  int    `json:"code"`
+	}
+	if err := json.Unmarshal([]byte(stderr), &errorObj); err != nil {
+		t.Fatalf("Expected JSON error object on stderr, got %q: %v", stderr, err)
+	}
+
+	if !strings.Contains(errorObj.Error, "404") {
+		t.Errorf("Expected error to mention status 404, got %q", errorObj.Error)
+	}
+
+	if errorObj.Code != 3 {
+		t.Errorf("Expected code 3 in error object, got %d", errorObj.Code)
+	}
+}
+
+func TestJSONSuccessEnvelope(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{}}}`))
+	}))
+	defer server.Close()
+
+	stdout, stderr, code := runMain(t, server.URL[7:], "-p", "pw", "--json", "registry", "set", "networking", "foo", "bar")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d (stderr: %s)", code, stderr)
+	}
+
+	var result map[string]interface{}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("Expected JSON on stdout, got %q: %v", stdout, err)
+	}
+
+	if result["success"] != true {
+		t.Errorf("Expected success=true, got %v", result["success"])
+	}
+	if _, hasError := result["error"]; hasError {
+		t.Error("Success envelope must not contain an error key")
+	}
+	if result["action"] != "set" {
+		t.Errorf("Expected action 'set', got %v", result["action"])
+	}
+}
+
+func TestDecodeEDIDWithoutHost(t *testing.T) {
+	hexFile := filepath.Join(t.TempDir(), "edid.hex")
+	edid := "00ffffffffffff0010ac7ba0414e4c300c1a010380351e78eaee95a3544c99260f50542108008180d1c0010101010101010101010101023a801871382d40582c4500132b2100001e000000ff00434656394e32414c304b554c0a000000fc0044454c4c2055323431324d0a20000000fd00384c1e5311000a20202020202000ad"
+	if err := os.WriteFile(hexFile, []byte(edid+"\n"), 0644); err != nil {
+		t.Fatalf("Failed to write EDID file: %v", err)
+	}
+
+	stdout, stderr, code := runMain(t, "video", "decode-edid", hexFile, "--json")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d (stderr: %s)", code, stderr)
+	}
+
+	var result struct {
+		Manufacturer   string   `json:"manufacturer"`
+		Product        string   `json:"product"`
+		SupportedModes []string `json:"supportedModes"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("Expected JSON on stdout, got %q: %v", stdout, err)
+	}
+
+	if result.Manufacturer != "DEL" || result.Product != "DELL U2412M" {
+		t.Errorf("Unexpected EDID identity: %+v", result)
+	}
+	if len(result.SupportedModes) == 0 || result.SupportedModes[0] != "1920x1080x60p" {
+		t.Errorf("Expected preferred mode 1920x1080x60p first, got %v", result.SupportedModes)
+	}
+}
+
+func TestYesSkipsConfirmation(t *testing.T) {
+	var deletes int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "DELETE" {
+			deletes++
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{}}}`))
+	}))
+	defer server.Close()
+	host := server.URL[7:]
+
+	tests := []struct {
+		name    string
+		args    []string
+		deletes int
+	}{
+		{"NoFlagDeclined", []string{host, "-p", "pw", "registry", "delete", "networking", "foo"}, 0},
+		{"Yes", []string{host, "-p", "pw", "--yes", "registry", "delete", "networking", "foo"}, 1},
+		{"ShortYes", []string{host, "-p", "pw", "-y", "file", "delete", "/storage/sd/a.txt"}, 1},
+		{"AssumeYes", []string{host, "-p", "pw", "--assume-yes", "registry", "delete-section", "networking"}, 1},
+		{"Force", []string{host, "-p", "pw", "registry", "delete", "networking", "foo", "--force"}, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			deletes = 0
+
+			// A "n" on stdin would cancel the command if it were read
+			stdout, stderr, code := runMainWithInput(t, "n\n", test.args...)
+			if code != 0 {
+				t.Fatalf("Expected exit code 0, got %d (stderr: %s)", code, stderr)
+			}
+
+			if deletes != test.deletes {
+				t.Errorf("Expected %d DELETE requests, got %d\nstdout: %s", test.deletes, deletes, stdout)
+			}
+		})
+	}
+}
+
+func TestRegistryHasExitCode(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/registry/networking/present/":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"data":{"result":{"value":"1"}}}`))
+		case "/api/v1/registry/networking/absent/":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer server.Close()
+	host := server.URL[7:]
+
+	tests := []struct {
+		name   string
+		args   []string
+		stdout string
+		code   int
+	}{
+		{"Present", []string{host, "-p", "pw", "registry", "has", "networking", "present"}, "true\n", 0},
+		{"Absent", []string{host, "-p", "pw", "registry", "has", "networking", "absent"}, "false\n", 1},
+		{"AbsentJSON", []string{host, "-p", "pw", "-j", "registry", "has", "networking", "absent"}, "{\"exists\":false}\n", 1},
+		{"Error", []string{host, "-p", "pw", "registry", "has", "networking", "denied"}, "", 2},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stdout, stderr, code := runMain(t, test.args...)
+			if code != test.code {
+				t.Errorf("Expected exit code %d, got %d (stderr: %s)", test.code, code, stderr)
+			}
+			if stdout != test.stdout {
+				t.Errorf("Expected stdout %q, got %q", test.stdout, stdout)
+			}
+		})
+	}
+}
+
+func TestVersionWithoutHost(t *testing.T) {
+	stdout, stderr, code := runMain(t, "version")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d (stderr: %s)", code, stderr)
+	}
+	if !strings.HasPrefix(stdout, "bscli "+testVersion+"\n") {
+		t.Errorf("Expected version %s in output, got %q", testVersion, stdout)
+	}
+
+	stdout, _, code = runMain(t, "version", "--json")
+	var result map[string]interface{}
+	if code != 0 || json.Unmarshal([]byte(stdout), &result) != nil || result["version"] != testVersion {
+		t.Errorf("Expected JSON with version %s, got %q (exit %d)", testVersion, stdout, code)
+	}
+	if _, ok := result["player"]; ok {
+		t.Errorf("Expected no player info without a host, got %v", result["player"])
+	}
+}
+
+func TestVersionWithHost(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{"model":"XT1144","fwVersion":"9.0.110"}}}`))
+	}))
+	defer server.Close()
+
+	stdout, stderr, code := runMain(t, server.URL[7:], "-p", "pw", "version")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d (stderr: %s)", code, stderr)
+	}
+	if !strings.Contains(stdout, ": XT1144 firmware 9.0.110") {
+		t.Errorf("Expected player version line, got %q", stdout)
+	}
+}
+
+func TestInfoDiffTwoHosts(t *testing.T) {
+	newPlayer := func(fwVersion string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"data":{"result":{"model":"XT1144","fwVersion":"` + fwVersion + `"}}}`))
+		}))
+	}
+	a := newPlayer("9.0.110")
+	defer a.Close()
+	b := newPlayer("8.5.47")
+	defer b.Close()
+
+	for _, args := range [][]string{
+		{"info", "diff", a.URL[7:], b.URL[7:], "-p", "pw", "-j"},
+		{a.URL[7:], "-p", "pw", "-j", "info", "diff", "--against", b.URL[7:]},
+	} {
+		stdout, stderr, code := runMain(t, args...)
+		if code != 0 {
+			t.Fatalf("Expected exit code 0 for %v, got %d (stderr: %s)", args, code, stderr)
+		}
+
+		var diffs []map[string]string
+		if err := json.Unmarshal([]byte(stdout), &diffs); err != nil {
+			t.Fatalf("Expected JSON diff list, got %q: %v", stdout, err)
+		}
+		if len(diffs) != 1 || diffs[0]["field"] != "fwVersion" || diffs[0]["a"] != "9.0.110" || diffs[0]["b"] != "8.5.47" {
+			t.Errorf("Unexpected diff for %v: %v", args, diffs)
+		}
+	}
+}
+
+func TestQuietSuppressesProgress(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "PUT" && r.URL.Path == "/api/v1/files/sd/":
+			w.Write([]byte(`{"data":{"result":{"success":true}}}`))
+		case r.Method == "GET" && r.URL.Path == "/api/v1/registry/networking/ssh/":
+			w.Write([]byte(`{"data":{"result":{"value":"22"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	host := server.URL[7:]
+
+	localPath := filepath.Join(t.TempDir(), "video.mp4")
+	if err := os.WriteFile(localPath, []byte("data"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	stdout, stderr, code := runMain(t, host, "-p", "pw", "file", "upload", localPath, "/storage/sd/video.mp4")
+	if code != 0 {
+		t.Fatalf("Upload failed with exit code %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "Uploading") || !strings.Contains(stdout, "Upload complete") {
+		t.Errorf("Expected progress lines without --quiet, got %q", stdout)
+	}
+
+	stdout, stderr, code = runMain(t, host, "-p", "pw", "--quiet", "file", "upload", localPath, "/storage/sd/video.mp4")
+	if code != 0 {
+		t.Fatalf("Quiet upload failed with exit code %d: %s", code, stderr)
+	}
+	if stdout != "" {
+		t.Errorf("Expected no output with --quiet, got %q", stdout)
+	}
+
+	stdout, stderr, code = runMain(t, host, "-p", "pw", "-q", "registry", "get", "networking", "ssh")
+	if code != 0 {
+		t.Fatalf("Quiet get failed with exit code %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "22") {
+		t.Errorf("Expected the value to be printed with --quiet, got %q", stdout)
+	}
+}
+
+func TestNonNumericArgumentIsUsageError(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+	host := server.URL[7:]
+
+	tests := []struct {
+		args    []string
+		message string
+	}{
+		{[]string{host, "-p", "pw", "display", "volume", "set", "abc"}, "must be an integer"},
+		{[]string{host, "-p", "pw", "logs", "supervisor", "set-level", "high"}, "must be 0-3 or one of error, warn, info, trace"},
+	}
+
+	for _, test := range tests {
+		args := test.args
+		_, stderr, code := runMain(t, args...)
+		if code != 5 {
+			t.Errorf("%v: expected exit code 5, got %d (stderr: %s)", args[3:], code, stderr)
+		}
+		if !strings.Contains(stderr, test.message) {
+			t.Errorf("%v: expected a parse error, got %q", args[3:], stderr)
+		}
+	}
+
+	if requests != 0 {
+		t.Errorf("Expected no requests for invalid input, got %d", requests)
+	}
+}
+
+func TestDisplayAndVideoJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/display-control/volume/":
+			w.Write([]byte(`{"data":{"result":{"value":30,"min":0,"max":100}}}`))
+		case "/api/v1/video/hdmi/output/0/power-save/":
+			w.Write([]byte(`{"data":{"result":{"enabled":true}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	host := server.URL[7:]
+
+	tests := []struct {
+		args []string
+		key  string
+	}{
+		{[]string{"display", "volume", "get"}, "value"},
+		{[]string{"video", "power-save", "get", "hdmi", "0"}, "enabled"},
+	}
+
+	for _, test := range tests {
+		stdout, stderr, code := runMain(t, append([]string{host, "-p", "pw", "-j"}, test.args...)...)
+		if code != 0 {
+			t.Fatalf("%v failed with exit code %d: %s", test.args, code, stderr)
+		}
+
+		var result map[string]interface{}
+		if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+			t.Fatalf("%v: invalid JSON %q: %v", test.args, stdout, err)
+		}
+		if _, ok := result[test.key]; !ok {
+			t.Errorf("%v: expected key %q in %v", test.args, test.key, result)
+		}
+	}
+}
+
+func TestFormatValidatesDevice(t *testing.T) {
+	var mu sync.Mutex
+	var formats []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "DELETE":
+			mu.Lock()
+			formats = append(formats, r.URL.Path)
+			mu.Unlock()
+			w.Write([]byte(`{"data":{"result":{}}}`))
+		case r.URL.Path == "/api/v1/files/sd/":
+			w.Write([]byte(`{"data":{"result":[{"name":"autorun.brs","type":"file"}]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	host := server.URL[7:]
+
+	tests := []struct {
+		name    string
+		args    []string
+		stdin   string
+		code    int
+		formats int
+	}{
+		{"UnknownDevice", []string{host, "-p", "pw", "file", "format", "sdd", "--force"}, "", 5, 0},
+		{"UnknownAllowed", []string{host, "-p", "pw", "file", "format", "flash", "--force", "--allow-unknown"}, "", 0, 1},
+		{"AutorunDeclined", []string{host, "-p", "pw", "file", "format", "usb1"}, "usb1\nn\n", 0, 0},
+		{"AutorunConfirmed", []string{host, "-p", "pw", "file", "format", "usb1"}, "usb1\ny\n", 0, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mu.Lock()
+			formats = nil
+			mu.Unlock()
+
+			stdout, stderr, code := runMainWithInput(t, test.stdin, test.args...)
+			if code != test.code {
+				t.Fatalf("Expected exit code %d, got %d (stderr: %s)", test.code, code, stderr)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if len(formats) != test.formats {
+				t.Errorf("Expected %d format requests, got %v\nstdout: %s", test.formats, formats, stdout)
+			}
+		})
+	}
+}
+
+func TestDryRunSendsNothing(t *testing.T) {
+	var mu sync.Mutex
+	var sent []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/files/sd/":
+			w.Write([]byte(`{"data":{"result":[{"name":"media","type":"directory"}]}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/files/sd/media/":
+			w.Write([]byte(`{"data":{"result":[{"name":"a.mp4","type":"file"}]}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/api/v1/registry/":
+			w.Write([]byte(`{"data":{"result":{"networking":{"hostname":"player1"},"html":{"url":"http://example.com"}}}}`))
+		default:
+			mu.Lock()
+			sent = append(sent, r.Method+" "+r.URL.Path)
+			mu.Unlock()
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+	host := server.URL[7:]
+	base := "http://" + host + "/api/v1"
+
+	dir := t.TempDir()
+	os.WriteFile(filepath.Join(dir, "a.mp4"), []byte("a"), 0644)
+	os.WriteFile(filepath.Join(dir, "b.mp4"), []byte("b"), 0644)
+	manifest := filepath.Join(dir, "manifest.txt")
+	os.WriteFile(manifest, []byte("a.mp4 -> /storage/sd/a.mp4\nb.mp4 -> /storage/sd/media/b.mp4\n"), 0644)
+
+	tests := []struct {
+		name     string
+		args     []string
+		expected []string
+	}{
+		{"delete", []string{"file", "delete", "/storage/sd/a.txt"}, []string{"DELETE " + base + "/files/sd/a.txt"}},
+		{"recursive delete", []string{"file", "delete", "-r", "/storage/sd/media"},
+			[]string{"DELETE " + base + "/files/sd/media/a.mp4", "DELETE " + base + "/files/sd/media/"}},
+		{"snapshot", []string{"control", "snapshot"}, []string{"POST " + base + "/snapshot/"}},
+		{"reboot", []string{"control", "reboot"}, []string{"PUT " + base + "/control/reboot/"}},
+		{"registry copy", []string{"registry", "copy", "--to", strings.Replace(host, "127.0.0.1", "localhost", 1)},
+			[]string{"/registry/html/url/", "/registry/networking/hostname/"}},
+		{"registry set-many", []string{"registry", "set-many", "networking", "a=1", "b=2"},
+			[]string{"PUT " + base + "/registry/networking/a/", "PUT " + base + "/registry/networking/b/"}},
+		{"upload batch", []string{"file", "upload-batch", manifest},
+			[]string{"PUT " + base + "/files/sd/\n", "PUT " + base + "/files/sd/media/\n"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			mu.Lock()
+			sent = nil
+			mu.Unlock()
+
+			args := append([]string{host, "-p", "pw", "--dry-run", "--yes"}, test.args...)
+			stdout, stderr, code := runMain(t, args...)
+			if code != 0 {
+				t.Fatalf("Expected exit code 0, got %d (stderr: %s)", code, stderr)
+			}
+			if len(sent) != 0 {
+				t.Errorf("Expected no mutating requests with --dry-run, got %v", sent)
+			}
+			for _, expected := range test.expected {
+				if !strings.Contains(stderr, expected) {
+					t.Errorf("Expected %q to be printed, got %q", expected, stderr)
+				}
+			}
+			if !strings.Contains(stderr, "DRY RUN: would send ") || strings.Contains(stderr, "Error") {
+				t.Errorf("Expected only the skipped requests on stderr, got %q", stderr)
+			}
+			// Nothing happened, so nothing is reported as done
+			for _, done := range []string{"Deleted", "Copied", "saved", "success"} {
+				if strings.Contains(stdout, done) {
+					t.Errorf("Expected no success report for a dry run, got %q", stdout)
+				}
+			}
+		})
+	}
+}
+
+func TestAPIPassthrough(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.Method == "GET" && r.URL.Path == "/api/v1/info/":
+			w.Write([]byte(`{"data":{"result":{"model":"XT1144"}}}`))
+		case r.Method == "PUT" && r.URL.Path == "/api/v1/registry/networking/ssh/":
+			var body map[string]string
+			json.NewDecoder(r.Body).Decode(&body)
+			w.Write([]byte(`{"data":{"result":{"value":"` + body["value"] + `"}}}`))
+		case r.Method == "PUT" && r.URL.Path == "/api/v1/control/dws-password/":
+			// Echoes the request, as some firmware does in validation errors
+			data, _ := io.ReadAll(r.Body)
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write(data)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"error":"not found"}`))
+		}
+	}))
+	defer server.Close()
+	host := server.URL[7:]
+
+	// The body of a failed request is redacted like any other error
+	_, stderr, code := runMainWithInput(t, `{"password":"hunter2"}`, host, "-p", "pw", "api", "PUT", "/control/dws-password/", "--body", "-")
+	if code != 1 || strings.Contains(stderr, "hunter2") || !strings.Contains(stderr, "[REDACTED]") {
+		t.Errorf("Expected exit code 1 and a redacted error, got %d and %q", code, stderr)
+	}
+
+	tests := []struct {
+		name   string
+		args   []string
+		stdin  string
+		stdout string
+		code   int
+	}{
+		{"Get", []string{host, "-p", "pw", "-j", "api", "GET", "/info/"}, "", `{"data":{"result":{"model":"XT1144"}}}` + "\n", 0},
+		{"DefaultMethod", []string{host, "-p", "pw", "-j", "api", "info/"}, "", `{"data":{"result":{"model":"XT1144"}}}` + "\n", 0},
+		{"BodyFromStdin", []string{host, "-p", "pw", "-j", "api", "put", "/registry/networking/ssh/", "--body", "-"}, `{"value":"22"}`, `{"data":{"result":{"value":"22"}}}` + "\n", 0},
+		{"NotFound", []string{host, "-p", "pw", "api", "/missing/"}, "", "", 3},
+		{"InvalidMethod", []string{host, "-p", "pw", "api", "FETCH", "/info/"}, "", "", 5},
+		{"InvalidBody", []string{host, "-p", "pw", "api", "PUT", "/info/", "--body", "-"}, "not json", "", 5},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			stdout, stderr, code := runMainWithInput(t, test.stdin, test.args...)
+			if code != test.code {
+				t.Fatalf("Expected exit code %d, got %d (stderr: %s)", test.code, code, stderr)
+			}
+			if stdout != test.stdout {
+				t.Errorf("Expected stdout %q, got %q", test.stdout, stdout)
+			}
+		})
+	}
+}
+
+func TestShellRunsScriptedCommands(t *testing.T) {
+	var challenges, requests, deletes int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodDelete {
+			deletes++
+		}
+		if r.Header.Get("Authorization") == "" {
+			challenges++
+			w.Header().Set("WWW-Authenticate", `Digest realm="BrightSign", nonce="abc123", qop="auth"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/health/":
+			w.Write([]byte(`{"data":{"result":{"status":"running","statusTime":"now"}}}`))
+		case "/api/v1/registry/networking/ssh/":
+			w.Write([]byte(`{"data":{"result":{"value":"22"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	host := server.URL[7:]
+
+	script := strings.Join([]string{
+		"info health",
+		"-j registry get networking ssh",
+		"registry get networking missing",
+		"no-such-command",
+		"registry get networking ssh",
+		"--dry-run file delete -f /storage/sd/x.txt",
+		"exit",
+		"info health",
+	}, "\n") + "\n"
+
+	stdout, stderr, code := runMainWithInput(t, script, host, "-p", "pw", "shell")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d (stderr: %s)", code, stderr)
+	}
+
+	if strings.Count(stdout, "running") != 1 {
+		t.Errorf("Expected one health result before exit, got:\n%s", stdout)
+	}
+	if !strings.Contains(stdout, `{"key":"ssh","section":"networking","value":"22"}`) {
+		t.Errorf("Expected JSON output for the -j line, got:\n%s", stdout)
+	}
+	if !strings.Contains(stdout, "networking/ssh = 22") {
+		t.Errorf("Expected -j not to leak into the next line, got:\n%s", stdout)
+	}
+	if !strings.Contains(stderr, "not found") || !strings.Contains(stderr, "no-such-command") {
+		t.Errorf("Expected errors for the failing lines, got:\n%s", stderr)
+	}
+
+	// Client flags on a line apply to that line only
+	if deletes != 0 {
+		t.Errorf("Expected --dry-run at the prompt to send no DELETE, got %d", deletes)
+	}
+
+	// The digest challenge is fetched once and reused for every command
+	if challenges != 1 {
+		t.Errorf("Expected 1 digest challenge, got %d", challenges)
+	}
+	if requests != 4 {
+		t.Errorf("Expected 4 authenticated requests, got %d", requests)
+	}
+}
+
+func TestNoColorWhenNotATerminal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/health/":
+			w.Write([]byte(`{"data":{"result":{"status":"running","statusTime":"now"}}}`))
+		case "/api/v1/files/sd/":
+			w.Write([]byte(`{"data":{"result":[{"name":"media","type":"directory"},{"name":"a.mp4","type":"file","size":10}]}}`))
+		case "/api/v1/diagnostics/":
+			w.Write([]byte(`{"data":{"result":[{"test":"dns","status":"fail","message":"timeout"}]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	host := server.URL[7:]
+
+	for _, args := range [][]string{
+		{"info", "health"},
+		{"file", "list", "/storage/sd/"},
+		{"diagnostics", "run"},
+	} {
+		stdout, stderr, code := runMain(t, append([]string{host, "-p", "pw"}, args...)...)
+		if code != 0 {
+			t.Fatalf("%v failed with exit code %d: %s", args, code, stderr)
+		}
+		if stdout == "" {
+			t.Errorf("%v: expected output", args)
+		}
+		if strings.Contains(stdout, "\033[") {
+			t.Errorf("%v: expected no color codes in piped output, got %q", args, stdout)
+		}
+	}
+}
+
+func TestUploadIntoExistingDirectory(t *testing.T) {
+	var mu sync.Mutex
+	var uploadPath, uploadName string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == "PUT" {
+			mu.Lock()
+			defer mu.Unlock()
+			uploadPath = r.URL.Path
+			if _, header, err := r.FormFile("file"); err == nil {
+				uploadName = header.Filename
+			}
+			w.Write([]byte(`{"data":{"result":{}}}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"result":[{"name":"media","type":"directory"},{"name":"a.mp4","type":"file","size":10}]}}`))
+	}))
+	defer server.Close()
+	host := server.URL[7:]
+
+	localPath := filepath.Join(t.TempDir(), "video.mp4")
+	if err := os.WriteFile(localPath, []byte("video"), 0644); err != nil {
+		t.Fatalf("Failed to create local file: %v", err)
+	}
+
+	tests := []struct {
+		remote   string
+		apiPath  string
+		filename string
+	}{
+		{"/storage/sd/media", "/api/v1/files/sd/media/", "video.mp4"},
+		{"/storage/sd", "/api/v1/files/sd/", "video.mp4"},
+		{"/storage/sd/a.mp4", "/api/v1/files/sd/", "a.mp4"},
+	}
+	for _, test := range tests {
+		mu.Lock()
+		uploadPath, uploadName = "", ""
+		mu.Unlock()
+		_, stderr, code := runMain(t, host, "-p", "pw", "file", "upload", localPath, test.remote)
+		if code != 0 {
+			t.Fatalf("Upload to %s failed with exit code %d: %s", test.remote, code, stderr)
+		}
+		mu.Lock()
+		if uploadPath != test.apiPath || uploadName != test.filename {
+			t.Errorf("Upload to %s: expected %s%s, got %s%s", test.remote, test.apiPath, test.filename, uploadPath, uploadName)
+		}
+		mu.Unlock()
+	}
+}
+
+func TestVersionGatedCommands(t *testing.T) {
+	var flushed bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/info/":
+			w.Write([]byte(`{"data":{"result":{"model":"XD234","fwVersion":"8.5.47"}}}`))
+		case "/api/v1/registry/flush/":
+			flushed = true
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	host := server.URL[7:]
+
+	for _, test := range []struct {
+		args    []string
+		message string
+	}{
+		{[]string{"registry", "flush"}, "registry flush requires BOS 9.0.107+ (player runs 8.5.47)"},
+		{[]string{"display", "info"}, "display control requires BOS 9.0.189+ (player runs 8.5.47)"},
+	} {
+		_, stderr, code := runMain(t, append([]string{host, "-p", "pw"}, test.args...)...)
+		if code != 1 {
+			t.Errorf("%v: expected exit code 1, got %d", test.args, code)
+		}
+		if !strings.Contains(stderr, test.message) {
+			t.Errorf("%v: expected %q, got %q", test.args, test.message, stderr)
+		}
+	}
+
+	if flushed {
+		t.Error("Expected registry flush not to be sent to old firmware")
+	}
+}
+
+func TestHostFromEnvironment(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{"status":"running","statusTime":"now"}}}`))
+	}))
+	defer server.Close()
+
+	t.Setenv("BSCLI_TEST_HOST", "192.0.2.1:1")
+	t.Setenv("BSCLI_HOST", server.URL[7:])
+	t.Setenv("BSCLI_PASSWORD", "pw")
+
+	stdout, stderr, code := runMain(t, "info", "health")
+	if code != 0 {
+		t.Fatalf("Expected success with host from BSCLI_HOST, got exit code %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "running") {
+		t.Errorf("Expected health output, got %q", stdout)
+	}
+}
+
+func TestFileSync(t *testing.T) {
+	var mu sync.Mutex
+	var changes []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case "GET":
+			switch r.URL.Path {
+			case "/api/v1/files/sd/show/":
+				w.Write([]byte(`{"data":{"result":[{"name":"same.mp4","type":"file","size":4},{"name":"changed.mp4","type":"file","size":3},{"name":"stale.mp4","type":"file","size":5},{"name":"media","type":"directory"}]}}`))
+			case "/api/v1/files/sd/show/media/":
+				w.Write([]byte(`{"data":{"result":[]}}`))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+			return
+		case "PUT":
+			if _, header, err := r.FormFile("file"); err == nil {
+				mu.Lock()
+				changes = append(changes, "upload "+r.URL.Path+header.Filename)
+				mu.Unlock()
+			}
+		case "DELETE":
+			mu.Lock()
+			changes = append(changes, "delete "+r.URL.Path)
+			mu.Unlock()
+		}
+		w.Write([]byte(`{"data":{"result":{}}}`))
+	}))
+	defer server.Close()
+	host := server.URL[7:]
+
+	localDir := t.TempDir()
+	for name, content := range map[string]string{"same.mp4": "same", "changed.mp4": "changed", "new.mp4": "new", "media/clip.mp4": "clip"} {
+		localPath := filepath.Join(localDir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(localPath), 0755)
+		if err := os.WriteFile(localPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+
+	// --dry-run only prints the plan
+	stdout, stderr, code := runMain(t, host, "-p", "pw", "--dry-run", "file", "sync", localDir, "/storage/sd/show", "--delete")
+	if code != 0 {
+		t.Fatalf("Dry-run sync failed with exit code %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "Plan: 3 to upload, 1 to delete, 0 directories to create") {
+		t.Errorf("Expected plan summary, got %q", stdout)
+	}
+	mu.Lock()
+	if len(changes) != 0 {
+		t.Fatalf("Expected no changes in dry-run, got %v", changes)
+	}
+	mu.Unlock()
+
+	_, stderr, code = runMain(t, host, "-p", "pw", "file", "sync", localDir, "/storage/sd/show", "--delete", "--force")
+	if code != 0 {
+		t.Fatalf("Sync failed with exit code %d: %s", code, stderr)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	sort.Strings(changes)
+	expected := []string{
+		"delete /api/v1/files/sd/show/stale.mp4",
+		"upload /api/v1/files/sd/show/changed.mp4",
+		"upload /api/v1/files/sd/show/media/clip.mp4",
+		"upload /api/v1/files/sd/show/new.mp4",
+	}
+	if strings.Join(changes, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected changes:\n%s\nwant:\n%s", strings.Join(changes, "\n"), strings.Join(expected, "\n"))
+	}
+}
+
+func TestInfoWithoutSubcommand(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/info/":
+			w.Write([]byte(`{"data":{"result":{"model":"XT1144","serial":"ABC123"}}}`))
+		case "/api/v1/health/":
+			w.Write([]byte(`{"data":{"result":{"status":"running"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	host := server.URL[7:]
+
+	stdout, stderr, code := runMain(t, host, "-p", "pw", "info")
+	if code != 0 {
+		t.Fatalf("info failed with exit code %d: %s", code, stderr)
+	}
+	for _, want := range []string{"Serial: ABC123", "Status: running", "unavailable:"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("Expected %q in output, got %q", want, stdout)
+		}
+	}
+
+	stdout, _, code = runMain(t, host, "-p", "pw", "-j", "info", "all")
+	var status map[string]interface{}
+	if code != 0 || json.Unmarshal([]byte(stdout), &status) != nil {
+		t.Fatalf("Expected JSON status, got exit code %d: %q", code, stdout)
+	}
+	if _, ok := status["errors"].(map[string]interface{})["time"]; !ok {
+		t.Errorf("Expected the time failure to be reported, got %v", status)
+	}
+
+	if _, _, code := runMain(t, host, "-p", "pw", "info", "bogus"); code != 5 {
+		t.Errorf("Expected usage exit code for an unknown info subcommand, got %d", code)
+	}
+}
+
+func TestNullRegistry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":null}}`))
+	}))
+	defer server.Close()
+	host := server.URL[7:]
+
+	for _, test := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"registry", "get-all"}, "Registry is empty\n"},
+		{[]string{"registry", "get-all", "--flat"}, "Registry is empty\n"},
+		{[]string{"-j", "registry", "get-all"}, "{}\n"},
+		{[]string{"registry", "search", "ssh"}, "Search results for 'ssh':\n  No matches found\n"},
+	} {
+		stdout, stderr, code := runMain(t, append([]string{host, "-p", "pw"}, test.args...)...)
+		if code != 0 {
+			t.Fatalf("%v failed with exit code %d: %s", test.args, code, stderr)
+		}
+		if stdout != test.want {
+			t.Errorf("%v: expected %q, got %q", test.args, test.want, stdout)
+		}
+	}
+}
+
+func TestControlDisplayPowerAliases(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == "GET" {
+			w.Write([]byte(`{"data":{"result":{"model":"XT1144","fwVersion":"9.0.189"}}}`))
+			return
+		}
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+string(body))
+		mu.Unlock()
+		w.Write([]byte(`{"data":{"result":{}}}`))
+	}))
+	defer server.Close()
+	host := server.URL[7:]
+
+	for _, pair := range [][2][]string{
+		{{"display", "power", "standby"}, {"control", "display-off"}},
+		{{"display", "power", "on"}, {"control", "display-on"}},
+	} {
+		mu.Lock()
+		requests = nil
+		mu.Unlock()
+		for _, args := range pair {
+			stdout, stderr, code := runMain(t, append([]string{host, "-p", "pw"}, args...)...)
+			if code != 0 {
+				t.Fatalf("%v failed with exit code %d: %s", args, code, stderr)
+			}
+			if stdout == "" {
+				t.Errorf("%v: expected confirmation output", args)
+			}
+		}
+		mu.Lock()
+		if len(requests) != 2 || requests[0] != requests[1] {
+			t.Errorf("Expected %v and %v to send the same request, got %q", pair[0], pair[1], requests)
+		}
+		mu.Unlock()
+	}
+}
+
+func TestDiagnosticsTargets(t *testing.T) {
+	var mu sync.Mutex
+	var paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.EscapedPath()+"?"+r.URL.RawQuery)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{"success":true}}}`))
+	}))
+	defer server.Close()
+	host := server.URL[7:]
+
+	if _, stderr, code := runMain(t, host, "-p", "pw", "diagnostics", "ping", "::1"); code != 0 {
+		t.Fatalf("ping ::1 failed with code %d: %s", code, stderr)
+	}
+	if _, stderr, code := runMain(t, host, "-p", "pw", "diagnostics", "dns-lookup", "example.com", "--ipv6"); code != 0 {
+		t.Fatalf("dns-lookup --ipv6 failed with code %d: %s", code, stderr)
+	}
+
+	// The server runs in this process, so requests from both subprocesses are recorded
+	expected := []string{"/api/v1/diagnostics/ping/%3A%3A1?", "/api/v1/diagnostics/dns-lookup/example.com?recordType=AAAA"}
+	mu.Lock()
+	got := append([]string(nil), paths...)
+	mu.Unlock()
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected requests %v, got %v", expected, got)
+	}
+
+	for _, args := range [][]string{
+		{"diagnostics", "ping", "bad host"},
+		{"diagnostics", "traceroute", "a/b"},
+		{"diagnostics", "dns-lookup", "example.com", "--ipv6", "--type", "MX"},
+	} {
+		if _, _, code := runMain(t, append([]string{host, "-p", "pw"}, args...)...); code != 5 {
+			t.Errorf("%v: expected exit code 5, got %d", args, code)
+		}
+	}
+}
+
+func TestTLSFlags(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{"model":"XT1144","serial":"TLS123"}}}`))
+	}))
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	caPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	if err := os.WriteFile(caFile, caPEM, 0644); err != nil {
+		t.Fatalf("Failed to write CA file: %v", err)
+	}
+
+	for _, args := range [][]string{
+		{"--cacert", caFile},
+		{"--insecure"},
+		{"--local"},
+	} {
+		stdout, stderr, code := runMain(t, append([]string{host, "-p", "pw", "--no-auth-cache"}, append(args, "info", "device")...)...)
+		if code != 0 {
+			t.Errorf("%v: expected success, got code %d: %s", args, code, stderr)
+		}
+		if !strings.Contains(stdout, "TLS123") {
+			t.Errorf("%v: expected device info, got %q", args, stdout)
+		}
+	}
+
+	// --https verifies against the system roots, which do not include the test CA
+	_, stderr, code := runMain(t, host, "-p", "pw", "--no-auth-cache", "--https", "info", "device")
+	if code == 0 {
+		t.Fatal("Expected --https to reject an unknown certificate")
+	}
+	if !strings.Contains(stderr, "--cacert") {
+		t.Errorf("Expected a --cacert suggestion, got %q", stderr)
+	}
+
+	if _, _, code := runMain(t, host, "-p", "pw", "--cacert", filepath.Join(t.TempDir(), "missing.pem"), "info", "device"); code == 0 {
+		t.Error("Expected a missing CA file to fail")
+	}
+}
+
+func TestClientCertificateFlags(t *testing.T) {
+	var requests int
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+	}))
+	defer server.Close()
+	host := server.URL[7:]
+
+	missing := filepath.Join(t.TempDir(), "client.crt")
+	for _, args := range [][]string{
+		{"--client-cert", missing},
+		{"--client-key", missing},
+	} {
+		_, stderr, code := runMain(t, append([]string{host, "-p", "pw"}, append(args, "info", "device")...)...)
+		if code != 5 {
+			t.Errorf("%v: expected exit code 5, got %d", args, code)
+		}
+		if !strings.Contains(stderr, "client") {
+			t.Errorf("%v: expected a client certificate error, got %q", args, stderr)
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if requests != 0 {
+		t.Errorf("Expected no requests with an unusable client certificate, got %d", requests)
+	}
+}
+
+func TestDiagnosticsRunSelection(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":[{"test":"ethernet","status":"pass"},{"test":"dns","status":"fail","message":"timeout"},{"test":"internet","status":"pass"}]}}`))
+	}))
+	defer server.Close()
+	host := server.URL[7:]
+
+	for _, test := range []struct {
+		args []string
+		code int
+	}{
+		{[]string{"diagnostics", "run"}, 0},
+		{[]string{"diagnostics", "run", "--fail-on-error"}, 1},
+		{[]string{"diagnostics", "run", "--only", "ethernet,internet", "--fail-on-error"}, 0},
+		{[]string{"diagnostics", "run", "--skip", "dns", "--fail-on-error"}, 0},
+		{[]string{"diagnostics", "run", "--only", "dns", "--fail-on-error"}, 1},
+		{[]string{"diagnostics", "run", "--only", "wifi"}, 5},
+	} {
+		if _, stderr, code := runMain(t, append([]string{host, "-p", "pw"}, test.args...)...); code != test.code {
+			t.Errorf("%v: expected exit code %d, got %d: %s", test.args, test.code, code, stderr)
+		}
+	}
+
+	stdout, _, _ := runMain(t, host, "-p", "pw", "-j", "diagnostics", "run", "--skip", "dns")
+	var results []struct {
+		Test string `json:"test"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &results); err != nil {
+		t.Fatalf("Invalid JSON output: %v: %s", err, stdout)
+	}
+	if len(results) != 2 || results[0].Test != "ethernet" || results[1].Test != "internet" {
+		t.Errorf("Expected ethernet and internet, got %+v", results)
+	}
+}
+
+func TestVideoOutputInfoAll(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/video/hdmi/output/0/":
+			w.Write([]byte(`{"data":{"result":{"connector":"hdmi","device":"0","connected":true,"width":3840,"height":2160,"refreshRate":30}}}`))
+		case "/api/v1/video/hdmi/output/1/":
+			w.Write([]byte(`{"data":{"result":{"connector":"hdmi","device":"1","connected":false}}}`))
+		case "/api/v1/video/vga/output/0/":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	host := server.URL[7:]
+
+	stdout, stderr, code := runMain(t, host, "-p", "pw", "video", "output-info", "--all")
+	if code != 0 {
+		t.Fatalf("Expected success, got code %d: %s", code, stderr)
+	}
+	for _, want := range []string{"hdmi 0", "3840x2160 @ 30Hz", "hdmi 1"} {
+		if !strings.Contains(stdout, want) {
+			t.Errorf("Expected %q in output, got %q", want, stdout)
+		}
+	}
+	// An output that failed is a warning, not the end of the listing
+	if strings.Contains(stdout, "vga") || !strings.Contains(stderr, "Warning: vga 0") {
+		t.Errorf("Expected a warning for vga 0, got stdout %q and stderr %q", stdout, stderr)
+	}
+
+	stdout, _, _ = runMain(t, host, "-p", "pw", "-j", "video", "output-info", "--all")
+	var outputs []map[string]interface{}
+	if err := json.Unmarshal([]byte(stdout), &outputs); err != nil || len(outputs) != 3 {
+		t.Errorf("Expected a JSON array of 3 outputs, got %q (%v)", stdout, err)
+	} else if outputs[2]["error"] == nil {
+		t.Errorf("Expected the vga output to carry its error, got %v", outputs[2])
+	}
+
+	if _, _, code := runMain(t, host, "-p", "pw", "video", "output-info", "--all", "hdmi", "0"); code != 5 {
+		t.Errorf("Expected exit code 5 for --all with arguments, got %d", code)
+	}
+}
+
+func TestNetworkConfigOutput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{"interface":"eth0","dhcp":false,"ip":"10.0.0.5","netmask":"255.0.0.0","dns":["10.0.0.1"]}}}`))
+	}))
+	defer server.Close()
+	host := server.URL[7:]
+
+	stdout, stderr, code := runMain(t, host, "-p", "pw", "diagnostics", "network-config", "eth0")
+	if code != 0 {
+		t.Fatalf("Expected success, got code %d: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "Interface: eth0") || strings.Contains(stdout, "{") {
+		t.Errorf("Expected human-readable output, got %q", stdout)
+	}
+
+	stdout, _, _ = runMain(t, host, "-p", "pw", "-j", "diagnostics", "network-config", "eth0")
+	var config struct {
+		Interface string   `json:"interface"`
+		IP        string   `json:"ip"`
+		DNS       []string `json:"dns"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &config); err != nil {
+		t.Fatalf("Invalid JSON output: %v: %s", err, stdout)
+	}
+	if config.Interface != "eth0" || config.IP != "10.0.0.5" || len(config.DNS) != 1 {
+		t.Errorf("Unexpected network config JSON: %+v", config)
+	}
+}
+
+func TestNetworkConfigSetDNSAndVLAN(t *testing.T) {
+	var mu sync.Mutex
+	var bodies []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		bodies = append(bodies, strings.TrimSpace(string(body)))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{"reachable":true}}}`))
+	}))
+	defer server.Close()
+	host := server.URL[7:]
+
+	base := []string{host, "-p", "pw", "diagnostics", "network-config-set", "eth0", "--dhcp"}
+	if _, stderr, code := runMain(t, append(base, "--dns", "10.0.0.2", "--dns", "10.0.0.3,10.0.0.4", "--clear-vlan")...); code != 0 {
+		t.Fatalf("Expected success, got code %d: %s", code, stderr)
+	}
+	if _, stderr, code := runMain(t, append(base, "--clear-dns")...); code != 0 {
+		t.Fatalf("Expected success, got code %d: %s", code, stderr)
+	}
+
+	expected := []string{
+		`{"interface":"eth0","dhcp":true,"dns":["10.0.0.2","10.0.0.3","10.0.0.4"],"vlanId":0}`,
+		`{"interface":"eth0","dhcp":true,"dns":[]}`,
+	}
+	mu.Lock()
+	got := append([]string(nil), bodies...)
+	mu.Unlock()
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("Expected payloads %v, got %v", expected, got)
+	}
+
+	for _, args := range [][]string{
+		{"--clear-dns", "--dns", "10.0.0.2"},
+		{"--clear-vlan", "--vlan", "20"},
+		{"--vlan", "0"},
+	} {
+		if _, _, code := runMain(t, append(base, args...)...); code != 5 {
+			t.Errorf("%v: expected exit code 5, got %d", args, code)
+		}
+	}
+}
+
+func TestFieldsFlag(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{"model":"XT1144","serial":"XTC35T000155","network":{"hostname":"lobby","interfaces":[]}}}}`))
+	}))
+	defer server.Close()
+
+	// --fields implies --json
+	stdout, stderr, code := runMain(t, server.URL[7:], "-p", "pw", "--fields", "model,network.hostname", "info", "device")
+	if code != 0 {
+		t.Fatalf("Expected success, got code %d: %s", code, stderr)
+	}
+	if strings.TrimSpace(stdout) != `{"model":"XT1144","network":{"hostname":"lobby"}}` {
+		t.Errorf("Unexpected projected output: %s", stdout)
+	}
+}
+
+func TestFileListPagination(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/files/sd/":
+			w.Write([]byte(`{"data":{"result":[` +
+				`{"name":"a.mp4","type":"file","size":1},` +
+				`{"name":"b.mp4","type":"file","size":2},` +
+				`{"name":"media","type":"directory"}]}}`))
+		case "/api/v1/files/sd/media/":
+			w.Write([]byte(`{"data":{"result":[{"name":"c.mp4","type":"file","size":3}]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	host := server.URL[7:]
+
+	stdout, stderr, code := runMain(t, host, "-p", "pw", "file", "list", "/storage/sd/", "--limit", "2", "--offset", "1")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d\nstderr: %s", code, stderr)
+	}
+	if strings.Contains(stdout, "a.mp4") || !strings.Contains(stdout, "b.mp4") || !strings.Contains(stdout, "media") {
+		t.Errorf("Expected entries 2-3 of the listing, got:\n%s", stdout)
+	}
+	if !strings.Contains(stdout, "Showing 2 of 3 entries") {
+		t.Errorf("Expected a footer, got:\n%s", stdout)
+	}
+
+	stdout, _, _ = runMain(t, host, "-p", "pw", "file", "list", "/storage/sd/", "--limit", "10")
+	if strings.Contains(stdout, "Showing") {
+		t.Errorf("Expected no footer when every entry is shown, got:\n%s", stdout)
+	}
+
+	stdout, _, _ = runMain(t, host, "-p", "pw", "file", "list", "/storage/sd/", "--offset", "5")
+	if !strings.Contains(stdout, "Showing 0 of 3 entries") {
+		t.Errorf("Expected an empty page footer, got:\n%s", stdout)
+	}
+
+	stdout, stderr, code = runMain(t, host, "-p", "pw", "file", "list", "/storage/sd/", "-r", "--offset", "2", "--limit", "2")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d\nstderr: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "/storage/sd/media") || !strings.Contains(stdout, "/storage/sd/media/c.mp4") || strings.Contains(stdout, "b.mp4") {
+		t.Errorf("Expected the recursive page to hold media and c.mp4, got:\n%s", stdout)
+	}
+	if !strings.Contains(stdout, "Showing 2 of 4 entries") {
+		t.Errorf("Expected a recursive footer, got:\n%s", stdout)
+	}
+
+	stdout, _, _ = runMain(t, host, "-p", "pw", "--json", "file", "list", "/storage/sd/", "-r", "--limit", "1")
+	var files []map[string]interface{}
+	if err := json.Unmarshal([]byte(stdout), &files); err != nil {
+		t.Fatalf("Expected a JSON array, got %q: %v", stdout, err)
+	}
+	if len(files) != 1 || files[0]["name"] != "a.mp4" {
+		t.Errorf("Expected only a.mp4, got %v", files)
+	}
+
+	_, _, code = runMain(t, host, "-p", "pw", "file", "list", "--limit", "-1")
+	if code != 5 {
+		t.Errorf("Expected usage exit code 5 for a negative limit, got %d", code)
+	}
+}
+
+func TestMutatingCommandsJSONEnvelope(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{"success":true}}}`))
+	}))
+	defer server.Close()
+	host := server.URL[7:]
+
+	tests := []struct {
+		args   []string
+		action string
+		text   string
+	}{
+		{[]string{"control", "local-dws", "enable"}, "local-dws", "Local DWS enabled"},
+		{[]string{"diagnostics", "telnet", "enable", "--reboot"}, "telnet", "Telnet enabled\nPlayer will reboot"},
+		{[]string{"diagnostics", "ssh", "disable"}, "ssh", "SSH disabled"},
+		{[]string{"registry", "delete", "-f", "networking", "key"}, "delete", "Deleted networking/key"},
+		{[]string{"file", "mkdir", "/storage/sd/media"}, "mkdir", "Created directory /storage/sd/media"},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args[:2], "_"), func(t *testing.T) {
+			stdout, stderr, code := runMain(t, append([]string{host, "-p", "pw", "--json"}, tt.args...)...)
+			if code != 0 {
+				t.Fatalf("Expected exit code 0, got %d\nstderr: %s", code, stderr)
+			}
+
+			var result map[string]interface{}
+			if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+				t.Fatalf("Expected a JSON envelope, got %q: %v", stdout, err)
+			}
+			if result["success"] != true || result["action"] != tt.action {
+				t.Errorf("Expected success with action %q, got %v", tt.action, result)
+			}
+
+			stdout, _, _ = runMain(t, append([]string{host, "-p", "pw"}, tt.args...)...)
+			if strings.TrimSpace(stdout) != tt.text {
+				t.Errorf("Expected %q without --json, got %q", tt.text, stdout)
+			}
+		})
+	}
+}
+
+func TestAllowBasicFlag(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "admin" || pass != "pw" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="BrightSign"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{"model":"XD1034"}}}`))
+	}))
+	defer server.Close()
+
+	_, stderr, code := runMain(t, server.URL[7:], "-p", "pw", "--no-auth-cache", "info", "device")
+	if code != 2 {
+		t.Errorf("Expected auth exit code 2 without --allow-basic, got %d", code)
+	}
+	if !strings.Contains(stderr, "--allow-basic") {
+		t.Errorf("Expected a hint about --allow-basic, got %q", stderr)
+	}
+
+	stdout, stderr, code := runMain(t, server.URL[7:], "-p", "pw", "--no-auth-cache", "--allow-basic", "info", "device")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0 with --allow-basic, got %d\nstderr: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "XD1034") {
+		t.Errorf("Expected device info, got %q", stdout)
+	}
+}
+
+func TestBadArgumentMessages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Unexpected request for %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		args    []string
+		message string
+	}{
+		{[]string{"video", "output-info", "hdmi"}, "output-info expects a connector and a device index, got 1 argument(s)"},
+		{[]string{"video", "modes", "set", "hdmi 1", "0", "1920x1080x60p"}, `invalid connector "hdmi 1"`},
+		{[]string{"registry", "set", "html", "motd", "hello", "world"}, "quote a value that contains spaces"},
+		{[]string{"file", "upload", "video.mp4", "/media/video.mp4"}, "must start with /storage/"},
+	}
+
+	for _, tt := range tests {
+		t.Run(strings.Join(tt.args[:2], "_"), func(t *testing.T) {
+			_, stderr, code := runMain(t, append([]string{server.URL[7:], "-p", "pw"}, tt.args...)...)
+			if code != 5 {
+				t.Errorf("Expected usage exit code 5, got %d", code)
+			}
+			if !strings.Contains(stderr, tt.message) {
+				t.Errorf("Expected %q in stderr, got:\n%s", tt.message, stderr)
+			}
+		})
+	}
+}
+
+func TestFileDuOutput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/files/sd/":
+			w.Write([]byte(`{"data":{"result":[{"name":"a.mp4","type":"file","size":1024},{"name":"media","type":"directory"}]}}`))
+		case "/api/v1/files/sd/media/":
+			w.Write([]byte(`{"data":{"result":[{"name":"b.mp4","type":"file","size":2048}]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	host := server.URL[7:]
+
+	stdout, stderr, code := runMain(t, host, "-p", "pw", "file", "du")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d\nstderr: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "3.0 KB  /storage/sd\n") || !strings.Contains(stdout, "2.0 KB  /storage/sd/media\n") {
+		t.Errorf("Unexpected du output:\n%s", stdout)
+	}
+
+	stdout, _, _ = runMain(t, host, "-p", "pw", "--json", "file", "du", "-s")
+	var entries []map[string]interface{}
+	if err := json.Unmarshal([]byte(stdout), &entries); err != nil {
+		t.Fatalf("Expected a JSON array, got %q: %v", stdout, err)
+	}
+	if len(entries) != 1 || entries[0]["path"] != "/storage/sd" || entries[0]["bytes"] != float64(3072) {
+		t.Errorf("Expected only the summarized total, got %v", entries)
+	}
+
+	if _, _, code := runMain(t, host, "-p", "pw", "file", "du", "-s", "--max-depth", "1"); code != 5 {
+		t.Errorf("Expected usage exit code 5 for --summarize with --max-depth, got %d", code)
+	}
+}
+
+func TestDumpResponseFlag(t *testing.T) {
+	const body = `{"data":{"result":{"model":"XD1034","serial":"ABC123"}}}`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	dumpPath := filepath.Join(t.TempDir(), "response.json")
+	_, stderr, code := runMain(t, server.URL[7:], "-p", "pw", "--dump-response", dumpPath, "info", "device")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d\nstderr: %s", code, stderr)
+	}
+
+	data, err := os.ReadFile(dumpPath)
+	if err != nil {
+		t.Fatalf("Dump file not written: %v", err)
+	}
+	if string(data) != body {
+		t.Errorf("Expected %q in the dump file, got %q", body, data)
+	}
+}
+
+func TestHealthExpect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{"status":"running","statusTime":"now"}}}`))
+	}))
+	defer server.Close()
+	host := server.URL[7:]
+
+	if _, stderr, code := runMain(t, host, "-p", "pw", "info", "health", "--wait-healthy", "--timeout", "10s"); code != 0 {
+		t.Errorf("Expected exit code 0 for a running player, got %d\nstderr: %s", code, stderr)
+	}
+	if _, _, code := runMain(t, host, "-p", "pw", "info", "health", "--expect", "running"); code != 0 {
+		t.Errorf("Expected exit code 0 when the status matches, got %d", code)
+	}
+
+	stdout, _, code := runMain(t, host, "-p", "pw", "info", "health", "--expect", "idle")
+	if code != 1 {
+		t.Errorf("Expected exit code 1 when the status differs, got %d", code)
+	}
+	if !strings.Contains(stdout, "Status: running") {
+		t.Errorf("Expected the status to be printed, got %q", stdout)
+	}
+
+	if _, _, code := runMain(t, host, "-p", "pw", "info", "health", "--timeout", "5s"); code != 5 {
+		t.Errorf("Expected usage exit code 5 for --timeout without --wait-healthy, got %d", code)
+	}
+}
+
+func TestOutputFileFlag(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{"model":"XD1034","serial":"ABC123"}}}`))
+	}))
+	defer server.Close()
+
+	outPath := filepath.Join(t.TempDir(), "reports", "device.json")
+	stdout, stderr, code := runMain(t, server.URL[7:], "-p", "pw", "--json", "-O", outPath, "info", "device")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d\nstderr: %s", code, stderr)
+	}
+	if stdout != "" {
+		t.Errorf("Expected nothing on stdout, got %q", stdout)
+	}
+
+	data, err := os.ReadFile(outPath)
+	if err != nil {
+		t.Fatalf("Output file not written: %v", err)
+	}
+	var info map[string]interface{}
+	if err := json.Unmarshal(data, &info); err != nil {
+		t.Fatalf("Expected JSON in the output file, got %q: %v", data, err)
+	}
+	if info["serial"] != "ABC123" {
+		t.Errorf("Expected the device info in the output file, got %v", info)
+	}
+
+	textPath := filepath.Join(t.TempDir(), "device.txt")
+	stdout, _, code = runMain(t, server.URL[7:], "-p", "pw", "--output-file", textPath, "info", "device")
+	if code != 0 || stdout != "" {
+		t.Errorf("Expected exit code 0 and empty stdout for text output, got %d and %q", code, stdout)
+	}
+	if data, _ := os.ReadFile(textPath); !strings.Contains(string(data), "ABC123") {
+		t.Errorf("Expected the text report in the output file, got %q", data)
+	}
+}
+
+func TestOutputFileKeepsProgressOnTerminal(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/logs/":
+			w.Write([]byte(`{"data":{"result":"boot ok\nplaying\n"}}`))
+		case "/api/v1/files/sd/":
+			w.Write([]byte(`{"data":{"result":[{"name":"a.mp4","type":"file","size":1},{"name":"b.mp4","type":"file","size":2}]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	host := server.URL[7:]
+
+	// logs get has no flag of its own; the global -O takes the raw log text
+	logPath := filepath.Join(t.TempDir(), "player.log")
+	_, stderr, code := runMain(t, host, "-p", "pw", "logs", "get", "-O", logPath)
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d\nstderr: %s", code, stderr)
+	}
+	if data, _ := os.ReadFile(logPath); string(data) != "boot ok\nplaying\n" {
+		t.Errorf("Expected the log text in the output file, got %q", data)
+	}
+
+	listPath := filepath.Join(t.TempDir(), "files.txt")
+	stdout, stderr, code := runMain(t, host, "-p", "pw", "file", "list", "/storage/sd/", "--limit", "1", "-O", listPath)
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d\nstderr: %s", code, stderr)
+	}
+	data, _ := os.ReadFile(listPath)
+	if !strings.Contains(string(data), "a.mp4") || strings.Contains(string(data), "Showing") {
+		t.Errorf("Expected only the listing in the output file, got %q", data)
+	}
+	if !strings.Contains(stdout, "Showing 1 of 2 entries") {
+		t.Errorf("Expected progress messages on the terminal, got %q", stdout)
+	}
+}
+
+func TestPingCountCSV(t *testing.T) {
+	var mu sync.Mutex
+	pings := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		pings++
+		failed := pings == 2
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if failed {
+			// One run failing is recorded and the pings carry on
+			w.WriteHeader(http.StatusInternalServerError)
+			w.Write([]byte(`{"error":"network unreachable"}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"result":{"success":true,"address":"8.8.8.8","packetsSent":4,"packetsReceived":4,"avgTime":12.5}}}`))
+	}))
+	defer server.Close()
+
+	stdout, stderr, code := runMain(t, server.URL[7:], "-p", "pw", "diagnostics", "ping", "8.8.8.8", "--count", "3", "--interval", "0", "-o", "csv")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d\nstderr: %s", code, stderr)
+	}
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	if len(lines) != 4 || !strings.HasPrefix(lines[0], "time,address,") {
+		t.Fatalf("Expected a header and 3 rows, got %q", stdout)
+	}
+	if !strings.Contains(lines[3], ",8.8.8.8,true,4,4,") {
+		t.Errorf("Unexpected row %q", lines[3])
+	}
+	if !strings.Contains(lines[2], ",8.8.8.8,false,0,0,") || !strings.Contains(lines[2], "network unreachable") {
+		t.Errorf("Expected the failed run in the error column, got %q", lines[2])
+	}
+
+	if _, _, code := runMain(t, server.URL[7:], "-p", "pw", "diagnostics", "ping", "8.8.8.8", "-o", "xml"); code != 5 {
+		t.Errorf("Expected exit code 5 for an unknown format, got %d", code)
+	}
+}
+
+func TestRegistryCopy(t *testing.T) {
+	source := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{
+			"networking":{"hostname":"player1","password":"secret","dhcp":"yes"},
+			"html":{"url":"http://example.com"}}}}`))
+	}))
+	defer source.Close()
+
+	var mu sync.Mutex
+	var puts []string
+	failKey := ""
+	dest := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPut {
+			t.Errorf("Unexpected %s %s on the destination", r.Method, r.URL.Path)
+			return
+		}
+		var payload struct {
+			Value string `json:"value"`
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+
+		mu.Lock()
+		defer mu.Unlock()
+		key := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/registry/"), "/")
+		if key == failKey {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		puts = append(puts, key+"="+payload.Value)
+	}))
+	defer dest.Close()
+
+	stdout, stderr, code := runMain(t, "registry", "copy", "-p", "pw", "--json",
+		"--from", source.URL[7:], "--to", dest.URL[7:], "--section", "networking", "--exclude", "password")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d\nstderr: %s", code, stderr)
+	}
+	expected := []string{"networking/dhcp=yes", "networking/hostname=player1"}
+	mu.Lock()
+	if !reflect.DeepEqual(puts, expected) {
+		t.Errorf("Expected writes %v, got %v", expected, puts)
+	}
+	mu.Unlock()
+	var result struct {
+		Written []string `json:"written"`
+		Total   int      `json:"total"`
+	}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("Invalid JSON output %q: %v", stdout, err)
+	}
+	if result.Total != 2 || len(result.Written) != 2 {
+		t.Errorf("Expected 2 of 2 values written, got %+v", result)
+	}
+
+	// A failing destination reports what was written before it failed
+	mu.Lock()
+	puts = nil
+	failKey = "networking/dhcp"
+	mu.Unlock()
+	stdout, stderr, code = runMain(t, source.URL[7:], "-p", "pw", "registry", "copy", "--to", dest.URL[7:])
+	if code != 1 {
+		t.Fatalf("Expected exit code 1, got %d", code)
+	}
+	if !strings.Contains(stdout, "Copied html/url") || strings.Contains(stdout, "networking/") {
+		t.Errorf("Expected only html/url reported as copied, got %q", stdout)
+	}
+	if !strings.Contains(stderr, "wrote 1 of 4 registry values") {
+		t.Errorf("Expected a partial write error, got %q", stderr)
+	}
+
+	// A complete copy ends with a one-line summary
+	mu.Lock()
+	puts = nil
+	failKey = ""
+	mu.Unlock()
+	stdout, _, code = runMain(t, source.URL[7:], "-p", "pw", "registry", "copy", "--to", dest.URL[7:], "--section", "html")
+	expectedOutput := "Copied html/url\nCopied 1 registry values from " + source.URL[7:] + " to " + dest.URL[7:] + "\n"
+	if code != 0 || stdout != expectedOutput {
+		t.Errorf("Expected exit code 0 and %q, got %d and %q", expectedOutput, code, stdout)
+	}
+}
+
+func TestVideoModeSetVerify(t *testing.T) {
+	var mu sync.Mutex
+	current := "1920x1080x60p"
+	fallback := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == http.MethodPut {
+			var payload struct {
+				Mode string `json:"mode"`
+			}
+			json.NewDecoder(r.Body).Decode(&payload)
+			// The player accepts the mode; a display that cannot show it falls back
+			if !fallback {
+				current = payload.Mode
+			}
+			w.Write([]byte(`{"data":{"result":true}}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"result":{"mode":"` + current + `"}}}`))
+	}))
+	defer server.Close()
+	host := server.URL[7:]
+
+	stdout, stderr, code := runMain(t, host, "-p", "pw", "video", "modes", "set", "hdmi", "0", "3840x2160x30p", "--verify", "--verify-delay", "0")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0 when the mode took effect, got %d\nstderr: %s", code, stderr)
+	}
+	if !strings.Contains(stdout, "(verified)") {
+		t.Errorf("Expected the mode to be reported as verified, got %q", stdout)
+	}
+
+	mu.Lock()
+	fallback = true
+	current = "1920x1080x60p"
+	mu.Unlock()
+
+	stdout, stderr, code = runMain(t, host, "-p", "pw", "--json", "video", "modes", "set", "hdmi", "0", "3840x2160x60p", "--verify", "--verify-delay", "0")
+	if code != 1 {
+		t.Errorf("Expected exit code 1 when the display fell back, got %d", code)
+	}
+	// The mismatch is reported as an error only, never with "success"
+	if stdout != "" {
+		t.Errorf("Expected nothing on stdout, got %q", stdout)
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal([]byte(stderr), &result); err != nil {
+		t.Fatalf("Invalid JSON error %q: %v", stderr, err)
+	}
+	if message, _ := result["error"].(string); !strings.Contains(message, "reports 1920x1080x60p") {
+		t.Errorf("Expected an error naming the current mode, got %v", result)
+	}
+	if _, ok := result["success"]; ok {
+		t.Errorf("Expected no success field in the error, got %v", result)
+	}
+}
+
+func TestFileDeleteRecursive(t *testing.T) {
+	var mu sync.Mutex
+	var deletes []string
+	lists := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == "GET" {
+			mu.Lock()
+			lists++
+			mu.Unlock()
+		}
+		switch {
+		case r.Method == "DELETE":
+			mu.Lock()
+			deletes = append(deletes, r.URL.Path)
+			mu.Unlock()
+			w.Write([]byte(`{"data":{"result":{"success":true}}}`))
+		case r.URL.Path == "/api/v1/files/sd/":
+			w.Write([]byte(`{"data":{"result":[{"name":"media","type":"directory"}]}}`))
+		case r.URL.Path == "/api/v1/files/sd/media/":
+			w.Write([]byte(`{"data":{"result":[{"name":"a.mp4","type":"file"},{"name":"sub","type":"directory"}]}}`))
+		case r.URL.Path == "/api/v1/files/sd/media/sub/":
+			w.Write([]byte(`{"data":{"result":[{"name":"b.mp4","type":"file"}]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	host := server.URL[7:]
+
+	// A device root is refused before it is walked or a prompt is shown
+	stdout, stderr, code := runMainWithInput(t, "y\n", host, "-p", "pw", "file", "delete", "-r", "/storage/sd/")
+	if code != 1 || !strings.Contains(stderr, "refusing to recursively delete storage device root") {
+		t.Errorf("Expected the device root to be refused, got %d and %q", code, stderr)
+	}
+	mu.Lock()
+	if stdout != "" || lists != 0 || len(deletes) != 0 {
+		t.Errorf("Expected no prompt or requests for a device root, got %q, %d listings and deletes %v", stdout, lists, deletes)
+	}
+	mu.Unlock()
+
+	stdout, _, code = runMainWithInput(t, "n\n", host, "-p", "pw", "file", "delete", "-r", "/storage/sd/media")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0 when declining, got %d", code)
+	}
+	if !strings.Contains(stdout, "Delete /storage/sd/media and the 3 entries in it?") {
+		t.Errorf("Expected the prompt to count the entries, got %q", stdout)
+	}
+	mu.Lock()
+	if len(deletes) != 0 {
+		t.Fatalf("Expected nothing deleted after declining, got %v", deletes)
+	}
+	mu.Unlock()
+
+	stdout, stderr, code = runMain(t, host, "-p", "pw", "--json", "file", "delete", "-r", "-f", "/storage/sd/media")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d\nstderr: %s", code, stderr)
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("Invalid JSON output %q: %v", stdout, err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if result["deleted"] != float64(4) || len(deletes) != 4 {
+		t.Errorf("Expected 4 entries deleted, got %v and requests %v", result, deletes)
+	}
+	if deletes[len(deletes)-1] != "/api/v1/files/sd/media/" {
+		t.Errorf("Expected the directory to be deleted last, got %v", deletes)
+	}
+}
+
+func TestIdentifyRestoresDisplayOnInterrupt(t *testing.T) {
+	var mu sync.Mutex
+	var states []string
+	blinking := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.URL.Path != "/api/v1/display-control/power-settings/" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Method == "GET" {
+			w.Write([]byte(`{"data":{"result":{"state":"on"}}}`))
+			return
+		}
+		var payload struct {
+			State string `json:"state"`
+		}
+		json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		states = append(states, payload.State)
+		mu.Unlock()
+		select {
+		case blinking <- struct{}{}:
+		default:
+		}
+		w.Write([]byte(`{"data":{"result":true}}`))
+	}))
+	defer server.Close()
+
+	cmd := exec.Command(os.Args[0])
+	cmd.Env = append(os.Environ(), "BSCLI_MAIN_ARGS="+strings.Join([]string{server.URL[7:], "-p", "pw", "control", "identify", "--duration", "60"}, "\n"))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("Failed to start subprocess: %v", err)
+	}
+
+	<-blinking
+	cmd.Process.Signal(os.Interrupt)
+
+	var exitErr *exec.ExitError
+	if err := cmd.Wait(); !errors.As(err, &exitErr) || exitErr.ExitCode() != 1 {
+		t.Fatalf("Expected exit code 1 after an interrupt, got %v (stderr: %s)", err, stderr.String())
+	}
+	if !strings.Contains(stderr.String(), "interrupted") {
+		t.Errorf("Expected an interrupted message, got %q", stderr.String())
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(states) == 0 || states[len(states)-1] != "on" {
+		t.Errorf("Expected the display to be switched back on, got %v", states)
+	}
+}
+
+func TestPcapRunOutput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case r.URL.Path == "/api/v1/diagnostics/packet-capture/" && r.Method == "POST":
+			w.Write([]byte(`{"data":{"result":true}}`))
+		case r.URL.Path == "/api/v1/diagnostics/packet-capture/":
+			w.Write([]byte(`{"data":{"result":{"running":false,"outputFile":"/storage/sd/capture.pcap","bytesCaptured":4}}}`))
+		case r.URL.Path == "/api/v1/files/sd/capture.pcap":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.Write([]byte("pcap"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	host := server.URL[7:]
+
+	// Text mode prints a sentence, not the JSON envelope
+	download := filepath.Join(t.TempDir(), "capture.pcap")
+	stdout, stderr, code := runMain(t, host, "-p", "pw", "diagnostics", "pcap", "run", "eth0", "--download", download)
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d\nstderr: %s", code, stderr)
+	}
+	if stdout != "Capturing on eth0 for 30s...\nPacket capture downloaded to "+download+"\n" {
+		t.Errorf("Expected a text report, got %q", stdout)
+	}
+
+	stdout, stderr, code = runMain(t, host, "-p", "pw", "--json", "diagnostics", "pcap", "run", "eth0", "--download", download)
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d\nstderr: %s", code, stderr)
+	}
+	var result map[string]interface{}
+	if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+		t.Fatalf("Invalid JSON output %q: %v", stdout, err)
+	}
+	if result["success"] != true || result["action"] != "packet-capture" || result["local"] != download {
+		t.Errorf("Unexpected JSON result %v", result)
+	}
+}
+
+func TestFileListNamesWithControlCharacters(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":[{"name":"two\nlines","type":"file","size":1},{"name":"tab\tbed","type":"file","size":2},{"name":"media","type":"directory"}]}}`))
+	}))
+	defer server.Close()
+
+	stdout, stderr, code := runMain(t, server.URL[7:], "-p", "pw", "file", "list", "/storage/sd/")
+	if code != 0 {
+		t.Fatalf("Expected exit code 0, got %d\nstderr: %s", code, stderr)
+	}
+	lines := strings.Split(strings.TrimSuffix(stdout, "\n"), "\n")
+	if len(lines) != 5 {
+		t.Fatalf("Expected a header, a rule and one row per file, got %q", stdout)
+	}
+	for i, name := range []string{"two lines", "tab bed", "media"} {
+		if !strings.Contains(lines[2+i], name) {
+			t.Errorf("Expected row %d to show %q, got %q", i, name, lines[2+i])
+		}
+	}
+}
+
+func TestStatusCommandsJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/diagnostics/packet-capture/":
+			w.Write([]byte(`{"data":{"result":{"running":true,"interface":"eth0"}}}`))
+		case "/api/v1/diagnostics/telnet/":
+			w.Write([]byte(`{"data":{"result":{"enabled":true,"portNumber":23}}}`))
+		case "/api/v1/diagnostics/ssh/":
+			w.Write([]byte(`{"data":{"result":{"enabled":true,"portNumber":22,"password":"hunter2"}}}`))
+		case "/api/v1/registry/recovery_url/":
+			w.Write([]byte(`{"data":{"result":{"url":"http://example.com/recover"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+	host := server.URL[7:]
+
+	tests := []struct {
+		args     []string
+		key      string
+		expected interface{}
+	}{
+		{[]string{"diagnostics", "pcap", "status"}, "interface", "eth0"},
+		{[]string{"diagnostics", "telnet", "status"}, "portNumber", float64(23)},
+		{[]string{"diagnostics", "ssh", "status"}, "portNumber", float64(22)},
+		{[]string{"registry", "recovery-url", "get"}, "recoveryUrl", "http://example.com/recover"},
+	}
+
+	for _, test := range tests {
+		t.Run(strings.Join(test.args, " "), func(t *testing.T) {
+			args := append([]string{host, "-p", "pw", "--json"}, test.args...)
+			stdout, stderr, code := runMain(t, args...)
+			if code != 0 {
+				t.Fatalf("Expected exit code 0, got %d (stderr: %s)", code, stderr)
+			}
+
+			var result map[string]interface{}
+			if err := json.Unmarshal([]byte(stdout), &result); err != nil {
+				t.Fatalf("Expected JSON output, got %q", stdout)
+			}
+			if result[test.key] != test.expected {
+				t.Errorf("Expected %s=%v, got %v", test.key, test.expected, result)
+			}
+			if strings.Contains(stdout, "hunter2") {
+				t.Errorf("Expected no password in the output, got %q", stdout)
+			}
+		})
+	}
+}
@@ -35,7 +35,7 @@ func main() {
 
 	username := os.Getenv("BSCLI_TEST_USERNAME")
 	if username == "" {
-		username = "admin"
+		username = brightsign.DefaultUsername
 	}
 
 	// Check both environment variable and flags for debug and insecure settings
@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+
+	"bscli/pkg/brightsign"
+	"github.com/spf13/cobra"
+)
+
+// apiMethods are the HTTP methods accepted by the api command
+var apiMethods = []string{http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete}
+
+func addAPICommands() {
+	apiCmd := &cobra.Command{
+		Use:   "api [method] [path]",
+		Short: "Send a request to any DWS endpoint and print the raw response",
+		Long: `Send an authenticated request to an arbitrary endpoint relative to /api/v1
+and print the response body. This reaches endpoints that have no dedicated
+command yet. The method defaults to GET when only a path is given.
+
+  bscli 192.168.1.100 api /info/
+  bscli 192.168.1.100 api PUT /registry/networking/ssh/ --body value.json
+  echo '{"value":"22"}' | bscli 192.168.1.100 api PUT /registry/networking/ssh/ --body -
+
+The body must be JSON. Responses are pretty-printed unless --json is given,
+which prints them unchanged.`,
+		Args: cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			bodyPath, _ := cmd.Flags().GetString("body")
+
+			method, path, err := parseAPIArgs(args)
+			if err != nil {
+				handleError(err)
+			}
+
+			var body interface{}
+			if bodyPath != "" {
+				raw, err := readAPIBody(bodyPath)
+				if err != nil {
+					handleError(err)
+				}
+				body = raw
+			}
+
+			client, err := getClient()
+			if err != nil {
+				handleError(err)
+			}
+
+			resp, err := client.DoRaw(method, path, body)
+			if err != nil {
+				handleError(err)
+			}
+			defer resp.Body.Close()
+
+			if err := brightsign.CheckResponse(resp, fmt.Sprintf("%s %s failed", method, path)); err != nil {
+				handleError(err)
+			}
+
+			data, err := io.ReadAll(resp.Body)
+			if err != nil {
+				handleError(fmt.Errorf("failed to read response: %w", err))
+			}
+
+			var pretty bytes.Buffer
+			if !jsonOutput && json.Indent(&pretty, data, "", "  ") == nil {
+				data = pretty.Bytes()
+			}
+			os.Stdout.Write(data)
+			if len(data) > 0 && data[len(data)-1] != '\n' {
+				fmt.Println()
+			}
+		},
+	}
+	apiCmd.Flags().String("body", "", "JSON request body file, or - for stdin")
+
+	rootCmd.AddCommand(apiCmd)
+}
+
+// parseAPIArgs returns the method and path of an api command line, defaulting
+// the method to GET when only a path is given
+func parseAPIArgs(args []string) (string, string, error) {
+	method, path := http.MethodGet, args[0]
+	if len(args) == 2 {
+		method, path = strings.ToUpper(args[0]), args[1]
+	}
+
+	valid := false
+	for _, m := range apiMethods {
+		if method == m {
+			valid = true
+			break
+		}
+	}
+	if !valid {
+		return "", "", &usageError{err: fmt.Errorf("unsupported method %q (use %s)", method, strings.Join(apiMethods, ", "))}
+	}
+
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return method, path, nil
+}
+
+// readAPIBody reads a JSON request body from a file, or stdin for "-"
+func readAPIBody(path string) (json.RawMessage, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	data = bytes.TrimSpace(data)
+	if !json.Valid(data) {
+		return nil, &usageError{err: fmt.Errorf("request body is not valid JSON")}
+	}
+	return json.RawMessage(data), nil
+}
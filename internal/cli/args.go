@@ -0,0 +1,117 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"bscli/pkg/brightsign"
+	"github.com/spf13/cobra"
+)
+
+// videoOutputHint explains the connector and device arguments of the video
+// commands
+func videoOutputHint() string {
+	return fmt.Sprintf(`The connector is a name such as %s and the device is the output index,
+usually 0, e.g. "hdmi 0". Use "video output-info --all" to list the player's outputs.`,
+		strings.Join(brightsign.VideoConnectors, ", "))
+}
+
+// videoOutputArgs validates the connector and device index taken by the
+// video commands, followed by the named extra arguments
+func videoOutputArgs(extra ...string) cobra.PositionalArgs {
+	names := append([]string{"a connector", "a device index"}, extra...)
+	return func(cmd *cobra.Command, args []string) error {
+		if len(args) != len(names) {
+			return fmt.Errorf("%s expects %s, got %d argument(s)\n%s",
+				cmd.Name(), joinArgNames(names), len(args), videoOutputHint())
+		}
+		if err := validateVideoOutput(args[0], args[1]); err != nil {
+			return err
+		}
+		for i, name := range extra {
+			if strings.TrimSpace(args[2+i]) == "" {
+				return fmt.Errorf("%s must not be empty", strings.TrimPrefix(name, "a "))
+			}
+		}
+		return nil
+	}
+}
+
+// validateVideoOutput checks that connector is a well-formed name and device
+// a non-negative index. Connectors beyond brightsign.VideoConnectors are left
+// for the player to accept or reject, since models differ.
+func validateVideoOutput(connector, device string) error {
+	if !isConnectorName(connector) {
+		return fmt.Errorf("invalid connector %q\n%s", connector, videoOutputHint())
+	}
+
+	if n, err := strconv.Atoi(device); err != nil || n < 0 {
+		return fmt.Errorf("invalid device index %q: must be a number such as 0\n%s", device, videoOutputHint())
+	}
+	return nil
+}
+
+// isConnectorName reports whether s is a letter followed by letters, digits,
+// "-" or "_", which keeps it a single segment of the API path
+func isConnectorName(s string) bool {
+	for i, r := range s {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && (r >= '0' && r <= '9' || r == '-' || r == '_'):
+		default:
+			return false
+		}
+	}
+	return s != ""
+}
+
+// registrySetArgs validates the section, key and value of registry set
+func registrySetArgs(cmd *cobra.Command, args []string) error {
+	switch {
+	case len(args) > 3:
+		return fmt.Errorf("registry set expects a section, a key and a value, got %d arguments; quote a value that contains spaces, e.g. registry set networking motd \"hello world\"", len(args))
+	case len(args) < 3:
+		return fmt.Errorf("registry set expects a section, a key and a value, e.g. registry set networking hostname player-1")
+	case strings.TrimSpace(args[0]) == "":
+		return fmt.Errorf("registry section must not be empty; list sections with registry sections")
+	case strings.TrimSpace(args[1]) == "":
+		return fmt.Errorf("registry key must not be empty; list the keys of a section with registry keys %s", args[0])
+	}
+	return nil
+}
+
+// uploadArgs validates the local file and remote path of file upload.
+// Whether the local file exists is left to the command.
+func uploadArgs(cmd *cobra.Command, args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("upload expects a local file and a remote path, got %d argument(s), e.g. file upload video.mp4 /storage/sd/video.mp4", len(args))
+	}
+
+	localPath, remotePath := args[0], args[1]
+	if strings.TrimSpace(localPath) == "" {
+		return fmt.Errorf("local file must not be empty")
+	}
+	if info, err := os.Stat(localPath); err == nil && info.IsDir() {
+		return fmt.Errorf("%s is a directory; use file sync %s /storage/sd/<dir> to upload it", localPath, localPath)
+	}
+
+	if strings.TrimSpace(remotePath) == "" {
+		return fmt.Errorf("remote path must not be empty, e.g. /storage/sd/ or media/video.mp4 (relative to /storage/sd/)")
+	}
+	if strings.HasPrefix(remotePath, "/") {
+		if err := brightsign.ValidateStoragePath(remotePath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// joinArgNames joins argument names as "a, b and c"
+func joinArgNames(names []string) string {
+	if len(names) == 1 {
+		return names[0]
+	}
+	return strings.Join(names[:len(names)-1], ", ") + " and " + names[len(names)-1]
+}
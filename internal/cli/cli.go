@@ -1,9 +1,14 @@
 package cli
 
 import (
+	"crypto/x509"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"os"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"syscall"
 
@@ -18,8 +23,24 @@ var (
 	username string
 	password string
 	debug    bool
+	trace    bool
 	jsonOutput bool
+	fields   []string
+	quiet    bool
+	dryRun   bool
+	noAuthCache bool
+	noDecompress bool
+	allowBasic bool
+	dumpResponse string
+	outputFile string
 	insecure bool
+	local    bool
+	useHTTPS bool
+	caCert   string
+	clientCert string
+	clientKey  string
+	port     int
+	basePath string
 
 	// Root command
 	rootCmd = &cobra.Command{
@@ -58,52 +79,246 @@ func Execute() error {
 		return rootCmd.Help()
 	}
 	
-	// First argument should be the host
-	host = args[0]
-	
-	// Set remaining arguments for cobra to parse
-	rootCmd.SetArgs(args[1:])
+	// First argument should be the host, unless the command runs offline
+	if isHostless(args) {
+		rootCmd.SetArgs(args)
+	} else if envHost := getenv("HOST"); envHost != "" && isCommand(args[0]) {
+		// The host comes from BSCLI_HOST when the first argument is a command
+		host = envHost
+		rootCmd.SetArgs(args)
+	} else {
+		host = args[0]
+
+		// Set remaining arguments for cobra to parse
+		rootCmd.SetArgs(args[1:])
+	}
 	
-	return rootCmd.Execute()
+	// Commands report their own failures through handleError, so anything
+	// cobra returns is a problem with the command line itself
+	defer closeClients()
+	defer restoreOutput()
+	if err := rootCmd.Execute(); err != nil {
+		return &usageError{err: err}
+	}
+	return nil
+}
+
+// hostlessCommands lists command paths that never contact a player and so
+// may be invoked without a host argument
+var hostlessCommands = [][]string{
+	{"video", "decode-edid"},
+	{"discover"},
+	{"version"},
+	{"info", "diff"},
+	{"registry", "copy"},
+}
+
+// isHostless reports whether args start with a command that needs no host
+func isHostless(args []string) bool {
+	for _, path := range hostlessCommands {
+		if len(args) < len(path) {
+			continue
+		}
+		matched := true
+		for i, name := range path {
+			if args[i] != name {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// isCommand reports whether name is a top-level command or flag rather than a host
+func isCommand(name string) bool {
+	if strings.HasPrefix(name, "-") {
+		return true
+	}
+	for _, cmd := range rootCmd.Commands() {
+		if cmd.Name() == name || cmd.HasAlias(name) {
+			return true
+		}
+	}
+	return name == "help"
+}
+
+// ExitCode returns the process exit code for an error returned by Execute
+func ExitCode(err error) int {
+	return exitCode(err)
 }
 
 func init() {
 	// Check environment variables for default values
-	debugDefault := os.Getenv("BSCLI_TEST_DEBUG") == "true"
-	insecureDefault := os.Getenv("BSCLI_TEST_INSECURE") == "true"
-	
+	debugDefault := getenvBool("DEBUG")
+	insecureDefault := getenvBool("INSECURE")
+	usernameDefault := getenv("USERNAME")
+	if usernameDefault == "" {
+		usernameDefault = brightsign.DefaultUsername
+	}
+
 	// Global flags (no longer need host flag)
-	rootCmd.PersistentFlags().StringVarP(&username, "user", "u", "admin", "Username for authentication")
+	rootCmd.PersistentFlags().StringVarP(&username, "user", "u", usernameDefault, "Username for authentication")
 	rootCmd.PersistentFlags().StringVarP(&password, "password", "p", "", "Password for authentication")
 	rootCmd.PersistentFlags().BoolVarP(&debug, "debug", "d", debugDefault, "Enable debug output")
+	rootCmd.PersistentFlags().BoolVar(&trace, "trace", false, "Trace HTTP requests and responses (headers, digest challenge, bodies) to stderr")
 	rootCmd.PersistentFlags().BoolVarP(&jsonOutput, "json", "j", false, "Output raw JSON (for scripts)")
-	rootCmd.PersistentFlags().BoolVarP(&insecure, "local", "l", insecureDefault, "Accept locally signed certificates (use HTTPS with insecure TLS)")
+	rootCmd.PersistentFlags().StringSliceVar(&fields, "fields", nil, "Only output these JSON fields, e.g. model,network.hostname (implies --json)")
+	rootCmd.PersistentFlags().StringVarP(&outputFile, "output-file", "O", "", "Write the command's output to this file instead of stdout, creating parent directories")
+	rootCmd.PersistentFlags().BoolVarP(&quiet, "quiet", "q", false, "Suppress progress and informational messages")
+	rootCmd.PersistentFlags().BoolVar(&noColor, "no-color", false, "Disable colored output (also disabled by NO_COLOR or when stdout is not a terminal)")
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "Print mutating requests (method, URL, payload) to stderr instead of sending them")
+	rootCmd.PersistentFlags().BoolVar(&noAuthCache, "no-auth-cache", false, "Do not reuse or save the digest challenge between invocations")
+	rootCmd.PersistentFlags().BoolVar(&noDecompress, "no-decompress", false, "Save gzip-encoded downloads and logs as received")
+	rootCmd.PersistentFlags().BoolVar(&allowBasic, "allow-basic", false, "Answer a Basic auth challenge from players without digest auth (sends the password unencrypted over HTTP)")
+	rootCmd.PersistentFlags().StringVar(&dumpResponse, "dump-response", "", "Write the raw body of each response to FILE (it holds the last one afterwards), for bug reports")
+	rootCmd.PersistentFlags().BoolVarP(&local, "local", "l", insecureDefault, "Accept locally signed certificates (same as --https --insecure)")
+	rootCmd.PersistentFlags().BoolVar(&useHTTPS, "https", false, "Connect over HTTPS and verify the player's certificate")
+	rootCmd.PersistentFlags().BoolVar(&insecure, "insecure", false, "Skip TLS certificate verification (implies --https)")
+	rootCmd.PersistentFlags().StringVar(&caCert, "cacert", "", "Verify the player's certificate against the CA bundle in this PEM file (implies --https)")
+	rootCmd.PersistentFlags().StringVar(&clientCert, "client-cert", "", "PEM client certificate for players that require mutual TLS (implies --https)")
+	rootCmd.PersistentFlags().StringVar(&clientKey, "client-key", "", "PEM private key for --client-cert (default: read from the certificate file)")
+	rootCmd.PersistentFlags().IntVar(&port, "port", 0, "DWS port (default 80, or 443 with HTTPS; ignored if host includes a port)")
+	rootCmd.PersistentFlags().StringVar(&basePath, "base-path", "", "Path prefix when the DWS is proxied, e.g. /custom")
+	rootCmd.PersistentFlags().BoolVarP(&assumeYes, "yes", "y", false, "Answer yes to all confirmation prompts")
+	rootCmd.PersistentFlags().BoolVar(&assumeYes, "assume-yes", false, "Alias for --yes")
+
+	rootCmd.PersistentPreRun = func(cmd *cobra.Command, args []string) {
+		if len(fields) > 0 {
+			jsonOutput = true
+		}
+		if outputFile != "" {
+			if err := redirectOutput(outputFile); err != nil {
+				handleError(err)
+			}
+		}
+	}
 
 	// Add command groups
 	addInfoCommands()
 	addControlCommands()
 	addFileCommands()
+	addStorageCommands()
 	addDiagnosticsCommands()
 	addDisplayCommands()
 	addRegistryCommands()
 	addLogsCommands()
 	addVideoCommands()
+	addDiscoverCommands()
+	addVersionCommands()
+	addAPICommands()
+	addShellCommands()
 }
 
 // getClient creates a BrightSign client with authentication
 func getClient() (*brightsign.Client, error) {
+	return getClientFor(host)
+}
+
+// requireVersion fails fast when the player's firmware is older than
+// minVersion, instead of letting the missing endpoint answer with a 404
+func requireVersion(client *brightsign.Client, feature, minVersion string) error {
+	if client.SupportsFeature(minVersion) {
+		return nil
+	}
+	version, _ := client.APIVersion()
+	return fmt.Errorf("%s requires BOS %s+ (player runs %s)", feature, minVersion, version)
+}
+
+// clientCacheKey identifies a client by the settings it was created with
+type clientCacheKey struct {
+	config brightsign.Config
+	caCert string
+}
+
+// clients holds the clients created by getClientFor during this run
+var clients = map[clientCacheKey]*brightsign.Client{}
+
+// closeClients releases the idle connections of every client created so far
+func closeClients() {
+	for key, client := range clients {
+		client.Close()
+		delete(clients, key)
+	}
+}
+
+// outputDest is the file --output-file redirected stdout to, and stdout is
+// the original stream restored when the command finishes
+var (
+	outputDest *os.File
+	stdout     = os.Stdout
+)
+
+// redirectOutput points stdout at path so every command's primary output,
+// JSON or text, lands in the file. Prompts keep using the terminal.
+func redirectOutput(path string) error {
+	if outputDest != nil {
+		if outputDest.Name() == path {
+			return nil
+		}
+		restoreOutput()
+	}
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to create output directory: %w", err)
+		}
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create output file: %w", err)
+	}
+	outputDest = f
+	os.Stdout = f
+	return nil
+}
+
+// restoreOutput closes the --output-file destination, if any, and puts the
+// original stdout back
+func restoreOutput() {
+	if outputDest == nil {
+		return
+	}
+	os.Stdout = stdout
+	outputDest.Close()
+	outputDest = nil
+}
+
+// getClientFor creates a client for a specific player using the global
+// credentials, for commands that talk to more than one host
+func getClientFor(host string) (*brightsign.Client, error) {
 	if host == "" {
 		return nil, fmt.Errorf("host is required")
 	}
 
-	// Prompt for password if not provided
+	// Check the client certificate before prompting for a password
+	if clientKey != "" && clientCert == "" {
+		return nil, &usageError{err: fmt.Errorf("--client-key requires --client-cert")}
+	}
+	if clientCert != "" {
+		if _, err := brightsign.LoadClientCertificate(clientCert, clientKey); err != nil {
+			return nil, &usageError{err: err}
+		}
+	}
+
+	// -u "" means the default account, as it does for the library
+	if strings.TrimSpace(username) == "" {
+		username = brightsign.DefaultUsername
+	}
+
+	// Fall back to the environment, then prompt
+	if password == "" {
+		password = getenv("PASSWORD")
+	}
 	if password == "" {
-		fmt.Printf("Password for %s@%s: ", username, host)
+		fmt.Fprintf(promptWriter, "Password for %s@%s: ", username, host)
 		bytePassword, err := term.ReadPassword(int(syscall.Stdin))
 		if err != nil {
 			return nil, fmt.Errorf("failed to read password: %w", err)
 		}
-		fmt.Println()
+		fmt.Fprintln(promptWriter)
 		password = string(bytePassword)
 	}
 
@@ -112,40 +327,167 @@ func getClient() (*brightsign.Client, error) {
 		Username: username,
 		Password: password,
 		Debug:    debug,
-		Insecure: insecure,
+		Trace:    trace,
+		Insecure: insecure || local,
+		HTTPS:    useHTTPS || local,
+		ClientCert: clientCert,
+		ClientKey:  clientKey,
+		NoDecompress: noDecompress,
+		AllowBasic: allowBasic,
+		DumpResponse: dumpResponse,
+		Port:     port,
+		BasePath: basePath,
+		DryRun:   dryRun,
+		AuthCacheDir: authCacheDir(),
+	}
+
+	// Commands that run several times in one process, such as in the shell,
+	// share the connection pool and digest challenge of the first client
+	key := clientCacheKey{config: config, caCert: caCert}
+	if client, ok := clients[key]; ok {
+		return client, nil
 	}
 
-	return brightsign.NewClient(config), nil
+	if caCert != "" {
+		pool, err := loadCACert(caCert)
+		if err != nil {
+			return nil, err
+		}
+		config.RootCAs = pool
+	}
+
+	client := brightsign.NewClient(config)
+	if stdinIsTerminal() {
+		client.SetPasswordPrompt(func() (string, error) {
+			return passwordReader(fmt.Sprintf("Authentication failed. Password for %s@%s: ", username, host))
+		})
+	}
+	clients[key] = client
+	return client, nil
 }
 
+// loadCACert reads a PEM bundle of CA certificates for verifying the player
+func loadCACert(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CA certificate: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no PEM certificates found in %s", path)
+	}
+	return pool, nil
+}
+
+// authCacheDir returns where digest challenges are cached between invocations:
+// $BSCLI_CACHE_DIR (or $BSCLI_TEST_CACHE_DIR), or bscli under the user cache
+// directory. It returns "" when caching is disabled or no cache directory is
+// available.
+func authCacheDir() string {
+	if noAuthCache {
+		return ""
+	}
+	if dir := getenv("CACHE_DIR"); dir != "" {
+		return dir
+	}
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "bscli")
+}
+
+// Exit codes returned by the CLI so scripts can tell failure modes apart
+const (
+	exitError    = 1 // Any other failure
+	exitAuth     = 2 // Authentication failed or access denied (HTTP 401/403)
+	exitNotFound = 3 // Path or endpoint not found (HTTP 404)
+	exitNetwork  = 4 // Connection failure or timeout
+	exitUsage    = 5 // Invalid command, flag or arguments
+)
+
+// usageError marks errors caused by invalid command-line usage
+type usageError struct {
+	err error
+}
+
+func (e *usageError) Error() string { return e.err.Error() }
+func (e *usageError) Unwrap() error { return e.err }
+
+// exit terminates the process. The shell replaces it so that a failing
+// command returns to the prompt instead.
+var exit = os.Exit
+
 // handleError prints an error message and exits
 func handleError(err error) {
+	// The client has already printed the request a dry run skipped
+	if errors.Is(err, brightsign.ErrDryRun) {
+		exit(0)
+		return
+	}
+
 	errMsg := err.Error()
-	
-	// Check for TLS certificate errors and provide helpful suggestions
-	if isTLSError(errMsg) {
-		helpfulMsg := errMsg + "\n\nThis appears to be a TLS certificate error. The player may be using a self-signed certificate.\nTry one of the following:\n  1. Use the --local or -l flag to accept locally signed certificates\n  2. Set environment variable: export BSCLI_TEST_INSECURE=true"
-		if jsonOutput {
-			// For JSON mode, include the helpful message in JSON
-			errorObj := map[string]string{
-				"error": errMsg,
-				"suggestion": "This appears to be a TLS certificate error. Try using --local or -l flag, or set BSCLI_TEST_INSECURE=true",
-			}
-			json.NewEncoder(os.Stdout).Encode(errorObj)
-		} else {
-			fmt.Fprintf(os.Stderr, "Error: %s\n", helpfulMsg)
+	suggestion := errorSuggestion(err)
+	code := exitCode(err)
+
+	if jsonOutput {
+		// For JSON mode, emit the error object on stderr so stdout only ever carries data
+		errorObj := map[string]interface{}{
+			"error": errMsg,
+			"code":  code,
 		}
-	} else {
-		// Regular error handling
-		if jsonOutput {
-			// For JSON mode, output error as JSON to stdout (not stderr for proper JSON parsing)
-			errorObj := map[string]string{"error": errMsg}
-			json.NewEncoder(os.Stdout).Encode(errorObj)
-		} else {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		if isTLSError(errMsg) {
+			suggestion = "This appears to be a TLS certificate error. Try --cacert with the player's CA, the --local or -l flag, or set BSCLI_INSECURE=true"
 		}
+		if suggestion != "" {
+			errorObj["suggestion"] = suggestion
+		}
+		json.NewEncoder(os.Stderr).Encode(errorObj)
+	} else if suggestion != "" {
+		fmt.Fprintf(os.Stderr, "Error: %s\n\n%s\n", errMsg, suggestion)
+	} else {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
 	}
-	os.Exit(1)
+	exit(code)
+}
+
+// errorSuggestion returns a hint for well-known failure modes, or ""
+func errorSuggestion(err error) string {
+	switch {
+	case isTLSError(err.Error()):
+		return "This appears to be a TLS certificate error. The player may be using a self-signed certificate.\nTry one of the following:\n  1. Use --cacert FILE to verify against the CA that signed it\n  2. Use the --local or -l flag to accept locally signed certificates\n  3. Set environment variable: export BSCLI_INSECURE=true"
+	case errors.Is(err, brightsign.ErrBasicAuthDisabled):
+		return "The player only offers Basic authentication, which sends the password unencrypted.\nUse --allow-basic to permit it, preferably together with --https."
+	case errors.Is(err, brightsign.ErrUnauthorized):
+		return "Authentication failed. Check the username (-u) and password (-p) for this player."
+	case errors.Is(err, brightsign.ErrForbidden):
+		return "The player refused the request. The account may not be allowed to perform this operation."
+	case errors.Is(err, brightsign.ErrNotFound):
+		return "The requested path or endpoint was not found. Check the path, or whether this firmware supports the command."
+	case errors.Is(err, brightsign.ErrPlayerBusy):
+		return "The player is still busy with another operation, such as an upload or a reboot. Wait for it to finish and try again."
+	case errors.Is(err, brightsign.ErrServer):
+		return "The player reported an internal error. It may be busy or rebooting; try again shortly."
+	}
+	return ""
+}
+
+// exitCode maps an error to the process exit code
+func exitCode(err error) int {
+	var usageErr *usageError
+	var netErr net.Error
+
+	switch {
+	case errors.As(err, &usageErr):
+		return exitUsage
+	case errors.Is(err, brightsign.ErrUnauthorized), errors.Is(err, brightsign.ErrForbidden):
+		return exitAuth
+	case errors.Is(err, brightsign.ErrNotFound):
+		return exitNotFound
+	case errors.As(err, &netErr):
+		return exitNetwork
+	}
+	return exitError
 }
 
 // isTLSError checks if an error message indicates a TLS certificate problem
@@ -170,7 +512,59 @@ func isTLSError(errMsg string) bool {
 
 // outputJSON outputs data as JSON when --json flag is used
 func outputJSON(data interface{}) {
+	if len(fields) > 0 {
+		projected, err := projectFields(data, fields)
+		if err != nil {
+			handleError(&usageError{err: fmt.Errorf("--fields: %w", err)})
+		}
+		data = projected
+	}
 	if err := json.NewEncoder(os.Stdout).Encode(data); err != nil {
 		handleError(fmt.Errorf("failed to encode JSON: %w", err))
 	}
+}
+
+// parseIntArg parses an integer command argument, reporting name in the
+// usage error when s is not a whole number
+func parseIntArg(s, name string) (int, error) {
+	value, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil {
+		return 0, &usageError{err: fmt.Errorf("invalid %s %q: must be an integer", name, s)}
+	}
+	return value, nil
+}
+
+// infof prints a progress or informational message. It is silent with
+// --quiet and in JSON mode; data and errors are never routed through it.
+// Like prompts it goes to the terminal, not an --output-file.
+func infof(format string, args ...interface{}) {
+	if quiet || jsonOutput {
+		return
+	}
+	fmt.Fprintf(promptWriter, format+"\n", args...)
+}
+
+// outputSuccess outputs the standard JSON envelope for a completed action.
+// Errors never carry "success" and successes never carry "error", so scripts
+// can tell them apart by key.
+func outputSuccess(action string, fields map[string]interface{}) {
+	result := map[string]interface{}{
+		"success": true,
+		"action":  action,
+	}
+	for key, value := range fields {
+		result[key] = value
+	}
+	outputJSON(result)
+}
+
+// reportSuccess reports a completed action: message for people, or the
+// outputSuccess envelope with fields under --json. Mutating commands use it
+// so automation can parse every outcome the same way.
+func reportSuccess(action, message string, fields map[string]interface{}) {
+	if jsonOutput {
+		outputSuccess(action, fields)
+		return
+	}
+	fmt.Println(message)
 }
\ No newline at end of file
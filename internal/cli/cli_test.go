@@ -1,9 +1,22 @@
 package cli
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"bscli/pkg/brightsign"
+	"github.com/spf13/cobra"
 )
 
 func TestGetClient_ValidConfig(t *testing.T) {
@@ -106,4 +119,1561 @@ func TestBrightSignClientCreation(t *testing.T) {
 			t.Errorf("%s service not initialized", name)
 		}
 	}
+}
+
+func TestExitCode(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected int
+	}{
+		{&brightsign.APIError{StatusCode: 401}, exitAuth},
+		{&brightsign.APIError{StatusCode: 403}, exitAuth},
+		{&brightsign.APIError{StatusCode: 404}, exitNotFound},
+		{&brightsign.APIError{StatusCode: 500}, exitError},
+		{fmt.Errorf("wrapped: %w", &brightsign.APIError{StatusCode: 404}), exitNotFound},
+		{errors.New("something else"), exitError},
+	}
+
+	for _, test := range tests {
+		if code := exitCode(test.err); code != test.expected {
+			t.Errorf("exitCode(%v): expected %d, got %d", test.err, test.expected, code)
+		}
+	}
+}
+
+func TestIsHostless(t *testing.T) {
+	tests := []struct {
+		args     []string
+		expected bool
+	}{
+		{[]string{"video", "decode-edid", "edid.hex"}, true},
+		{[]string{"video"}, false},
+		{[]string{"discover", "--subnet", "10.0.0.0/24"}, true},
+		{[]string{"192.168.1.100", "video", "decode-edid", "edid.hex"}, false},
+		{[]string{"192.168.1.100", "info", "device"}, false},
+	}
+
+	for _, test := range tests {
+		if result := isHostless(test.args); result != test.expected {
+			t.Errorf("isHostless(%v) = %v, expected %v", test.args, result, test.expected)
+		}
+	}
+}
+
+func TestConfirmDestructive(t *testing.T) {
+	defer func(r io.Reader) { confirmReader = r }(confirmReader)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+		result   bool
+	}{
+		{"exact match", "sd\n", "sd", true},
+		{"surrounding whitespace", "  FACTORY-RESET \n", "FACTORY-RESET", true},
+		{"bare y", "y\n", "sd", false},
+		{"wrong device", "usb1\n", "sd", false},
+		{"case mismatch", "factory-reset\n", "FACTORY-RESET", false},
+		{"no input", "", "sd", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			confirmReader = strings.NewReader(test.input)
+			if result := confirmDestructive("Format?", test.expected); result != test.result {
+				t.Errorf("confirmDestructive with input %q = %v, expected %v", test.input, result, test.result)
+			}
+		})
+	}
+}
+
+func TestConfirm(t *testing.T) {
+	defer func(r io.Reader) { confirmReader = r }(confirmReader)
+
+	tests := []struct {
+		input    string
+		expected bool
+	}{
+		{"y\n", true},
+		{"Y\n", true},
+		{"n\n", false},
+		{"\n", false},
+		{"yes please\n", false},
+		{"", false},
+	}
+
+	for _, test := range tests {
+		confirmReader = strings.NewReader(test.input)
+		if result := confirm("Delete?"); result != test.expected {
+			t.Errorf("confirm with input %q = %v, expected %v", test.input, result, test.expected)
+		}
+	}
+}
+
+func TestConfirmReadsOneLinePerPrompt(t *testing.T) {
+	defer func(r io.Reader) { confirmReader = r }(confirmReader)
+
+	confirmReader = strings.NewReader("y\nn\n")
+	if !confirm("First?") {
+		t.Error("Expected first prompt to be confirmed")
+	}
+	if confirm("Second?") {
+		t.Error("Expected second prompt to be declined")
+	}
+}
+
+// failingReader fails the test if a prompt tries to read input
+type failingReader struct {
+	t *testing.T
+}
+
+func (r failingReader) Read(p []byte) (int, error) {
+	r.t.Error("Confirmation read from input although assumeYes is set")
+	return 0, io.EOF
+}
+
+func TestAssumeYesBypassesReader(t *testing.T) {
+	defer func(r io.Reader) { confirmReader = r }(confirmReader)
+	defer func() { assumeYes = false }()
+
+	assumeYes = true
+	confirmReader = failingReader{t: t}
+
+	if !confirm("Delete?") {
+		t.Error("Expected confirm to succeed with assumeYes")
+	}
+	if !confirmDestructive("Format?", "sd") {
+		t.Error("Expected confirmDestructive to succeed with assumeYes")
+	}
+}
+
+func TestValidateFirmwareFile(t *testing.T) {
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "update.bsfw")
+	os.WriteFile(valid, []byte("firmware"), 0644)
+
+	empty := filepath.Join(dir, "empty.bsfw")
+	os.WriteFile(empty, nil, 0644)
+
+	wrongExt := filepath.Join(dir, "update.zip")
+	os.WriteFile(wrongExt, []byte("firmware"), 0644)
+
+	if size, err := validateFirmwareFile(valid); err != nil || size != 8 {
+		t.Errorf("Expected valid firmware of 8 bytes, got size %d, err %v", size, err)
+	}
+
+	for _, path := range []string{empty, wrongExt, filepath.Join(dir, "missing.bsfw")} {
+		if _, err := validateFirmwareFile(path); err == nil {
+			t.Errorf("Expected an error for %s", path)
+		}
+	}
+}
+
+func TestRegistryWriterValidation(t *testing.T) {
+	tests := []struct {
+		valueType string
+		value     string
+		valid     bool
+	}{
+		{"string", "anything", true},
+		{"int", "5", true},
+		{"int", "five", false},
+		{"bool", "yes", true},
+		{"bool", "maybe", false},
+		{"json", `{"a":1}`, true},
+		{"json", `{"a":`, false},
+		{"float", "1.5", false},
+	}
+
+	for _, test := range tests {
+		_, err := registryWriter(test.valueType, test.value)
+		if (err == nil) != test.valid {
+			t.Errorf("registryWriter(%q, %q) error = %v, expected valid=%v", test.valueType, test.value, err, test.valid)
+		}
+	}
+}
+
+func TestRegistryWriterKeepsJSONText(t *testing.T) {
+	var body map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":true}}`))
+	}))
+	defer server.Close()
+
+	client := brightsign.NewClient(brightsign.Config{Host: server.URL[7:], Password: "pw"})
+	value := `{"z": 1.50, "a": [1e3]}`
+	write, err := registryWriter("json", value)
+	if err != nil {
+		t.Fatalf("registryWriter failed: %v", err)
+	}
+	if err := write(client.Registry, "html", "config"); err != nil {
+		t.Fatalf("write failed: %v", err)
+	}
+	if body["value"] != value {
+		t.Errorf("Expected the JSON to be stored unchanged as %q, got %q", value, body["value"])
+	}
+}
+
+func TestParseKeyValues(t *testing.T) {
+	pairs, err := parseKeyValues([]string{"foo=bar", "url=http://x/?a=b", "empty="})
+	if err != nil {
+		t.Fatalf("parseKeyValues failed: %v", err)
+	}
+
+	expected := []keyValue{{"foo", "bar"}, {"url", "http://x/?a=b"}, {"empty", ""}}
+	if len(pairs) != len(expected) {
+		t.Fatalf("Expected %d pairs, got %d", len(expected), len(pairs))
+	}
+	for i := range expected {
+		if pairs[i] != expected[i] {
+			t.Errorf("Expected pair %d to be %+v, got %+v", i, expected[i], pairs[i])
+		}
+	}
+
+	for _, bad := range [][]string{{"novalue"}, {"=value"}, {"a=1", "a=2"}} {
+		if _, err := parseKeyValues(bad); err == nil {
+			t.Errorf("Expected an error for %v", bad)
+		}
+	}
+}
+
+func TestSetRegistryValuesPartialFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/readonly/") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := brightsign.NewClient(brightsign.Config{Host: server.URL[7:], Password: "pw"})
+
+	results, err := setRegistryValues(client.Registry, "networking", []keyValue{
+		{"first", "1"},
+		{"readonly", "2"},
+		{"last", "3"},
+	})
+	if err != nil {
+		t.Fatalf("Expected failures in the results, got %v", err)
+	}
+
+	if len(results) != 3 {
+		t.Fatalf("Expected all 3 pairs to be attempted, got %d results", len(results))
+	}
+
+	expected := []bool{true, false, true}
+	for i, result := range results {
+		if result.Success != expected[i] {
+			t.Errorf("Expected %s success=%v, got %v (%s)", result.Key, expected[i], result.Success, result.Error)
+		}
+	}
+
+	if results[1].Error == "" {
+		t.Error("Expected an error message for the failed key")
+	}
+}
+
+func TestParseUploadManifest(t *testing.T) {
+	text := "# assets\nvideo.mp4 -> /storage/sd/video.mp4\n\n/abs/img.png -> media/img.png\n"
+	entries, err := parseUploadManifest([]byte(text), "/work")
+	if err != nil {
+		t.Fatalf("parseUploadManifest failed: %v", err)
+	}
+
+	expected := []uploadEntry{
+		{Local: "/work/video.mp4", Remote: "/storage/sd/video.mp4"},
+		{Local: "/abs/img.png", Remote: "/storage/sd/media/img.png"},
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("Expected %d entries, got %d", len(expected), len(entries))
+	}
+	for i := range expected {
+		if entries[i] != expected[i] {
+			t.Errorf("Expected entry %d to be %+v, got %+v", i, expected[i], entries[i])
+		}
+	}
+
+	jsonManifest := `[{"local": "a.mp4", "remote": "/storage/sd/a.mp4"}]`
+	entries, err = parseUploadManifest([]byte(jsonManifest), "/work")
+	if err != nil || len(entries) != 1 || entries[0].Local != "/work/a.mp4" {
+		t.Errorf("Unexpected JSON manifest result: %+v, %v", entries, err)
+	}
+
+	for _, bad := range []string{"no arrow here", "[{\"local\": \"a\"}]", "", "[not json"} {
+		if _, err := parseUploadManifest([]byte(bad), "/work"); err == nil {
+			t.Errorf("Expected an error for manifest %q", bad)
+		}
+	}
+}
+
+func TestUploadBatchConcurrency(t *testing.T) {
+	const concurrency = 3
+
+	var mu sync.Mutex
+	var active, maxActive, puts int
+	seen := make(map[string]bool)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		active++
+		puts++
+		if active > maxActive {
+			maxActive = active
+		}
+		mu.Unlock()
+
+		_, header, err := r.FormFile("file")
+		if err == nil {
+			mu.Lock()
+			seen[header.Filename] = true
+			mu.Unlock()
+		}
+
+		time.Sleep(20 * time.Millisecond)
+
+		mu.Lock()
+		active--
+		mu.Unlock()
+
+		if err == nil && header.Filename == "broken.mp4" {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := brightsign.NewClient(brightsign.Config{Host: server.URL[7:], Password: "pw"})
+
+	dir := t.TempDir()
+	var entries []uploadEntry
+	for i := 0; i < 10; i++ {
+		name := fmt.Sprintf("file%d.mp4", i)
+		if i == 4 {
+			name = "broken.mp4"
+		}
+		local := filepath.Join(dir, name)
+		os.WriteFile(local, []byte("data"), 0644)
+		entries = append(entries, uploadEntry{Local: local, Remote: "/storage/sd/" + name})
+	}
+	entries = append(entries, uploadEntry{Local: filepath.Join(dir, "missing.mp4"), Remote: "/storage/sd/missing.mp4"})
+
+	results, err := uploadBatch(client.Storage, entries, concurrency)
+	if err != nil {
+		t.Fatalf("Expected failures in the results, got %v", err)
+	}
+
+	if len(results) != len(entries) {
+		t.Fatalf("Expected %d results, got %d", len(entries), len(results))
+	}
+
+	if puts != 10 || len(seen) != 10 {
+		t.Errorf("Expected all 10 existing files to be uploaded, got %d PUTs for %d files", puts, len(seen))
+	}
+
+	if maxActive > concurrency {
+		t.Errorf("Expected at most %d concurrent uploads, saw %d", concurrency, maxActive)
+	}
+
+	for i, result := range results {
+		if result.Remote != entries[i].Remote {
+			t.Errorf("Expected results in manifest order, got %s at %d", result.Remote, i)
+		}
+		shouldFail := strings.HasSuffix(result.Remote, "broken.mp4") || strings.HasSuffix(result.Remote, "missing.mp4")
+		if result.Success == shouldFail {
+			t.Errorf("Unexpected outcome for %s: success=%v (%s)", result.Remote, result.Success, result.Error)
+		}
+	}
+}
+
+func TestCatFileGuards(t *testing.T) {
+	files := map[string][]byte{
+		"/api/v1/files/sd/autorun.brs": []byte("Sub Main()\nEnd Sub\n"),
+		"/api/v1/files/sd/video.mp4":   append([]byte{0x00, 0x00, 0x00, 0x18, 'f', 't', 'y', 'p'}, make([]byte, 100)...),
+		"/api/v1/files/sd/big.log":     []byte(strings.Repeat("x", maxCatSize+10)),
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(files[r.URL.Path])
+	}))
+	defer server.Close()
+
+	client := brightsign.NewClient(brightsign.Config{Host: server.URL[7:], Password: "pw"})
+
+	var buf strings.Builder
+	if err := catFile(client.Storage, "/storage/sd/autorun.brs", &buf, false); err != nil {
+		t.Fatalf("catFile failed: %v", err)
+	}
+	if buf.String() != "Sub Main()\nEnd Sub\n" {
+		t.Errorf("Unexpected output %q", buf.String())
+	}
+
+	for _, path := range []string{"/storage/sd/video.mp4", "/storage/sd/big.log"} {
+		buf.Reset()
+		if err := catFile(client.Storage, path, &buf, false); err == nil {
+			t.Errorf("Expected %s to be refused without --force", path)
+		}
+		if buf.Len() != 0 {
+			t.Errorf("Expected no output for refused %s, got %d bytes", path, buf.Len())
+		}
+
+		buf.Reset()
+		if err := catFile(client.Storage, path, &buf, true); err != nil {
+			t.Errorf("Expected %s to print with --force: %v", path, err)
+		}
+	}
+}
+
+func TestSearchRegistry(t *testing.T) {
+	snapshot := brightsign.RegistrySnapshot{
+		"networking": {"ssh": "22", "telnet": "disabled", "Hostname": "Lobby-Player"},
+		"html":       {"enable_web_inspector": "1"},
+	}
+
+	keys := func(matches []Match) string {
+		var result []string
+		for _, match := range matches {
+			result = append(result, match.Section+"/"+match.Key)
+		}
+		return strings.Join(result, ",")
+	}
+
+	tests := []struct {
+		name     string
+		opts     SearchOptions
+		expected string
+	}{
+		{"SubstringIgnoresCase", SearchOptions{Term: "lobby"}, "networking/Hostname"},
+		{"CaseSensitive", SearchOptions{Term: "lobby", CaseSensitive: true}, ""},
+		{"SectionMatchesAllKeys", SearchOptions{Term: "html"}, "html/enable_web_inspector"},
+		{"Regex", SearchOptions{Term: "^(ssh|telnet)$", Regex: true}, "networking/ssh,networking/telnet"},
+		{"RegexIgnoresCase", SearchOptions{Term: "^hostname$", Regex: true}, "networking/Hostname"},
+		{"RegexCaseSensitive", SearchOptions{Term: "^hostname$", Regex: true, CaseSensitive: true}, ""},
+		{"KeysOnly", SearchOptions{Term: "^[0-9]+$", Regex: true, Scope: searchKeys}, ""},
+		{"ValuesOnly", SearchOptions{Term: "^[0-9]+$", Regex: true, Scope: searchValues}, "html/enable_web_inspector,networking/ssh"},
+		{"ValuesOnlySkipsKeys", SearchOptions{Term: "ssh", Scope: searchValues}, ""},
+		{"InvalidRegex", SearchOptions{Term: "(", Regex: true}, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if result := keys(searchRegistry(snapshot, test.opts)); result != test.expected {
+				t.Errorf("Expected %q, got %q", test.expected, result)
+			}
+		})
+	}
+}
+
+// fixedClock is a clock frozen at a single instant
+type fixedClock struct {
+	now time.Time
+}
+
+func (c fixedClock) Now() time.Time { return c.now }
+
+func TestSyncTime(t *testing.T) {
+	var body map[string]interface{}
+	zones := `["America/Los_Angeles","UTC"]`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == "GET" && r.URL.Path == "/api/v1/time/timezones/" {
+			w.Write([]byte(`{"data":{"result":` + zones + `}}`))
+			return
+		}
+		if r.Method != "PUT" || r.URL.Path != "/api/v1/time/" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("Failed to decode request body: %v", err)
+		}
+		w.Write([]byte(`{"data":{"result":true}}`))
+	}))
+	defer server.Close()
+
+	client := brightsign.NewClient(brightsign.Config{Host: server.URL[7:], Password: "pw"})
+	losAngeles, err := time.LoadLocation("America/Los_Angeles")
+	if err != nil {
+		t.Skipf("timezone database not available: %v", err)
+	}
+	at := time.Date(2025, 6, 14, 23, 30, 5, 0, losAngeles)
+
+	tests := []struct {
+		name     string
+		now      time.Time
+		utc      bool
+		expected map[string]interface{}
+	}{
+		{"Local", at, false, map[string]interface{}{"date": "2025-06-14", "time": "23:30:05", "timezone": "America/Los_Angeles"}},
+		{"UTC", at, true, map[string]interface{}{"date": "2025-06-15", "time": "06:30:05", "timezone": "UTC"}},
+		// An abbreviation is never sent; the instant goes out in UTC instead
+		{"Fixed offset", at.In(time.FixedZone("PDT", -7*60*60)), false, map[string]interface{}{"date": "2025-06-15", "time": "06:30:05", "timezone": "UTC"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			body = nil
+			if _, err := syncTime(client, fixedClock{now: test.now}, test.utc); err != nil {
+				t.Fatalf("syncTime failed: %v", err)
+			}
+			for key, value := range test.expected {
+				if body[key] != value {
+					t.Errorf("Expected %s %v, got %v", key, value, body[key])
+				}
+			}
+		})
+	}
+
+	// A zone the player does not list is rejected before the time is set
+	zones = `["UTC"]`
+	body = nil
+	if _, err := syncTime(client, fixedClock{now: at}, false); err == nil || !strings.Contains(err.Error(), "America/Los_Angeles") {
+		t.Errorf("Expected an unknown timezone error, got %v", err)
+	}
+	if body != nil {
+		t.Errorf("Expected no time to be set, got %v", body)
+	}
+}
+
+// cannedPasswords returns a passwordReader that answers prompts in order
+func cannedPasswords(answers ...string) func(string) (string, error) {
+	return func(prompt string) (string, error) {
+		if len(answers) == 0 {
+			return "", io.EOF
+		}
+		answer := answers[0]
+		answers = answers[1:]
+		return answer, nil
+	}
+}
+
+func TestReadNewPassword(t *testing.T) {
+	defer func(r func(string) (string, error)) { passwordReader = r }(passwordReader)
+
+	tests := []struct {
+		name     string
+		answers  []string
+		expected string
+		usage    bool
+	}{
+		{"matching", []string{"s3cret", "s3cret"}, "s3cret", false},
+		{"mismatch", []string{"s3cret", "s3cert"}, "", true},
+		{"empty", []string{"", ""}, "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			passwordReader = cannedPasswords(test.answers...)
+			result, err := readNewPassword()
+
+			var usage *usageError
+			if errors.As(err, &usage) != test.usage {
+				t.Errorf("Expected usage error %v, got %v", test.usage, err)
+			}
+			if result != test.expected {
+				t.Errorf("Expected password %q, got %q", test.expected, result)
+			}
+		})
+	}
+}
+
+func TestReadPasswordLine(t *testing.T) {
+	result, err := readPasswordLine(strings.NewReader(" pass word \r\nignored\n"))
+	if err != nil {
+		t.Fatalf("readPasswordLine failed: %v", err)
+	}
+	if result != " pass word " {
+		t.Errorf("Expected only the line ending stripped, got %q", result)
+	}
+
+	if _, err := readPasswordLine(strings.NewReader("")); err == nil {
+		t.Error("Expected an error for empty stdin")
+	}
+}
+
+func TestRunPacketCapture(t *testing.T) {
+	defer func(d time.Duration) { pcapPollInterval = d }(pcapPollInterval)
+	pcapPollInterval = time.Millisecond
+
+	var mu sync.Mutex
+	var started bool
+	polls := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case r.Method == "POST" && r.URL.Path == "/api/v1/diagnostics/packet-capture/":
+			var config brightsign.PacketCaptureConfig
+			json.NewDecoder(r.Body).Decode(&config)
+			if config.Interface != "eth0" || config.Duration != 30 {
+				t.Errorf("Unexpected capture config: %+v", config)
+			}
+			started = true
+			w.Write([]byte(`{"data":{"result":{}}}`))
+		case r.Method == "GET" && r.URL.Path == "/api/v1/diagnostics/packet-capture/":
+			polls++
+			running := polls < 3
+			fmt.Fprintf(w, `{"data":{"result":{"running":%t,"bytesCaptured":4,"outputFile":"/storage/sd/cap.pcap"}}}`, running)
+		case r.Method == "GET" && r.URL.Path == "/api/v1/files/sd/cap.pcap":
+			if polls < 3 {
+				t.Error("Downloaded the capture before it finished")
+			}
+			w.Write([]byte("pcap"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	client := brightsign.NewClient(brightsign.Config{Host: server.URL[7:], Password: "pw"})
+	localPath := filepath.Join(t.TempDir(), "out.pcap")
+
+	config := brightsign.PacketCaptureConfig{Interface: "eth0", Duration: 30, OutputFile: "/storage/sd/cap.pcap"}
+	status, err := runPacketCapture(client, config, localPath, time.Minute)
+	if err != nil {
+		t.Fatalf("runPacketCapture failed: %v", err)
+	}
+
+	if !started {
+		t.Error("Expected the capture to be started")
+	}
+	if polls != 3 {
+		t.Errorf("Expected 3 status polls, got %d", polls)
+	}
+	if status.BytesCaptured != 4 {
+		t.Errorf("Expected 4 bytes captured, got %d", status.BytesCaptured)
+	}
+
+	data, err := os.ReadFile(localPath)
+	if err != nil {
+		t.Fatalf("Failed to read downloaded capture: %v", err)
+	}
+	if string(data) != "pcap" {
+		t.Errorf("Expected capture contents %q, got %q", "pcap", data)
+	}
+}
+
+func TestRunPacketCaptureTimeout(t *testing.T) {
+	defer func(d time.Duration) { pcapPollInterval = d }(pcapPollInterval)
+	pcapPollInterval = time.Millisecond
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/diagnostics/packet-capture/" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		w.Write([]byte(`{"data":{"result":{"running":true}}}`))
+	}))
+	defer server.Close()
+
+	client := brightsign.NewClient(brightsign.Config{Host: server.URL[7:], Password: "pw"})
+
+	config := brightsign.PacketCaptureConfig{Interface: "eth0", Duration: 1, OutputFile: "/storage/sd/cap.pcap"}
+	_, err := runPacketCapture(client, config, filepath.Join(t.TempDir(), "out.pcap"), 10*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "still running") {
+		t.Errorf("Expected a timeout error, got %v", err)
+	}
+}
+
+func TestFlattenRegistry(t *testing.T) {
+	snapshot := brightsign.RegistrySnapshot{
+		"networking":   {"ssh": "22", "dhcp": "yes"},
+		"html":         {"enable_web_inspector": "1"},
+		"brightscript": {"debug": "0"},
+	}
+
+	var lines []string
+	for _, entry := range flattenRegistry(snapshot) {
+		lines = append(lines, formatMatch(entry))
+	}
+
+	expected := []string{
+		"brightscript/debug = 0",
+		"html/enable_web_inspector = 1",
+		"networking/dhcp = yes",
+		"networking/ssh = 22",
+	}
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected flat output:\n%s\ngot:\n%s", strings.Join(expected, "\n"), strings.Join(lines, "\n"))
+	}
+}
+
+func TestParseIntArg(t *testing.T) {
+	tests := []struct {
+		arg      string
+		expected int
+		wantErr  bool
+	}{
+		{"50", 50, false},
+		{"-1", -1, false},
+		{" 7 ", 7, false},
+		{"abc", 0, true},
+		{"50%", 0, true},
+		{"1.5", 0, true},
+		{"", 0, true},
+	}
+
+	for _, test := range tests {
+		value, err := parseIntArg(test.arg, "volume")
+		if (err != nil) != test.wantErr {
+			t.Errorf("parseIntArg(%q) error = %v, wantErr %v", test.arg, err, test.wantErr)
+			continue
+		}
+		if err != nil {
+			var usage *usageError
+			if !errors.As(err, &usage) {
+				t.Errorf("Expected a usage error for %q, got %T", test.arg, err)
+			}
+			if !strings.Contains(err.Error(), "volume") {
+				t.Errorf("Expected the argument name in %q", err.Error())
+			}
+			continue
+		}
+		if value != test.expected {
+			t.Errorf("parseIntArg(%q) = %d, expected %d", test.arg, value, test.expected)
+		}
+	}
+}
+
+func TestCheckDisplayLevel(t *testing.T) {
+	tests := []struct {
+		name     string
+		value    int
+		min, max int
+		wantErr  bool
+	}{
+		{"in range", 50, 0, 100, false},
+		{"at min", 0, 0, 100, false},
+		{"at max", 100, 0, 100, false},
+		{"below min", -1, 0, 100, true},
+		{"above max", 101, 0, 100, true},
+		{"no bounds reported", 250, 0, 0, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkDisplayLevel("brightness", test.value, test.min, test.max)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("checkDisplayLevel(%d) error = %v, wantErr %v", test.value, err, test.wantErr)
+			}
+			var usage *usageError
+			if err != nil && !errors.As(err, &usage) {
+				t.Errorf("Expected a usage error, got %T", err)
+			}
+		})
+	}
+}
+
+func TestSplitShellLine(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected []string
+		wantErr  bool
+	}{
+		{"info health", []string{"info", "health"}, false},
+		{"  file   list\t/storage/sd/ ", []string{"file", "list", "/storage/sd/"}, false},
+		{`registry set html name "my player"`, []string{"registry", "set", "html", "name", "my player"}, false},
+		{`registry set html name 'say "hi"'`, []string{"registry", "set", "html", "name", `say "hi"`}, false},
+		{`file cat /storage/sd/a\ b.txt`, []string{"file", "cat", "/storage/sd/a b.txt"}, false},
+		{`registry set html name ""`, []string{"registry", "set", "html", "name", ""}, false},
+		{"", nil, false},
+		{`registry set html name "open`, nil, true},
+	}
+
+	for _, test := range tests {
+		words, err := splitShellLine(test.line)
+		if (err != nil) != test.wantErr {
+			t.Errorf("splitShellLine(%q) error = %v, wantErr %v", test.line, err, test.wantErr)
+			continue
+		}
+		if strings.Join(words, "|") != strings.Join(test.expected, "|") || len(words) != len(test.expected) {
+			t.Errorf("splitShellLine(%q) = %q, expected %q", test.line, words, test.expected)
+		}
+	}
+}
+
+func TestColorize(t *testing.T) {
+	defer func(f func() bool) { stdoutIsTerminal = f }(stdoutIsTerminal)
+	defer func(v bool) { noColor = v }(noColor)
+	t.Setenv("NO_COLOR", "")
+	os.Unsetenv("NO_COLOR")
+
+	stdoutIsTerminal = func() bool { return true }
+	noColor = false
+	if got := green("ok"); got != "\033[32mok\033[0m" {
+		t.Errorf("Expected green on a terminal, got %q", got)
+	}
+
+	noColor = true
+	if got := green("ok"); got != "ok" {
+		t.Errorf("Expected no color with --no-color, got %q", got)
+	}
+
+	noColor = false
+	t.Setenv("NO_COLOR", "1")
+	if got := red("failed"); got != "failed" {
+		t.Errorf("Expected no color with NO_COLOR set, got %q", got)
+	}
+
+	os.Unsetenv("NO_COLOR")
+	stdoutIsTerminal = func() bool { return false }
+	if got := bold("TYPE"); got != "TYPE" {
+		t.Errorf("Expected no color when stdout is not a terminal, got %q", got)
+	}
+}
+
+func TestRenderDiagnostics(t *testing.T) {
+	defer func(f func() bool) { stdoutIsTerminal = f }(stdoutIsTerminal)
+	stdoutIsTerminal = func() bool { return false }
+
+	results := []brightsign.DiagnosticResult{
+		{Test: "ethernet", Status: "pass", DurationMs: 12},
+		{Test: "dns", Status: "fail", Message: "timeout", DurationMs: 1500},
+		{Test: "internet", Status: "error"},
+		{Test: "gateway", Status: "fail", Message: "no route\n\tcheck\tcabling\n"},
+		{Test: "wifi", Status: "pass"},
+	}
+
+	var out strings.Builder
+	renderDiagnostics(&out, results)
+
+	expected := "" +
+		"  TEST      TIME  MESSAGE\n" +
+		"✓ ethernet  12ms\n" +
+		"✗ dns       1.5s  timeout\n" +
+		"✗ internet  -     error\n" +
+		"✗ gateway   -     no route check cabling\n" +
+		"✓ wifi      -\n"
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestGetenv(t *testing.T) {
+	tests := []struct {
+		name    string
+		newVal  string
+		testVal string
+		want    string
+	}{
+		{"new name", "10.0.0.1", "", "10.0.0.1"},
+		{"old name", "", "10.0.0.2", "10.0.0.2"},
+		{"new name wins", "10.0.0.1", "10.0.0.2", "10.0.0.1"},
+		{"unset", "", "", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv("BSCLI_HOST", test.newVal)
+			t.Setenv("BSCLI_TEST_HOST", test.testVal)
+			if got := getenv("HOST"); got != test.want {
+				t.Errorf("getenv(HOST) = %q, want %q", got, test.want)
+			}
+		})
+	}
+
+	t.Setenv("BSCLI_DEBUG", "false")
+	t.Setenv("BSCLI_TEST_DEBUG", "true")
+	if getenvBool("DEBUG") {
+		t.Error("Expected BSCLI_DEBUG=false to override BSCLI_TEST_DEBUG=true")
+	}
+}
+
+func TestGetClientPasswordFromEnv(t *testing.T) {
+	defer func() { host, password = "", "" }()
+	host = "192.168.1.100"
+	password = ""
+	t.Setenv("BSCLI_PASSWORD", "")
+	t.Setenv("BSCLI_TEST_PASSWORD", "legacy")
+
+	if _, err := getClient(); err != nil {
+		t.Fatalf("getClient failed: %v", err)
+	}
+	if password != "legacy" {
+		t.Errorf("Expected password from BSCLI_TEST_PASSWORD, got %q", password)
+	}
+}
+
+func TestIsCommand(t *testing.T) {
+	for _, name := range []string{"info", "files", "help", "--json"} {
+		if !isCommand(name) {
+			t.Errorf("Expected %q to be a command", name)
+		}
+	}
+	for _, name := range []string{"192.168.1.100", "player.local"} {
+		if isCommand(name) {
+			t.Errorf("Expected %q to be a host", name)
+		}
+	}
+}
+
+func TestPlanSync(t *testing.T) {
+	localDir := t.TempDir()
+	files := map[string]string{
+		"same.mp4":        "same",
+		"changed.mp4":     "changed content",
+		"new.mp4":         "new",
+		"media/clip.mp4":  "clip",
+		"media/a/b/c.txt": "deep",
+	}
+	for name, content := range files {
+		localPath := filepath.Join(localDir, filepath.FromSlash(name))
+		os.MkdirAll(filepath.Dir(localPath), 0755)
+		if err := os.WriteFile(localPath, []byte(content), 0644); err != nil {
+			t.Fatalf("Failed to create %s: %v", name, err)
+		}
+	}
+	old := time.Now().Add(-time.Hour)
+	os.Chtimes(filepath.Join(localDir, "same.mp4"), old, old)
+
+	remote := []brightsign.FileInfo{
+		{Name: "same.mp4", Path: "/storage/sd/same.mp4", Type: "file", Size: 4},
+		{Name: "changed.mp4", Path: "/storage/sd/changed.mp4", Type: "file", Size: 3},
+		{Name: "stale.mp4", Path: "/storage/sd/stale.mp4", Type: "file", Size: 5},
+		{Name: "media", Path: "/storage/sd/media", Type: "directory"},
+		{Name: "clip.mp4", Path: "/storage/sd/media/clip.mp4", Type: "file", Size: 4, Modified: "2000-01-01T00:00:00Z"},
+	}
+
+	plan, err := planSync(localDir, "/storage/sd", remote, true, false, true)
+	if err != nil {
+		t.Fatalf("planSync failed: %v", err)
+	}
+
+	var got []string
+	for _, action := range plan {
+		got = append(got, action.Action+" "+action.Remote+" "+action.Reason)
+	}
+	expected := []string{
+		"mkdir /storage/sd/media/a ",
+		"mkdir /storage/sd/media/a/b ",
+		"upload /storage/sd/changed.mp4 size changed",
+		"upload /storage/sd/media/a/b/c.txt new",
+		"upload /storage/sd/new.mp4 new",
+		"delete /storage/sd/stale.mp4 not present locally",
+	}
+	if strings.Join(got, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Unexpected plan:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(expected, "\n"))
+	}
+
+	// With --mtime the older remote clip is replaced; without --delete nothing is removed
+	plan, err = planSync(localDir, "/storage/sd", remote, true, true, false)
+	if err != nil {
+		t.Fatalf("planSync failed: %v", err)
+	}
+	for _, action := range plan {
+		if action.Action == "delete" {
+			t.Errorf("Unexpected delete without --delete: %s", action.Remote)
+		}
+		if action.Remote == "/storage/sd/same.mp4" {
+			t.Errorf("Expected unchanged older file to be skipped")
+		}
+	}
+	found := false
+	for _, action := range plan {
+		if action.Remote == "/storage/sd/media/clip.mp4" && action.Reason == "newer" {
+			found = true
+		}
+	}
+	if !found {
+		t.Errorf("Expected media/clip.mp4 to be uploaded as newer, got %+v", plan)
+	}
+}
+
+func TestPrintNetworkConfig(t *testing.T) {
+	var out strings.Builder
+	printNetworkConfig(&out, &brightsign.NetworkConfig{
+		Interface: "eth0",
+		IP:        "192.168.1.100",
+		Netmask:   "255.255.255.0",
+		Gateway:   "192.168.1.1",
+		DNS:       []string{"8.8.8.8", "1.1.1.1"},
+		VLANID:    20,
+	}, "")
+
+	expected := "" +
+		"Interface: eth0\n" +
+		"DHCP: off\n" +
+		"IP Address: 192.168.1.100\n" +
+		"Netmask: 255.255.255.0\n" +
+		"Gateway: 192.168.1.1\n" +
+		"DNS: 8.8.8.8, 1.1.1.1\n" +
+		"VLAN: 20\n"
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	out.Reset()
+	printNetworkConfig(&out, &brightsign.NetworkConfig{Interface: "wlan0", DHCP: true}, "  ")
+	if out.String() != "  Interface: wlan0\n  DHCP: on\n" {
+		t.Errorf("Expected only interface and DHCP for a DHCP config, got %q", out.String())
+	}
+}
+
+func TestProjectFields(t *testing.T) {
+	info := brightsign.DeviceInfo{
+		Model:  "XT1144",
+		Serial: "XTC35T000155",
+		Network: brightsign.NetworkInfo{
+			Hostname: "lobby",
+			Interfaces: []brightsign.NetworkInterface{
+				{Name: "eth0", IP: "192.168.1.100"},
+				{Name: "wlan0", IP: "192.168.2.100"},
+			},
+		},
+	}
+
+	tests := []struct {
+		fields []string
+		want   string
+	}{
+		{[]string{"model", "network.hostname"}, `{"model":"XT1144","network":{"hostname":"lobby"}}`},
+		{[]string{"network.interfaces.name"}, `{"network":{"interfaces":[{"name":"eth0"},{"name":"wlan0"}]}}`},
+		{[]string{"serial", "missing", "model.nested"}, `{"serial":"XTC35T000155"}`},
+	}
+
+	for _, tt := range tests {
+		projected, err := projectFields(info, tt.fields)
+		if err != nil {
+			t.Fatalf("projectFields(%v) failed: %v", tt.fields, err)
+		}
+		got, _ := json.Marshal(projected)
+		if string(got) != tt.want {
+			t.Errorf("projectFields(%v) = %s, want %s", tt.fields, got, tt.want)
+		}
+	}
+
+	list, _ := projectFields([]brightsign.NetworkInterface{{Name: "eth0", IP: "10.0.0.1"}}, []string{"ip"})
+	if got, _ := json.Marshal(list); string(got) != `[{"ip":"10.0.0.1"}]` {
+		t.Errorf("Expected arrays to be projected per element, got %s", got)
+	}
+
+	if _, err := projectFields(info, []string{"network..hostname"}); err == nil {
+		t.Error("Expected an error for an empty path segment")
+	}
+}
+
+func TestGetClientForReusesClients(t *testing.T) {
+	defer func() { password = "" }()
+	defer closeClients()
+	password = "testpass"
+
+	first, err := getClientFor("192.168.1.100")
+	if err != nil {
+		t.Fatalf("getClientFor failed: %v", err)
+	}
+	second, _ := getClientFor("192.168.1.100")
+	if first != second {
+		t.Error("Expected the same client for repeated use of a host")
+	}
+
+	other, _ := getClientFor("192.168.1.101")
+	if other == first {
+		t.Error("Expected a separate client for another host")
+	}
+
+	closeClients()
+	if len(clients) != 0 {
+		t.Errorf("Expected closeClients to forget all clients, %d left", len(clients))
+	}
+	if third, _ := getClientFor("192.168.1.100"); third == first {
+		t.Error("Expected a new client after closeClients")
+	}
+}
+
+func TestParseSupervisorLevel(t *testing.T) {
+	tests := []struct {
+		arg      string
+		expected int
+		wantErr  bool
+	}{
+		{"error", 0, false},
+		{"warn", 1, false},
+		{"INFO", 2, false},
+		{" trace ", 3, false},
+		{"0", 0, false},
+		{"3", 3, false},
+		{"4", 0, true},
+		{"-1", 0, true},
+		{"debug", 0, true},
+		{"", 0, true},
+	}
+
+	for _, test := range tests {
+		level, err := parseSupervisorLevel(test.arg)
+		if (err != nil) != test.wantErr {
+			t.Errorf("parseSupervisorLevel(%q) error = %v, wantErr %v", test.arg, err, test.wantErr)
+			continue
+		}
+		if err != nil {
+			var usageErr *usageError
+			if !errors.As(err, &usageErr) {
+				t.Errorf("parseSupervisorLevel(%q) should return a usage error, got %T", test.arg, err)
+			}
+			continue
+		}
+		if level != test.expected {
+			t.Errorf("parseSupervisorLevel(%q) = %d, expected %d", test.arg, level, test.expected)
+		}
+	}
+}
+
+func TestDescribeSupervisorLevel(t *testing.T) {
+	tests := []struct {
+		level    interface{}
+		expected string
+	}{
+		{float64(2), "2 (info)"},
+		{"3", "3 (trace)"},
+		{"warn", "1 (warn)"},
+		{"verbose", "verbose"},
+		{nil, "<nil>"},
+	}
+
+	for _, test := range tests {
+		if got := describeSupervisorLevel(test.level); got != test.expected {
+			t.Errorf("describeSupervisorLevel(%v) = %q, expected %q", test.level, got, test.expected)
+		}
+	}
+}
+
+func TestPrintDisplaySettings(t *testing.T) {
+	var out strings.Builder
+	printDisplaySettings(&out, &brightsign.DisplaySettings{
+		Brightness:     &brightsign.BrightnessSettings{Value: 80, Min: 0, Max: 100},
+		PowerSettings:  &brightsign.PowerSettings{State: "standby"},
+		StandbyTimeout: &brightsign.StandbyTimeoutSettings{Seconds: 300},
+		WhiteBalance:   &brightsign.WhiteBalanceSettings{Red: 128, Green: 120, Blue: 110, Max: 255},
+	})
+
+	expected := "" +
+		"Power: standby\n" +
+		"Brightness: 80 (min: 0, max: 100)\n" +
+		"White Balance: red 128, green 120, blue 110 (min: 0, max: 255)\n" +
+		"Standby Timeout: 300s\n"
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	out.Reset()
+	printDisplaySettings(&out, &brightsign.DisplaySettings{})
+	if out.String() != "No display settings reported\n" {
+		t.Errorf("Expected a message for empty settings, got %q", out.String())
+	}
+}
+
+func TestPageBounds(t *testing.T) {
+	tests := []struct {
+		name                 string
+		total, offset, limit int
+		start, end           int
+	}{
+		{"no paging", 10, 0, 0, 0, 10},
+		{"first page", 10, 0, 3, 0, 3},
+		{"middle page", 10, 3, 3, 3, 6},
+		{"last partial page", 10, 8, 3, 8, 10},
+		{"limit larger than count", 10, 0, 50, 0, 10},
+		{"offset only", 10, 4, 0, 4, 10},
+		{"offset at length", 10, 10, 3, 10, 10},
+		{"offset beyond length", 10, 25, 3, 10, 10},
+		{"empty listing", 0, 0, 5, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end := pageBounds(tt.total, tt.offset, tt.limit)
+			if start != tt.start || end != tt.end {
+				t.Errorf("pageBounds(%d, %d, %d) = %d, %d, want %d, %d",
+					tt.total, tt.offset, tt.limit, start, end, tt.start, tt.end)
+			}
+		})
+	}
+}
+
+func TestPositionalArgValidators(t *testing.T) {
+	dir := t.TempDir()
+	cmd := &cobra.Command{Use: "set"}
+
+	tests := []struct {
+		name     string
+		validate cobra.PositionalArgs
+		args     []string
+		message  string // empty when the arguments are valid
+	}{
+		{"video output", videoOutputArgs(), []string{"hdmi", "0"}, ""},
+		{"video missing device", videoOutputArgs(), []string{"hdmi"}, "expects a connector and a device index, got 1"},
+		{"video unlisted connector", videoOutputArgs(), []string{"dvi", "0"}, ""},
+		{"video malformed connector", videoOutputArgs(), []string{"hdmi/0", "0"}, `invalid connector "hdmi/0"`},
+		{"video empty connector", videoOutputArgs(), []string{"", "0"}, `invalid connector ""`},
+		{"video swapped arguments", videoOutputArgs(), []string{"0", "hdmi"}, `invalid connector "0"`},
+		{"video bad device", videoOutputArgs(), []string{"vga", "first"}, `invalid device index "first"`},
+		{"video negative device", videoOutputArgs(), []string{"vga", "-1"}, `invalid device index "-1"`},
+		{"video mode", videoOutputArgs("a mode"), []string{"hdmi", "0", "1920x1080x60p"}, ""},
+		{"video missing mode", videoOutputArgs("a mode"), []string{"hdmi", "0"}, "expects a connector, a device index and a mode"},
+		{"video empty mode", videoOutputArgs("a mode"), []string{"hdmi", "0", " "}, "mode must not be empty"},
+		{"registry set", registrySetArgs, []string{"networking", "hostname", ""}, ""},
+		{"registry unquoted value", registrySetArgs, []string{"html", "motd", "hello", "world"}, "quote a value that contains spaces"},
+		{"registry missing value", registrySetArgs, []string{"networking", "hostname"}, "expects a section, a key and a value"},
+		{"registry empty section", registrySetArgs, []string{"", "hostname", "x"}, "section must not be empty"},
+		{"registry empty key", registrySetArgs, []string{"networking", " ", "x"}, "key must not be empty"},
+		{"upload", uploadArgs, []string{"video.mp4", "/storage/sd/video.mp4"}, ""},
+		{"upload relative remote", uploadArgs, []string{"video.mp4", "media/"}, ""},
+		{"upload missing remote", uploadArgs, []string{"video.mp4"}, "expects a local file and a remote path"},
+		{"upload directory", uploadArgs, []string{dir, "/storage/sd/"}, "use file sync"},
+		{"upload outside storage", uploadArgs, []string{"video.mp4", "/sd/video.mp4"}, "must start with /storage/"},
+		{"upload empty remote", uploadArgs, []string{"video.mp4", ""}, "remote path must not be empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.validate(cmd, tt.args)
+			if tt.message == "" {
+				if err != nil {
+					t.Errorf("Expected %v to be valid, got %v", tt.args, err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("Expected an error containing %q for %v, got %v", tt.message, tt.args, err)
+			}
+		})
+	}
+}
+
+func TestDiskUsage(t *testing.T) {
+	// /storage/sd: a.mp4 (100), media/ { b.mp4 (200), clips/ { c.mp4 (300), d.mp4 (400) } }, empty/
+	listings := map[string]string{
+		"/api/v1/files/sd/": `[{"name":"a.mp4","type":"file","size":100},{"name":"media","type":"directory"},{"name":"empty","type":"directory"}]`,
+		"/api/v1/files/sd/media/": `[{"name":"b.mp4","type":"file","size":200},{"name":"clips","type":"directory"}]`,
+		"/api/v1/files/sd/media/clips/": `[{"name":"c.mp4","type":"file","size":300},{"name":"d.mp4","type":"file","size":400}]`,
+		"/api/v1/files/sd/empty/": `[]`,
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		listing, ok := listings[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"data":{"result":%s}}`, listing)
+	}))
+	defer server.Close()
+
+	client := brightsign.NewClient(brightsign.Config{Host: server.URL[7:], Password: "pw"})
+
+	tests := []struct {
+		root     string
+		maxDepth int
+		expected []duEntry
+	}{
+		{"/storage/sd/", -1, []duEntry{
+			{"/storage/sd", 1000},
+			{"/storage/sd/media", 900},
+			{"/storage/sd/media/clips", 700},
+			{"/storage/sd/empty", 0},
+		}},
+		{"/storage/sd", 1, []duEntry{
+			{"/storage/sd", 1000},
+			{"/storage/sd/media", 900},
+			{"/storage/sd/empty", 0},
+		}},
+		{"/storage/sd/", 0, []duEntry{{"/storage/sd", 1000}}},
+		{"/storage/sd/media/", -1, []duEntry{
+			{"/storage/sd/media", 900},
+			{"/storage/sd/media/clips", 700},
+		}},
+	}
+
+	for _, tt := range tests {
+		entries, err := diskUsage(client.Storage, tt.root, tt.maxDepth)
+		if err != nil {
+			t.Fatalf("diskUsage(%s, %d) failed: %v", tt.root, tt.maxDepth, err)
+		}
+		if !reflect.DeepEqual(entries, tt.expected) {
+			t.Errorf("diskUsage(%s, %d) = %v, want %v", tt.root, tt.maxDepth, entries, tt.expected)
+		}
+	}
+
+	if _, err := diskUsage(client.Storage, "/storage/usb1/", -1); err == nil {
+		t.Error("Expected an error for a missing directory")
+	}
+}
+
+func TestPrintTimeInfo(t *testing.T) {
+	tests := []struct {
+		name     string
+		info     brightsign.TimeInfo
+		expected string
+	}{
+		{
+			name:     "epoch seconds in a known timezone",
+			info:     brightsign.TimeInfo{Date: float64(1748772000), Timezone: "America/Los_Angeles"},
+			expected: "Date: 2025-06-01\nTime: 03:00:00 PDT\nTimezone: America/Los_Angeles (UTC-07:00)\n",
+		},
+		{
+			name:     "epoch milliseconds without a timezone",
+			info:     brightsign.TimeInfo{Date: float64(1748772000000)},
+			expected: "Date: 2025-06-01\nTime: 10:00:00 UTC\n",
+		},
+		{
+			name:     "string date",
+			info:     brightsign.TimeInfo{Date: "2025-06-01", Time: "10:00:00", Timezone: "PST"},
+			expected: "Date: 2025-06-01\nTime: 10:00:00\nTimezone: PST\n",
+		},
+		{
+			name:     "epoch with a reported time",
+			info:     brightsign.TimeInfo{Date: float64(1748772000), Time: "10:00:00", Timezone: "UTC"},
+			expected: "Date: 2025-06-01\nTime: 10:00:00\nTimezone: UTC (UTC+00:00)\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out strings.Builder
+			printTimeInfo(&out, &tt.info, "")
+			if out.String() != tt.expected {
+				t.Errorf("Expected:\n%s\ngot:\n%s", tt.expected, out.String())
+			}
+		})
+	}
+}
+
+func TestWaitForHealth(t *testing.T) {
+	defer func(interval time.Duration) { healthPollInterval = interval }(healthPollInterval)
+	healthPollInterval = 10 * time.Millisecond
+
+	// Unreachable while booting, then starting, then running
+	var mu sync.Mutex
+	responses := []int{0, 0, 1, 1, 2}
+	statuses := []string{"", "starting", "running"}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		state := responses[0]
+		if len(responses) > 1 {
+			responses = responses[1:]
+		}
+		mu.Unlock()
+
+		if state == 0 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"data":{"result":{"status":"%s","statusTime":"now"}}}`, statuses[state])
+	}))
+	defer server.Close()
+
+	client := brightsign.NewClient(brightsign.Config{Host: server.URL[7:], Password: "pw"})
+
+	health, err := waitForHealth(client, "Running", time.Second)
+	if err != nil {
+		t.Fatalf("waitForHealth failed: %v", err)
+	}
+	if health.Status != "running" {
+		t.Errorf("Expected status running, got %s", health.Status)
+	}
+	mu.Lock()
+	remaining := len(responses)
+	mu.Unlock()
+	if remaining != 1 {
+		t.Errorf("Expected every transition to be polled, %d responses left", remaining)
+	}
+
+	_, err = waitForHealth(client, "idle", 50*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "last: status running") {
+		t.Errorf("Expected a timeout naming the last status, got %v", err)
+	}
+
+	// Rejected credentials fail at once instead of waiting out the timeout
+	forbidden := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer forbidden.Close()
+
+	start := time.Now()
+	_, err = waitForHealth(brightsign.NewClient(brightsign.Config{Host: forbidden.URL[7:], Password: "pw"}), "running", time.Minute)
+	if !errors.Is(err, brightsign.ErrForbidden) || time.Since(start) > 10*time.Second {
+		t.Errorf("Expected ErrForbidden without waiting, got %v after %s", err, time.Since(start))
+	}
+}
+
+func TestPingCSV(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	var buf strings.Builder
+	out := newCSVWriter(&buf, true)
+
+	results := []*brightsign.PingResult{
+		{Success: true, Address: "8.8.8.8", PacketsSent: 4, PacketsRecv: 4, MinTime: 10.5, AvgTime: 12.25, MaxTime: 15},
+		{Success: false, Address: "8.8.8.8", PacketsSent: 4, PacketLoss: 100, ErrorMessage: "host unreachable, no reply"},
+	}
+	for i, result := range results {
+		if err := out.Write(result, at.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatalf("Write failed: %v", err)
+		}
+	}
+
+	expected := "time,address,success,sent,received,loss_pct,min_ms,avg_ms,max_ms,error\n" +
+		"2024-05-01T12:00:00Z,8.8.8.8,true,4,4,0.0,10.50,12.25,15.00,\n" +
+		"2024-05-01T12:01:00Z,8.8.8.8,false,4,0,100.0,0.00,0.00,0.00,\"host unreachable, no reply\"\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, buf.String())
+	}
+
+	buf.Reset()
+	if err := newCSVWriter(&buf, false).Write(results[0], at); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+	if strings.HasPrefix(buf.String(), "time,") {
+		t.Errorf("Expected no header with header disabled, got %q", buf.String())
+	}
+}
+
+func TestTracerouteCSV(t *testing.T) {
+	at := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	result := &brightsign.TraceRouteResult{
+		Success: true,
+		Target:  "example.com",
+		Hops: []brightsign.TraceHop{
+			{Number: 1, Address: "192.168.1.1", Hostname: "gateway", RTT: 0.5},
+			{Number: 2, Address: "10.0.0.1", RTT: 7.125},
+		},
+	}
+
+	var buf strings.Builder
+	if err := newCSVWriter(&buf, true).Write(result, at); err != nil {
+		t.Fatalf("Write failed: %v", err)
+	}
+
+	expected := "time,target,hop,address,hostname,rtt_ms,error\n" +
+		"2024-05-01T12:00:00Z,example.com,1,192.168.1.1,gateway,0.50,\n" +
+		"2024-05-01T12:00:00Z,example.com,2,10.0.0.1,,7.12,\n"
+	if buf.String() != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, buf.String())
+	}
+
+	if _, err := outputFormat("xml"); exitCode(err) != exitUsage {
+		t.Errorf("Expected a usage error for an unknown format, got %v", err)
+	}
+}
+
+func TestRenderNetworkInfo(t *testing.T) {
+	network := brightsign.NetworkInfo{
+		Hostname: "player1",
+		Interfaces: []brightsign.NetworkInterface{
+			{Name: "eth0", Type: "ethernet", Proto: "dhcp", IP: "192.168.1.100", Netmask: "255.255.255.0",
+				Gateway: "192.168.1.1", DNS: "192.168.1.1", MAC: "00:11:22:33:44:55", Metric: 10},
+			{Name: "wlan0", Type: "wifi", Proto: "static", IP: "10.0.0.5", Netmask: "255.0.0.0",
+				MAC: "66:77:88:99:aa:bb", Metric: 20},
+		},
+	}
+
+	var out strings.Builder
+	renderNetworkInfo(&out, network)
+
+	expected := "" +
+		"Hostname: player1\n" +
+		"\n" +
+		"INTERFACE  TYPE      PROTO   IP             NETMASK        GATEWAY      DNS          MAC                METRIC\n" +
+		"eth0       ethernet  dhcp    192.168.1.100  255.255.255.0  192.168.1.1  192.168.1.1  00:11:22:33:44:55  10\n" +
+		"wlan0      wifi      static  10.0.0.5       255.0.0.0      -            -            66:77:88:99:aa:bb  20\n"
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	out.Reset()
+	renderNetworkInfo(&out, brightsign.NetworkInfo{})
+	if out.String() != "Hostname: -\nNo network interfaces reported\n" {
+		t.Errorf("Unexpected output for no interfaces: %q", out.String())
+	}
+}
+
+func TestGetClientForPromptsAgainOnRejectedPassword(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, pass, ok := r.BasicAuth(); !ok || pass != "right" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="BrightSign"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{"status":"running"}}}`))
+	}))
+	defer server.Close()
+
+	defer func(r func(string) (string, error)) { passwordReader = r }(passwordReader)
+	defer func(f func() bool) { stdinIsTerminal = f }(stdinIsTerminal)
+	defer func() { password, allowBasic, noAuthCache = "", false, false }()
+	defer closeClients()
+	password, allowBasic, noAuthCache = "wrong", true, true
+
+	var prompts []string
+	passwordReader = func(prompt string) (string, error) {
+		prompts = append(prompts, prompt)
+		return "right", nil
+	}
+
+	// Without a terminal the rejection is final
+	stdinIsTerminal = func() bool { return false }
+	client, err := getClientFor(server.URL[7:])
+	if err != nil {
+		t.Fatalf("getClientFor failed: %v", err)
+	}
+	if _, err := client.Info.GetHealth(); !errors.Is(err, brightsign.ErrUnauthorized) {
+		t.Errorf("Expected ErrUnauthorized without a terminal, got %v", err)
+	}
+	if len(prompts) != 0 {
+		t.Errorf("Expected no prompt without a terminal, got %q", prompts)
+	}
+
+	closeClients()
+	stdinIsTerminal = func() bool { return true }
+	client, _ = getClientFor(server.URL[7:])
+	if _, err := client.Info.GetHealth(); err != nil {
+		t.Fatalf("Expected the prompted password to be accepted, got %v", err)
+	}
+	if len(prompts) != 1 || !strings.HasPrefix(prompts[0], "Authentication failed. Password for admin@") {
+		t.Errorf("Expected one re-prompt, got %q", prompts)
+	}
+}
+
+func TestAuthCacheDir(t *testing.T) {
+	defer func(v bool) { noAuthCache = v }(noAuthCache)
+	noAuthCache = false
+
+	// The cache directory follows the same BSCLI_ then BSCLI_TEST_ lookup
+	t.Setenv("BSCLI_CACHE_DIR", "")
+	t.Setenv("BSCLI_TEST_CACHE_DIR", "/tmp/bscli-test-cache")
+	if got := authCacheDir(); got != "/tmp/bscli-test-cache" {
+		t.Errorf("Expected the BSCLI_TEST_ fallback, got %q", got)
+	}
+
+	t.Setenv("BSCLI_CACHE_DIR", "/tmp/bscli-cache")
+	if got := authCacheDir(); got != "/tmp/bscli-cache" {
+		t.Errorf("Expected BSCLI_CACHE_DIR, got %q", got)
+	}
+
+	noAuthCache = true
+	if got := authCacheDir(); got != "" {
+		t.Errorf("Expected no cache directory with --no-auth-cache, got %q", got)
+	}
+}
+
+func TestPrintNeighborhood(t *testing.T) {
+	neighborhood := map[string]interface{}{
+		"lobby":   map[string]interface{}{"model": "XT1144", "ip": "10.0.0.7", "tags": []interface{}{"a", "b"}},
+		"kiosk 2": map[string]interface{}{"ip": "10.0.0.9\n", "serial": nil},
+	}
+
+	var out strings.Builder
+	printNeighborhood(&out, neighborhood)
+
+	expected := "" +
+		"NAME     DETAILS\n" +
+		"kiosk 2  ip=10.0.0.9 serial=-\n" +
+		"lobby    ip=10.0.0.7 model=XT1144 tags=a,b\n"
+	if out.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, out.String())
+	}
 }
\ No newline at end of file
@@ -0,0 +1,56 @@
+package cli
+
+import (
+	"os"
+
+	"golang.org/x/term"
+)
+
+// ANSI escape sequences used for human-readable output
+const (
+	ansiReset = "\033[0m"
+	ansiBold  = "\033[1m"
+	ansiRed   = "\033[31m"
+	ansiGreen = "\033[32m"
+	ansiBlue  = "\033[34m"
+)
+
+// noColor disables colored output, set by --no-color
+var noColor bool
+
+// stdoutIsTerminal reports whether stdout is a terminal. Tests replace it.
+var stdoutIsTerminal = func() bool {
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// colorEnabled reports whether human output may contain color: only on a
+// terminal, outside JSON mode, and unless --no-color or NO_COLOR is set
+func colorEnabled() bool {
+	if noColor || jsonOutput {
+		return false
+	}
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	return stdoutIsTerminal()
+}
+
+// colorize wraps s in an ANSI sequence when color is enabled
+func colorize(code, s string) string {
+	if !colorEnabled() {
+		return s
+	}
+	return code + s + ansiReset
+}
+
+// green marks healthy or passing results
+func green(s string) string { return colorize(ansiGreen, s) }
+
+// red marks failures
+func red(s string) string { return colorize(ansiRed, s) }
+
+// blue marks directories in listings
+func blue(s string) string { return colorize(ansiBlue, s) }
+
+// bold marks headers
+func bold(s string) string { return colorize(ansiBold, s) }
@@ -0,0 +1,126 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"syscall"
+
+	"golang.org/x/term"
+)
+
+// promptWriter receives prompts. It stays the original stdout when
+// --output-file redirects command output, so prompts still reach the user.
+var promptWriter io.Writer = os.Stdout
+
+// confirmReader is where confirmation prompts read their answers from.
+// Tests replace it to feed canned input.
+var confirmReader io.Reader = os.Stdin
+
+// passwordReader prompts for a password and reads it without echo.
+// Tests replace it to feed canned input.
+var passwordReader = func(prompt string) (string, error) {
+	fmt.Fprint(promptWriter, prompt)
+	bytePassword, err := term.ReadPassword(int(syscall.Stdin))
+	fmt.Fprintln(promptWriter)
+	if err != nil {
+		return "", fmt.Errorf("failed to read password: %w", err)
+	}
+	return string(bytePassword), nil
+}
+
+// stdinIsTerminal reports whether stdin is a terminal, so a rejected password
+// can be asked for again. Tests replace it.
+var stdinIsTerminal = func() bool {
+	return term.IsTerminal(int(syscall.Stdin))
+}
+
+// assumeYes answers every confirmation affirmatively without reading input
+var assumeYes bool
+
+// readResponse reads a single line from confirmReader without buffering past
+// the newline, so consecutive prompts each get their own line
+func readResponse() string {
+	var line []byte
+	buf := make([]byte, 1)
+	for {
+		n, err := confirmReader.Read(buf)
+		if n > 0 {
+			if buf[0] == '\n' {
+				break
+			}
+			line = append(line, buf[0])
+		}
+		if err != nil {
+			break
+		}
+	}
+	return strings.TrimSpace(string(line))
+}
+
+// confirm asks a yes/no question and reports whether the user answered yes
+func confirm(prompt string) bool {
+	if assumeYes {
+		return true
+	}
+
+	fmt.Fprintf(promptWriter, "%s (y/N): ", prompt)
+	response := readResponse()
+	if response != "y" && response != "Y" {
+		fmt.Fprintln(promptWriter, "Cancelled")
+		return false
+	}
+	return true
+}
+
+// confirmDestructive asks the user to type expected verbatim before an
+// irreversible operation. A bare "y" is deliberately not enough.
+func confirmDestructive(prompt, expected string) bool {
+	if assumeYes {
+		return true
+	}
+
+	fmt.Fprintf(promptWriter, "%s\nType %q to continue: ", prompt, expected)
+	if readResponse() != expected {
+		fmt.Fprintln(promptWriter, "Cancelled")
+		return false
+	}
+	return true
+}
+
+// readNewPassword prompts for a new password twice and returns it only if
+// both entries match
+func readNewPassword() (string, error) {
+	first, err := passwordReader("New password: ")
+	if err != nil {
+		return "", err
+	}
+	if first == "" {
+		return "", &usageError{err: fmt.Errorf("password must not be empty")}
+	}
+
+	second, err := passwordReader("Confirm new password: ")
+	if err != nil {
+		return "", err
+	}
+	if first != second {
+		return "", &usageError{err: fmt.Errorf("passwords do not match")}
+	}
+	return first, nil
+}
+
+// readPasswordLine reads a password from the first line of r, as used by
+// --password-stdin. Only the line ending is stripped.
+func readPasswordLine(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", fmt.Errorf("failed to read password from stdin: %w", err)
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return "", &usageError{err: fmt.Errorf("no password on stdin")}
+	}
+	return line, nil
+}
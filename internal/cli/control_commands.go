@@ -2,7 +2,12 @@ package cli
 
 import (
 	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
 	"strings"
+	"syscall"
+	"time"
 
 	"bscli/pkg/brightsign"
 	"github.com/spf13/cobra"
@@ -25,12 +30,9 @@ func addControlCommands() {
 			disableAutorun, _ := cmd.Flags().GetBool("disable-autorun")
 
 			// Confirm dangerous operations
-			if factoryReset {
-				fmt.Print("WARNING: Factory reset will erase all settings. Continue? (y/N): ")
-				var response string
-				fmt.Scanln(&response)
-				if response != "y" && response != "Y" {
-					fmt.Println("Cancelled")
+			force, _ := cmd.Flags().GetBool("force")
+			if factoryReset && !force {
+				if !confirmDestructive("WARNING: Factory reset will erase all settings.", "FACTORY-RESET") {
 					return
 				}
 			}
@@ -51,12 +53,17 @@ func addControlCommands() {
 				handleError(err)
 			}
 
-			fmt.Println("Reboot initiated")
+			reportSuccess("reboot", "Reboot initiated", map[string]interface{}{
+				"crashReport":    crashReport,
+				"factoryReset":   factoryReset,
+				"disableAutorun": disableAutorun,
+			})
 		},
 	}
 	rebootCmd.Flags().Bool("crash-report", false, "Generate crash report")
 	rebootCmd.Flags().Bool("factory-reset", false, "Perform factory reset")
 	rebootCmd.Flags().Bool("disable-autorun", false, "Disable autorun after reboot")
+	rebootCmd.Flags().BoolP("force", "f", false, "Skip factory reset confirmation")
 
 	// Snapshot command
 	snapshotCmd := &cobra.Command{
@@ -66,16 +73,23 @@ func addControlCommands() {
 			width, _ := cmd.Flags().GetInt("width")
 			height, _ := cmd.Flags().GetInt("height")
 			fullRes, _ := cmd.Flags().GetBool("full-resolution")
-
-			client, err := getClient()
-			if err != nil {
-				handleError(err)
-			}
+			format, _ := cmd.Flags().GetString("format")
+			quality, _ := cmd.Flags().GetInt("quality")
 
 			options := &brightsign.SnapshotOptions{
 				Width:                      width,
 				Height:                     height,
 				ShouldCaptureFullResolution: fullRes,
+				Format:                     strings.ToLower(format),
+				Quality:                    quality,
+			}
+			if err := options.Validate(); err != nil {
+				handleError(&usageError{err: err})
+			}
+
+			client, err := getClient()
+			if err != nil {
+				handleError(err)
 			}
 
 			filename, err := client.Control.TakeSnapshot(options)
@@ -83,12 +97,14 @@ func addControlCommands() {
 				handleError(err)
 			}
 
-			fmt.Printf("Snapshot saved: %s\n", filename)
+			reportSuccess("snapshot", "Snapshot saved: "+filename, map[string]interface{}{"file": filename})
 		},
 	}
 	snapshotCmd.Flags().Int("width", 0, "Width of snapshot")
 	snapshotCmd.Flags().Int("height", 0, "Height of snapshot")
 	snapshotCmd.Flags().Bool("full-resolution", false, "Capture at full resolution")
+	snapshotCmd.Flags().String("format", "", "Image format: jpeg or png (default: player default)")
+	snapshotCmd.Flags().Int("quality", 0, "JPEG quality 1-100 (default: player default)")
 
 	// DWS password commands
 	dwsPasswordCmd := &cobra.Command{
@@ -126,9 +142,18 @@ func addControlCommands() {
 	dwsPasswordSetCmd := &cobra.Command{
 		Use:   "set [password]",
 		Short: "Set DWS password",
-		Args:  cobra.MaximumNArgs(1),
+		Long: `Set the DWS password. Without a password argument the new password is
+prompted for twice, without echo. Use --password-stdin to read it from the
+first line of stdin in scripts. Passing it as an argument still works but
+leaves it in shell history and process lists.`,
+		Args: cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			reset, _ := cmd.Flags().GetBool("reset")
+			fromStdin, _ := cmd.Flags().GetBool("password-stdin")
+
+			if fromStdin && len(args) > 0 {
+				handleError(&usageError{err: fmt.Errorf("--password-stdin cannot be combined with a password argument")})
+			}
 
 			client, err := getClient()
 			if err != nil {
@@ -139,8 +164,19 @@ func addControlCommands() {
 				Reset: reset,
 			}
 
-			if !reset && len(args) > 0 {
-				config.Password = args[0]
+			if !reset {
+				switch {
+				case len(args) > 0:
+					fmt.Fprintln(os.Stderr, "Warning: a password given as an argument is visible in shell history and process lists; omit it to be prompted or use --password-stdin")
+					config.Password = args[0]
+				case fromStdin:
+					config.Password, err = readPasswordLine(os.Stdin)
+				default:
+					config.Password, err = readNewPassword()
+				}
+				if err != nil {
+					handleError(err)
+				}
 			}
 
 			err = client.Control.SetDWSPassword(config)
@@ -148,14 +184,15 @@ func addControlCommands() {
 				handleError(err)
 			}
 
+			message := "DWS password set"
 			if reset {
-				fmt.Println("DWS password reset to default")
-			} else {
-				fmt.Println("DWS password set")
+				message = "DWS password reset to default"
 			}
+			reportSuccess("dws-password", message, map[string]interface{}{"reset": reset})
 		},
 	}
 	dwsPasswordSetCmd.Flags().Bool("reset", false, "Reset password to default")
+	dwsPasswordSetCmd.Flags().Bool("password-stdin", false, "Read the new password from stdin")
 
 	dwsPasswordCmd.AddCommand(dwsPasswordGetCmd, dwsPasswordSetCmd)
 
@@ -206,7 +243,7 @@ func addControlCommands() {
 				handleError(err)
 			}
 
-			fmt.Println("Local DWS enabled")
+			reportSuccess("local-dws", "Local DWS enabled", map[string]interface{}{"enabled": true})
 		},
 	}
 
@@ -224,7 +261,7 @@ func addControlCommands() {
 				handleError(err)
 			}
 
-			fmt.Println("Local DWS disabled")
+			reportSuccess("local-dws", "Local DWS disabled", map[string]interface{}{"enabled": false})
 		},
 	}
 
@@ -243,12 +280,8 @@ func addControlCommands() {
 				handleError(fmt.Errorf("invalid URL: must start with http:// or https://"))
 			}
 
-			fmt.Printf("WARNING: This will download and install firmware from %s\n", url)
-			fmt.Print("The player will reboot automatically. Continue? (y/N): ")
-			var response string
-			fmt.Scanln(&response)
-			if response != "y" && response != "Y" {
-				fmt.Println("Cancelled")
+			fmt.Fprintf(os.Stderr, "WARNING: This will download and install firmware from %s\n", url)
+			if !confirm("The player will reboot automatically. Continue?") {
 				return
 			}
 
@@ -262,10 +295,209 @@ func addControlCommands() {
 				handleError(err)
 			}
 
-			fmt.Println("Firmware download initiated, player will reboot")
+			reportSuccess("download-firmware", "Firmware download initiated, player will reboot", map[string]interface{}{"url": url})
+		},
+	}
+
+	// Update firmware from a local file
+	updateFirmwareCmd := &cobra.Command{
+		Use:   "update-firmware [local-file]",
+		Short: "Upload a local .bsfw firmware file and install it",
+		Long: `Upload a .bsfw firmware file to the root of a storage device and reboot the
+player so the OS installs it. Use --wait to block until the player is back.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			localPath := args[0]
+			device, _ := cmd.Flags().GetString("device")
+			wait, _ := cmd.Flags().GetBool("wait")
+			waitTimeout, _ := cmd.Flags().GetDuration("wait-timeout")
+
+			size, err := validateFirmwareFile(localPath)
+			if err != nil {
+				handleError(err)
+			}
+
+			remotePath := fmt.Sprintf("/storage/%s/%s", device, filepath.Base(localPath))
+
+			fmt.Fprintf(os.Stderr, "WARNING: This will install firmware from %s\n", localPath)
+			if !confirm("The player will reboot automatically. Continue?") {
+				return
+			}
+
+			client, err := getClient()
+			if err != nil {
+				handleError(err)
+			}
+
+			infof("Uploading %s (%s) to %s...", localPath, formatSize(size), remotePath)
+			if err := client.Storage.UploadFile(localPath, remotePath); err != nil {
+				handleError(err)
+			}
+
+			infof("Upload complete, rebooting player to install firmware...")
+			if err := client.Control.InstallFirmwareFile(remotePath); err != nil {
+				handleError(err)
+			}
+
+			fields := map[string]interface{}{"source": localPath, "remote": remotePath}
+			if !wait {
+				reportSuccess("update-firmware", "Firmware install initiated, player will reboot", fields)
+				return
+			}
+
+			infof("Waiting for player to come back online...")
+			if err := waitForReboot(client, waitTimeout); err != nil {
+				handleError(err)
+			}
+			fields["online"] = true
+			reportSuccess("update-firmware", "Player is back online", fields)
+		},
+	}
+	updateFirmwareCmd.Flags().String("device", "sd", "Storage device to upload the firmware to")
+	updateFirmwareCmd.Flags().Bool("wait", false, "Wait for the player to reboot and respond again")
+	updateFirmwareCmd.Flags().Duration("wait-timeout", 15*time.Minute, "Maximum time to wait with --wait")
+
+	// Display power shortcuts
+	displayOnCmd := &cobra.Command{
+		Use:   "display-on",
+		Short: "Turn the connected display on (same as display power on)",
+		Long: `Wake the connected display. Only the screen is affected; the player itself
+keeps running either way. Use control reboot to restart the player.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			setDisplayPower("on")
+		},
+	}
+
+	displayOffCmd := &cobra.Command{
+		Use:   "display-off",
+		Short: "Put the connected display in standby (same as display power standby)",
+		Long: `Put the connected display to sleep. The player keeps running and playing
+content; only the screen goes dark. Use control display-on to wake it.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			setDisplayPower("standby")
+		},
+	}
+
+	identifyCmd := &cobra.Command{
+		Use:   "identify",
+		Short: "Blink the connected display to find this player",
+		Long: `Blink the connected display for --duration seconds so a player can be picked
+out in a rack. The DWS offers no LED or tone control, so the display is
+switched between standby and on once a second and then returned to the
+power state it had before. Needs display control (BOS 9.0.189+); on older
+firmware, control snapshot shows what a player is displaying instead.`,
+		Example: `  bscli 192.168.1.100 control identify
+  bscli 192.168.1.100 control identify --duration 30`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			duration, _ := cmd.Flags().GetInt("duration")
+			if duration <= 0 {
+				handleError(&usageError{err: fmt.Errorf("--duration must be positive")})
+			}
+
+			client, err := getDisplayClient()
+			if err != nil {
+				handleError(err)
+			}
+
+			// Ctrl-C or SIGTERM stops the blinking, restoring the display
+			// rather than leaving it in standby
+			stop, release := stopOnSignal()
+			defer release()
+
+			infof("Blinking the display of %s for %ds...", host, duration)
+			if err := client.Control.IdentifyUntil(duration, stop); err != nil {
+				handleError(err)
+			}
+			select {
+			case <-stop:
+				handleError(fmt.Errorf("identify interrupted; the display power state was restored"))
+			default:
+			}
+
+			reportSuccess("identify", "Done identifying "+host, map[string]interface{}{"duration": duration})
 		},
 	}
+	identifyCmd.Flags().Int("duration", 10, "Seconds to blink the display for")
 
-	controlCmd.AddCommand(rebootCmd, snapshotCmd, dwsPasswordCmd, localDWSCmd, downloadFirmwareCmd)
+	controlCmd.AddCommand(rebootCmd, snapshotCmd, displayOnCmd, displayOffCmd, identifyCmd, dwsPasswordCmd, localDWSCmd, downloadFirmwareCmd, updateFirmwareCmd)
 	rootCmd.AddCommand(controlCmd)
+}
+
+// stopOnSignal returns a channel that is closed on SIGINT or SIGTERM, and a
+// release function that stops watching for them
+func stopOnSignal() (<-chan struct{}, func()) {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case <-signals:
+			close(stop)
+		case <-done:
+		}
+	}()
+
+	return stop, func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+// maxFirmwareSize is a sanity limit for firmware images
+const maxFirmwareSize = 2 << 30
+
+// validateFirmwareFile checks that a local firmware file looks installable
+// and returns its size
+func validateFirmwareFile(path string) (int64, error) {
+	if !strings.EqualFold(filepath.Ext(path), ".bsfw") {
+		return 0, fmt.Errorf("firmware file must have a .bsfw extension: %s", path)
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read firmware file: %w", err)
+	}
+
+	if info.IsDir() {
+		return 0, fmt.Errorf("firmware path is a directory: %s", path)
+	}
+
+	if info.Size() == 0 {
+		return 0, fmt.Errorf("firmware file is empty: %s", path)
+	}
+
+	if info.Size() > maxFirmwareSize {
+		return 0, fmt.Errorf("firmware file is too large (%s): %s", formatSize(info.Size()), path)
+	}
+
+	return info.Size(), nil
+}
+
+// healthPollInterval is how often a rebooting or starting player is checked
+var healthPollInterval = 5 * time.Second
+
+// waitForReboot waits for the player to go offline and answer health checks again
+func waitForReboot(client *brightsign.Client, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	wentDown := false
+
+	for time.Now().Before(deadline) {
+		time.Sleep(healthPollInterval)
+
+		_, err := client.Info.GetHealth()
+		if err != nil {
+			wentDown = true
+			continue
+		}
+		if wentDown {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("player did not come back within %s", timeout)
 }
\ No newline at end of file
@@ -0,0 +1,129 @@
+package cli
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"bscli/pkg/brightsign"
+)
+
+// Output formats accepted by -o on commands that support CSV
+const (
+	formatText = "text"
+	formatCSV  = "csv"
+)
+
+// outputFormat validates the value of -o, rejecting anything but text and
+// csv as a usage error
+func outputFormat(format string) (string, error) {
+	switch format {
+	case "", formatText:
+		return formatText, nil
+	case formatCSV:
+		return formatCSV, nil
+	}
+	return "", &usageError{err: fmt.Errorf("invalid output format %q (use text or csv)", format)}
+}
+
+// Column headers of the CSV renderings, one per result type
+var (
+	pingCSVHeader       = []string{"time", "address", "success", "sent", "received", "loss_pct", "min_ms", "avg_ms", "max_ms", "error"}
+	tracerouteCSVHeader = []string{"time", "target", "hop", "address", "hostname", "rtt_ms", "error"}
+)
+
+// csvHeader returns the column names for results of the same type as v
+func csvHeader(v interface{}) ([]string, error) {
+	switch v.(type) {
+	case *brightsign.PingResult:
+		return pingCSVHeader, nil
+	case *brightsign.TraceRouteResult:
+		return tracerouteCSVHeader, nil
+	}
+	return nil, fmt.Errorf("no CSV format for %T", v)
+}
+
+// csvRows renders a result as CSV rows stamped with at. A traceroute gives
+// one row per hop, or a single row carrying the error when it failed.
+func csvRows(v interface{}, at time.Time) ([][]string, error) {
+	stamp := at.Format(time.RFC3339)
+
+	switch r := v.(type) {
+	case *brightsign.PingResult:
+		return [][]string{{
+			stamp,
+			r.Address,
+			strconv.FormatBool(r.Success),
+			strconv.Itoa(r.PacketsSent),
+			strconv.Itoa(r.PacketsRecv),
+			formatFloat(r.PacketLoss, 1),
+			formatFloat(r.MinTime, 2),
+			formatFloat(r.AvgTime, 2),
+			formatFloat(r.MaxTime, 2),
+			r.ErrorMessage,
+		}}, nil
+
+	case *brightsign.TraceRouteResult:
+		if !r.Success || len(r.Hops) == 0 {
+			return [][]string{{stamp, r.Target, "", "", "", "", r.Error}}, nil
+		}
+		rows := make([][]string, 0, len(r.Hops))
+		for _, hop := range r.Hops {
+			rows = append(rows, []string{
+				stamp,
+				r.Target,
+				strconv.Itoa(hop.Number),
+				hop.Address,
+				hop.Hostname,
+				formatFloat(hop.RTT, 2),
+				"",
+			})
+		}
+		return rows, nil
+	}
+	return nil, fmt.Errorf("no CSV format for %T", v)
+}
+
+// formatFloat formats f with prec decimals
+func formatFloat(f float64, prec int) string {
+	return strconv.FormatFloat(f, 'f', prec, 64)
+}
+
+// csvWriter writes results as CSV, emitting the header before the first row
+// unless it is suppressed
+type csvWriter struct {
+	w          *csv.Writer
+	headerDone bool
+}
+
+// newCSVWriter returns a csvWriter on out. With header false no header row
+// is written, for appending to an existing log.
+func newCSVWriter(out io.Writer, header bool) *csvWriter {
+	return &csvWriter{w: csv.NewWriter(out), headerDone: !header}
+}
+
+// Write renders result and flushes it, so each row reaches a log file as
+// soon as it is available
+func (c *csvWriter) Write(result interface{}, at time.Time) error {
+	if !c.headerDone {
+		header, err := csvHeader(result)
+		if err != nil {
+			return err
+		}
+		if err := c.w.Write(header); err != nil {
+			return err
+		}
+		c.headerDone = true
+	}
+
+	rows, err := csvRows(result, at)
+	if err != nil {
+		return err
+	}
+	if err := c.w.WriteAll(rows); err != nil {
+		return err
+	}
+	return c.w.Error()
+}
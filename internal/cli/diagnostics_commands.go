@@ -1,8 +1,15 @@
 package cli
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"bscli/pkg/brightsign"
 	"github.com/spf13/cobra"
@@ -20,94 +27,150 @@ func addDiagnosticsCommands() {
 	runDiagCmd := &cobra.Command{
 		Use:   "run",
 		Short: "Run all network diagnostics",
+		Long: `Run the player's network diagnostics and show the results.
+
+The player always runs every test; --only and --skip select which results are
+shown and checked. With --fail-on-error the command exits with status 1 if any
+selected test failed, for use in health checks.`,
 		Run: func(cmd *cobra.Command, args []string) {
+			only, _ := cmd.Flags().GetStringSlice("only")
+			skip, _ := cmd.Flags().GetStringSlice("skip")
+			failOnError, _ := cmd.Flags().GetBool("fail-on-error")
+
 			client, err := getClient()
 			if err != nil {
 				handleError(err)
 			}
 
-			results, err := client.Diagnostics.RunDiagnostics()
+			report, err := client.Diagnostics.RunDiagnostics()
 			if err != nil {
 				handleError(err)
 			}
 
-			if jsonOutput {
-				outputJSON(results)
-				return
+			if report.Results == nil && (len(only) > 0 || len(skip) > 0 || failOnError) {
+				handleError(fmt.Errorf("the player's diagnostics response has no test results to select or check"))
 			}
-
-			fmt.Println("Diagnostic Results:")
-			// Handle different possible result formats
-			switch resultData := results.(type) {
-			case []interface{}:
-				for _, r := range resultData {
-					if resultMap, ok := r.(map[string]interface{}); ok {
-						status := "✓"
-						if statusVal, ok := resultMap["status"]; ok && statusVal != "pass" {
-							status = "✗"
-						}
-						test := ""
-						if testVal, ok := resultMap["test"]; ok {
-							test = fmt.Sprintf("%v", testVal)
-						}
-						message := ""
-						if msgVal, ok := resultMap["message"]; ok {
-							message = fmt.Sprintf("%v", msgVal)
-						}
-						fmt.Printf("%s %s: %s\n", status, test, message)
-					}
-				}
-			case map[string]interface{}:
-				// Single diagnostic result object
-				status := "✓"
-				if statusVal, ok := resultData["status"]; ok && statusVal != "pass" {
-					status = "✗"
+			if report.Results != nil {
+				if err := checkDiagnosticNames(report.Results, append(only, skip...)); err != nil {
+					handleError(&usageError{err: err})
 				}
-				test := ""
-				if testVal, ok := resultData["test"]; ok {
-					test = fmt.Sprintf("%v", testVal)
+				report.Results = brightsign.FilterDiagnostics(report.Results, only, skip)
+			}
+
+			failed := 0
+			for _, result := range report.Results {
+				if !result.Passed() {
+					failed++
 				}
-				message := ""
-				if msgVal, ok := resultData["message"]; ok {
-					message = fmt.Sprintf("%v", msgVal)
+			}
+			if failOnError && failed > 0 {
+				// Report after the results are printed
+				defer handleError(fmt.Errorf("%d of %d diagnostic tests failed", failed, len(report.Results)))
+			}
+
+			if jsonOutput {
+				if report.Results != nil {
+					outputJSON(report.Results)
+				} else {
+					outputJSON(report.Raw)
 				}
-				fmt.Printf("%s %s: %s\n", status, test, message)
-			default:
-				fmt.Printf("%v\n", results)
+				return
+			}
+
+			fmt.Println(bold("Diagnostic Results:"))
+			if report.Results == nil {
+				// Unknown response shape, print it as returned
+				data, _ := json.MarshalIndent(report.Raw, "", "  ")
+				fmt.Println(string(data))
+				return
 			}
+			renderDiagnostics(os.Stdout, report.Results)
 		},
 	}
 
 	// Ping command
 	pingCmd := &cobra.Command{
-		Use:   "ping [ip-address]",
-		Short: "Ping an IP address",
-		Args:  cobra.ExactArgs(1),
+		Use:   "ping [address]",
+		Short: "Ping an IP address (IPv4 or IPv6) or hostname",
+		Long: `Ping an address from the player.
+
+--count repeats the ping, waiting --interval between runs; 0 repeats until
+interrupted. With -o csv each run is printed as a CSV row, after a header
+unless --no-header is given, for appending to a monitoring log. A run that
+fails is recorded, in the error column for CSV, and the pings continue.`,
+		Example: `  bscli player1 diagnostics ping 8.8.8.8
+  bscli player1 diagnostics ping 8.8.8.8 --count 0 --interval 1m -o csv >> ping.csv`,
+		Args: cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			client, err := getClient()
+			count, _ := cmd.Flags().GetInt("count")
+			interval, _ := cmd.Flags().GetDuration("interval")
+			noHeader, _ := cmd.Flags().GetBool("no-header")
+			formatFlag, _ := cmd.Flags().GetString("output")
+
+			if err := brightsign.ValidateTarget(args[0]); err != nil {
+				handleError(&usageError{err: err})
+			}
+			format, err := outputFormat(formatFlag)
 			if err != nil {
 				handleError(err)
 			}
+			switch {
+			case count < 0:
+				handleError(&usageError{err: fmt.Errorf("--count must not be negative")})
+			case format == formatCSV && jsonOutput:
+				handleError(&usageError{err: fmt.Errorf("-o csv cannot be combined with --json")})
+			case count == 0 && jsonOutput:
+				handleError(&usageError{err: fmt.Errorf("--count 0 cannot be combined with --json")})
+			}
 
-			result, err := client.Diagnostics.Ping(args[0])
+			client, err := getClient()
 			if err != nil {
 				handleError(err)
 			}
 
-			if jsonOutput {
-				outputJSON(result)
-				return
+			csvOut := newCSVWriter(os.Stdout, !noHeader)
+			var results []*brightsign.PingResult
+			for i := 0; count == 0 || i < count; i++ {
+				if i > 0 {
+					time.Sleep(interval)
+				}
+
+				result, err := client.Diagnostics.Ping(args[0])
+				if err != nil {
+					// A single text ping reports the error; CSV and repeated
+					// pings record it as a failed run and carry on, unless
+					// the player rejected the credentials
+					if (count == 1 && format != formatCSV) || exitCode(err) == exitAuth {
+						handleError(err)
+					}
+					result = &brightsign.PingResult{Address: args[0], ErrorMessage: err.Error()}
+				}
+
+				switch {
+				case jsonOutput:
+					results = append(results, result)
+				case format == formatCSV:
+					if err := csvOut.Write(result, time.Now()); err != nil {
+						handleError(err)
+					}
+				default:
+					printPingResult(result)
+				}
 			}
 
-			if result.Success {
-				fmt.Printf("PING %s: %d/%d packets received\n", result.Address, result.PacketsRecv, result.PacketsSent)
-				fmt.Printf("Packet Loss: %.1f%%\n", result.PacketLoss)
-				fmt.Printf("RTT min/avg/max = %.2f/%.2f/%.2f ms\n", result.MinTime, result.AvgTime, result.MaxTime)
-			} else {
-				fmt.Printf("PING %s failed: %s\n", result.Address, result.ErrorMessage)
+			if jsonOutput {
+				if count == 1 {
+					outputJSON(results[0])
+				} else {
+					outputJSON(results)
+				}
 			}
 		},
 	}
+	pingCmd.Flags().Int("count", 1, "Number of times to ping (0 = until interrupted)")
+	pingCmd.Flags().Duration("interval", time.Second, "Time between pings with --count")
+	pingCmd.Flags().StringP("output", "o", formatText, "Output format: text or csv")
+	pingCmd.Flags().Bool("no-header", false, "Omit the CSV header row")
 
 	// DNS lookup command
 	dnsCmd := &cobra.Command{
@@ -116,13 +179,25 @@ func addDiagnosticsCommands() {
 		Args:  cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			resolveAddr, _ := cmd.Flags().GetBool("resolve")
+			recordType, _ := cmd.Flags().GetString("type")
+			ipv6, _ := cmd.Flags().GetBool("ipv6")
+
+			if err := brightsign.ValidateTarget(args[0]); err != nil {
+				handleError(&usageError{err: err})
+			}
+			if ipv6 {
+				if recordType != "" && !strings.EqualFold(recordType, "AAAA") {
+					handleError(&usageError{err: fmt.Errorf("--ipv6 cannot be combined with --type %s", recordType)})
+				}
+				recordType = "AAAA"
+			}
 
 			client, err := getClient()
 			if err != nil {
 				handleError(err)
 			}
 
-			result, err := client.Diagnostics.DNSLookup(args[0], resolveAddr)
+			result, err := client.Diagnostics.DNSLookupWithType(args[0], recordType, resolveAddr)
 			if err != nil {
 				handleError(err)
 			}
@@ -134,8 +209,17 @@ func addDiagnosticsCommands() {
 
 			if result.Success {
 				fmt.Printf("DNS lookup for %s:\n", result.Hostname)
-				for _, addr := range result.Addresses {
-					fmt.Printf("  %s\n", addr)
+				if recordType == "" {
+					for _, addr := range result.Addresses {
+						fmt.Printf("  %s\n", addr)
+					}
+				}
+				for _, record := range result.Records {
+					if record.Priority != 0 {
+						fmt.Printf("  %-5s %d %s\n", record.Type, record.Priority, record.Value)
+					} else {
+						fmt.Printf("  %-5s %s\n", record.Type, record.Value)
+					}
 				}
 			} else {
 				fmt.Printf("DNS lookup failed: %s\n", result.Error)
@@ -143,6 +227,8 @@ func addDiagnosticsCommands() {
 		},
 	}
 	dnsCmd.Flags().Bool("resolve", false, "Resolve addresses")
+	dnsCmd.Flags().String("type", "", "Record type to query (A, AAAA, CNAME, MX, TXT)")
+	dnsCmd.Flags().Bool("ipv6", false, "Look up IPv6 addresses (same as --type AAAA)")
 
 	// Traceroute command
 	tracerouteCmd := &cobra.Command{
@@ -151,17 +237,49 @@ func addDiagnosticsCommands() {
 		Args:  cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			resolveAddr, _ := cmd.Flags().GetBool("resolve")
+			maxHops, _ := cmd.Flags().GetInt("max-hops")
+			timeout, _ := cmd.Flags().GetInt("timeout")
+			noHeader, _ := cmd.Flags().GetBool("no-header")
+			formatFlag, _ := cmd.Flags().GetString("output")
+
+			if err := brightsign.ValidateTarget(args[0]); err != nil {
+				handleError(&usageError{err: err})
+			}
+			format, err := outputFormat(formatFlag)
+			if err != nil {
+				handleError(err)
+			}
+			if format == formatCSV && jsonOutput {
+				handleError(&usageError{err: fmt.Errorf("-o csv cannot be combined with --json")})
+			}
 
 			client, err := getClient()
 			if err != nil {
 				handleError(err)
 			}
 
-			result, err := client.Diagnostics.TraceRoute(args[0], resolveAddr)
+			// The player answers once the whole trace is done, so there are
+			// no partial results to print while it runs
+			result, err := client.Diagnostics.TraceRouteWithOptions(args[0], &brightsign.TraceRouteOptions{
+				ResolveAddress: resolveAddr,
+				MaxHops:        maxHops,
+				Timeout:        timeout,
+			})
 			if err != nil {
 				handleError(err)
 			}
 
+			if jsonOutput {
+				outputJSON(result)
+				return
+			}
+			if format == formatCSV {
+				if err := newCSVWriter(os.Stdout, !noHeader).Write(result, time.Now()); err != nil {
+					handleError(err)
+				}
+				return
+			}
+
 			if result.Success {
 				fmt.Printf("Traceroute to %s:\n", result.Target)
 				for _, hop := range result.Hops {
@@ -177,6 +295,10 @@ func addDiagnosticsCommands() {
 		},
 	}
 	tracerouteCmd.Flags().Bool("resolve", false, "Resolve addresses")
+	tracerouteCmd.Flags().Int("max-hops", 0, "Maximum number of hops (0 = player default)")
+	tracerouteCmd.Flags().Int("timeout", 0, "Per-hop timeout in seconds (0 = player default)")
+	tracerouteCmd.Flags().StringP("output", "o", formatText, "Output format: text or csv")
+	tracerouteCmd.Flags().Bool("no-header", false, "Omit the CSV header row")
 
 	// Network interfaces command
 	interfacesCmd := &cobra.Command{
@@ -202,6 +324,80 @@ func addDiagnosticsCommands() {
 			for _, iface := range interfaces {
 				fmt.Printf("  - %s\n", iface)
 			}
+			if len(interfaces) == 0 {
+				fmt.Println("  No interfaces reported")
+			}
+		},
+	}
+
+	// Wi-Fi scan command
+	wifiScanCmd := &cobra.Command{
+		Use:   "wifi-scan",
+		Short: "List wireless networks visible to the player",
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := getClient()
+			if err != nil {
+				handleError(err)
+			}
+
+			networks, err := client.Diagnostics.WiFiScan()
+			if err != nil {
+				handleError(err)
+			}
+
+			if jsonOutput {
+				outputJSON(networks)
+				return
+			}
+
+			if len(networks) == 0 {
+				fmt.Println("No wireless networks found")
+				return
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "SSID\tBSSID\tSIGNAL\tCHANNEL\tSECURITY")
+			for _, network := range networks {
+				ssid := network.SSID
+				if ssid == "" {
+					ssid = "(hidden)"
+				}
+				security := network.Security
+				if security == "" {
+					security = "-"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%d dBm\t%d\t%s\n", ssid, network.BSSID, network.Signal, network.Channel, security)
+			}
+			w.Flush()
+		},
+	}
+
+	// Network neighborhood command
+	neighborhoodCmd := &cobra.Command{
+		Use:   "neighborhood",
+		Short: "Show other BrightSign players seen on the network",
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := getClient()
+			if err != nil {
+				handleError(err)
+			}
+
+			neighborhood, err := client.Diagnostics.GetNetworkNeighborhood()
+			if err != nil {
+				handleError(err)
+			}
+
+			if jsonOutput {
+				outputJSON(neighborhood)
+				return
+			}
+
+			if len(neighborhood) == 0 {
+				fmt.Println("No players reported")
+				return
+			}
+
+			printNeighborhood(os.Stdout, neighborhood)
 		},
 	}
 
@@ -221,10 +417,96 @@ func addDiagnosticsCommands() {
 				handleError(err)
 			}
 
-			data, _ := json.MarshalIndent(config, "", "  ")
-			fmt.Println(string(data))
+			if jsonOutput {
+				outputJSON(config)
+				return
+			}
+
+			printNetworkConfig(os.Stdout, config, "")
+		},
+	}
+
+	// Network configuration test-apply command
+	netConfigSetCmd := &cobra.Command{
+		Use:   "network-config-set [interface]",
+		Short: "Test-apply network configuration for interface",
+		Long: `Test-apply a network configuration and report whether the player is still
+reachable with it. An unreachable configuration is rolled back by the player,
+so this shows whether a static IP change would strand it.
+
+DNS servers and the VLAN tag are left unchanged unless --dns, --vlan,
+--clear-dns or --clear-vlan is given. --dns may be repeated or comma separated.`,
+		Example: `  bscli 192.168.1.100 diagnostics network-config-set eth0 --ip 10.0.0.5 --netmask 255.255.255.0 \
+    --gateway 10.0.0.1 --dns 10.0.0.2 --dns 10.0.0.3 --clear-vlan`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			dhcp, _ := cmd.Flags().GetBool("dhcp")
+			ip, _ := cmd.Flags().GetString("ip")
+			netmask, _ := cmd.Flags().GetString("netmask")
+			gateway, _ := cmd.Flags().GetString("gateway")
+			dns, _ := cmd.Flags().GetStringSlice("dns")
+			vlan, _ := cmd.Flags().GetInt("vlan")
+			clearDNS, _ := cmd.Flags().GetBool("clear-dns")
+			clearVLAN, _ := cmd.Flags().GetBool("clear-vlan")
+
+			if !dhcp && ip == "" {
+				handleError(&usageError{err: fmt.Errorf("either --dhcp or --ip is required")})
+			}
+			if clearDNS && len(dns) > 0 {
+				handleError(&usageError{err: fmt.Errorf("--clear-dns cannot be combined with --dns")})
+			}
+			if clearVLAN && cmd.Flags().Changed("vlan") {
+				handleError(&usageError{err: fmt.Errorf("--clear-vlan cannot be combined with --vlan")})
+			}
+			if cmd.Flags().Changed("vlan") && (vlan < 1 || vlan > 4094) {
+				handleError(&usageError{err: fmt.Errorf("--vlan must be between 1 and 4094 (use --clear-vlan to remove the tag)")})
+			}
+
+			client, err := getClient()
+			if err != nil {
+				handleError(err)
+			}
+
+			result, err := client.Diagnostics.SetNetworkConfiguration(args[0], brightsign.NetworkConfig{
+				Interface: args[0],
+				DHCP:      dhcp,
+				IP:        ip,
+				Netmask:   netmask,
+				Gateway:   gateway,
+				DNS:       dns,
+				VLANID:    vlan,
+				ClearDNS:  clearDNS,
+				ClearVLAN: clearVLAN,
+			})
+			if err != nil {
+				handleError(err)
+			}
+
+			if jsonOutput {
+				outputJSON(result)
+				return
+			}
+
+			if result.Reachable {
+				fmt.Println("Reachable: yes")
+			} else {
+				fmt.Println("Reachable: no - this configuration would leave the player unreachable")
+			}
+			if result.Error != "" {
+				fmt.Printf("Error: %s\n", result.Error)
+			}
+			fmt.Println("Configuration:")
+			printNetworkConfig(os.Stdout, &result.AppliedConfig, "  ")
 		},
 	}
+	netConfigSetCmd.Flags().Bool("dhcp", false, "Use DHCP")
+	netConfigSetCmd.Flags().String("ip", "", "Static IP address")
+	netConfigSetCmd.Flags().String("netmask", "", "Netmask for static IP")
+	netConfigSetCmd.Flags().String("gateway", "", "Default gateway for static IP")
+	netConfigSetCmd.Flags().StringSlice("dns", nil, "DNS server (repeat or comma separate for several)")
+	netConfigSetCmd.Flags().Int("vlan", 0, "VLAN ID (1-4094)")
+	netConfigSetCmd.Flags().Bool("clear-dns", false, "Remove all DNS servers")
+	netConfigSetCmd.Flags().Bool("clear-vlan", false, "Remove the VLAN tag")
 
 	// Packet capture commands
 	pcapCmd := &cobra.Command{
@@ -247,6 +529,11 @@ func addDiagnosticsCommands() {
 				handleError(err)
 			}
 
+			if jsonOutput {
+				outputJSON(status)
+				return
+			}
+
 			if status.Running {
 				fmt.Println("Packet capture is running")
 				fmt.Printf("Interface: %s\n", status.Interface)
@@ -289,7 +576,7 @@ func addDiagnosticsCommands() {
 				handleError(err)
 			}
 
-			fmt.Println("Packet capture started")
+			reportSuccess("packet-capture-start", "Packet capture started", map[string]interface{}{"interface": args[0]})
 		},
 	}
 	pcapStartCmd.Flags().Int("duration", 60, "Capture duration in seconds")
@@ -311,11 +598,70 @@ func addDiagnosticsCommands() {
 				handleError(err)
 			}
 
-			fmt.Println("Packet capture stopped")
+			reportSuccess("packet-capture-stop", "Packet capture stopped", nil)
 		},
 	}
 
-	pcapCmd.AddCommand(pcapStatusCmd, pcapStartCmd, pcapStopCmd)
+	pcapRunCmd := &cobra.Command{
+		Use:   "run [interface]",
+		Short: "Capture packets, wait for completion and download the result",
+		Long: `Start a capture, poll its status until it stops and download the capture file.
+
+The capture is written to --output on the player and downloaded to --download.
+If the player still reports the capture as running after --timeout (default:
+duration plus one minute) the command fails and the capture is left running.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			duration, _ := cmd.Flags().GetDuration("duration")
+			timeout, _ := cmd.Flags().GetDuration("timeout")
+			maxSize, _ := cmd.Flags().GetInt("max-size")
+			filter, _ := cmd.Flags().GetString("filter")
+			output, _ := cmd.Flags().GetString("output")
+			download, _ := cmd.Flags().GetString("download")
+
+			if duration < time.Second {
+				handleError(&usageError{err: fmt.Errorf("--duration must be at least 1s")})
+			}
+			if timeout == 0 {
+				timeout = duration + time.Minute
+			}
+
+			client, err := getClient()
+			if err != nil {
+				handleError(err)
+			}
+
+			config := brightsign.PacketCaptureConfig{
+				Interface:   args[0],
+				Duration:    int(duration / time.Second),
+				MaxFileSize: maxSize,
+				Filter:      filter,
+				OutputFile:  output,
+			}
+
+			infof("Capturing on %s for %s...", args[0], duration)
+
+			status, err := runPacketCapture(client, config, download, timeout)
+			if err != nil {
+				handleError(err)
+			}
+
+			reportSuccess("packet-capture", "Packet capture downloaded to "+download, map[string]interface{}{
+				"interface":     args[0],
+				"remote":        status.OutputFile,
+				"local":         download,
+				"bytesCaptured": status.BytesCaptured,
+			})
+		},
+	}
+	pcapRunCmd.Flags().Duration("duration", 30*time.Second, "Capture duration")
+	pcapRunCmd.Flags().Duration("timeout", 0, "Maximum time to wait for the capture to finish (default: duration + 1m)")
+	pcapRunCmd.Flags().Int("max-size", 0, "Maximum file size in bytes")
+	pcapRunCmd.Flags().String("filter", "", "Capture filter expression")
+	pcapRunCmd.Flags().String("output", defaultCaptureFile, "Capture file path on the player")
+	pcapRunCmd.Flags().String("download", "capture.pcap", "Local file to download the capture to")
+
+	pcapCmd.AddCommand(pcapStatusCmd, pcapStartCmd, pcapStopCmd, pcapRunCmd)
 
 	// Telnet configuration
 	telnetCmd := &cobra.Command{
@@ -337,6 +683,11 @@ func addDiagnosticsCommands() {
 				handleError(err)
 			}
 
+			if jsonOutput {
+				outputJSON(config)
+				return
+			}
+
 			if config.Enabled {
 				fmt.Printf("Telnet is enabled on port %d\n", config.PortNumber)
 			} else {
@@ -368,10 +719,11 @@ func addDiagnosticsCommands() {
 				handleError(err)
 			}
 
-			fmt.Println("Telnet enabled")
-			if reboot {
-				fmt.Println("Player will reboot")
-			}
+			reportSuccess("telnet", withRebootNotice("Telnet enabled", reboot), map[string]interface{}{
+				"enabled": true,
+				"port":    port,
+				"reboot":  reboot,
+			})
 		},
 	}
 	telnetEnableCmd.Flags().Int("port", 23, "Telnet port number")
@@ -398,10 +750,10 @@ func addDiagnosticsCommands() {
 				handleError(err)
 			}
 
-			fmt.Println("Telnet disabled")
-			if reboot {
-				fmt.Println("Player will reboot")
-			}
+			reportSuccess("telnet", withRebootNotice("Telnet disabled", reboot), map[string]interface{}{
+				"enabled": false,
+				"reboot":  reboot,
+			})
 		},
 	}
 	telnetDisableCmd.Flags().Bool("reboot", false, "Reboot after change")
@@ -428,6 +780,13 @@ func addDiagnosticsCommands() {
 				handleError(err)
 			}
 
+			if jsonOutput {
+				// Never echo a password, should the firmware report one
+				config.Password = ""
+				outputJSON(config)
+				return
+			}
+
 			if config.Enabled {
 				fmt.Printf("SSH is enabled on port %d\n", config.PortNumber)
 			} else {
@@ -461,10 +820,11 @@ func addDiagnosticsCommands() {
 				handleError(err)
 			}
 
-			fmt.Println("SSH enabled")
-			if reboot {
-				fmt.Println("Player will reboot")
-			}
+			reportSuccess("ssh", withRebootNotice("SSH enabled", reboot), map[string]interface{}{
+				"enabled": true,
+				"port":    port,
+				"reboot":  reboot,
+			})
 		},
 	}
 	sshEnableCmd.Flags().Int("port", 22, "SSH port number")
@@ -492,17 +852,207 @@ func addDiagnosticsCommands() {
 				handleError(err)
 			}
 
-			fmt.Println("SSH disabled")
-			if reboot {
-				fmt.Println("Player will reboot")
-			}
+			reportSuccess("ssh", withRebootNotice("SSH disabled", reboot), map[string]interface{}{
+				"enabled": false,
+				"reboot":  reboot,
+			})
 		},
 	}
 	sshDisableCmd.Flags().Bool("reboot", false, "Reboot after change")
 
 	sshCmd.AddCommand(sshStatusCmd, sshEnableCmd, sshDisableCmd)
 
+	runDiagCmd.Flags().StringSlice("only", nil, "Only show and check these tests (comma separated)")
+	runDiagCmd.Flags().StringSlice("skip", nil, "Do not show or check these tests (comma separated)")
+	runDiagCmd.Flags().Bool("fail-on-error", false, "Exit with status 1 if any selected test failed")
+
 	diagCmd.AddCommand(runDiagCmd, pingCmd, dnsCmd, tracerouteCmd, interfacesCmd, 
-		netConfigCmd, pcapCmd, telnetCmd, sshCmd)
+		wifiScanCmd, neighborhoodCmd, netConfigCmd, netConfigSetCmd, pcapCmd, telnetCmd, sshCmd)
 	rootCmd.AddCommand(diagCmd)
-}
\ No newline at end of file
+}
+
+// defaultCaptureFile is where pcap run asks the player to write its capture
+const defaultCaptureFile = "/storage/sd/bscli-capture.pcap"
+
+// pcapPollInterval is how often pcap run checks whether the capture finished
+var pcapPollInterval = 2 * time.Second
+
+// runPacketCapture starts a capture, waits until the player reports it as no
+// longer running and downloads the capture file to localPath
+func runPacketCapture(client *brightsign.Client, config brightsign.PacketCaptureConfig, localPath string, timeout time.Duration) (*brightsign.PacketCaptureStatus, error) {
+	if err := client.Diagnostics.StartPacketCapture(config); err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(timeout)
+	for {
+		time.Sleep(pcapPollInterval)
+
+		status, err := client.Diagnostics.GetPacketCaptureStatus()
+		if err != nil {
+			return nil, err
+		}
+
+		if !status.Running {
+			if status.OutputFile == "" {
+				status.OutputFile = config.OutputFile
+			}
+			if status.OutputFile == "" {
+				return nil, fmt.Errorf("packet capture finished but the player did not report a capture file")
+			}
+			if err := client.Storage.DownloadFile(status.OutputFile, localPath); err != nil {
+				return nil, err
+			}
+			return status, nil
+		}
+
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("packet capture still running after %s; stop it with 'diagnostics pcap stop'", timeout)
+		}
+	}
+}
+
+// printNetworkConfig prints an interface configuration one setting per line,
+// each prefixed with indent. Static addressing fields are only shown when set.
+func printNetworkConfig(out io.Writer, config *brightsign.NetworkConfig, indent string) {
+	dhcp := "off"
+	if config.DHCP {
+		dhcp = "on"
+	}
+	fmt.Fprintf(out, "%sInterface: %s\n", indent, config.Interface)
+	fmt.Fprintf(out, "%sDHCP: %s\n", indent, dhcp)
+	if config.IP != "" {
+		fmt.Fprintf(out, "%sIP Address: %s\n", indent, config.IP)
+	}
+	if config.Netmask != "" {
+		fmt.Fprintf(out, "%sNetmask: %s\n", indent, config.Netmask)
+	}
+	if config.Gateway != "" {
+		fmt.Fprintf(out, "%sGateway: %s\n", indent, config.Gateway)
+	}
+	if len(config.DNS) > 0 {
+		fmt.Fprintf(out, "%sDNS: %s\n", indent, strings.Join(config.DNS, ", "))
+	}
+	if config.VLANID != 0 {
+		fmt.Fprintf(out, "%sVLAN: %d\n", indent, config.VLANID)
+	}
+}
+
+// checkDiagnosticNames reports names that match none of the tests in results,
+// so a misspelled --only or --skip does not silently select nothing
+func checkDiagnosticNames(results []brightsign.DiagnosticResult, names []string) error {
+	for _, name := range names {
+		if len(brightsign.FilterDiagnostics(results, []string{name}, nil)) > 0 {
+			continue
+		}
+		tests := make([]string, len(results))
+		for i, result := range results {
+			tests[i] = result.Test
+		}
+		return fmt.Errorf("unknown diagnostic test %q (available: %s)", name, strings.Join(tests, ", "))
+	}
+	return nil
+}
+
+// printPingResult prints one ping summary
+func printPingResult(result *brightsign.PingResult) {
+	if result.Success {
+		fmt.Printf("PING %s: %d/%d packets received\n", result.Address, result.PacketsRecv, result.PacketsSent)
+		fmt.Printf("Packet Loss: %.1f%%\n", result.PacketLoss)
+		fmt.Printf("RTT min/avg/max = %.2f/%.2f/%.2f ms\n", result.MinTime, result.AvgTime, result.MaxTime)
+	} else {
+		fmt.Printf("PING %s failed: %s\n", result.Address, result.ErrorMessage)
+	}
+}
+
+// renderDiagnostics prints diagnostics results as a table with pass/fail
+// marks and the duration of each test where the firmware reported one. The
+// colored marks are added after alignment since tabwriter would count escape
+// sequences as width.
+func renderDiagnostics(out io.Writer, results []brightsign.DiagnosticResult) {
+	var table bytes.Buffer
+	w := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TEST\tTIME\tMESSAGE")
+	for _, result := range results {
+		duration := "-"
+		if result.DurationMs > 0 {
+			duration = result.Duration().Round(time.Millisecond).String()
+		}
+		message := result.Message
+		if message == "" && !result.Passed() {
+			message = result.Status
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\n", tableCell(result.Test), duration, tableCell(message))
+	}
+	w.Flush()
+
+	lines := strings.Split(strings.TrimSuffix(table.String(), "\n"), "\n")
+	fmt.Fprintf(out, "  %s\n", bold(lines[0]))
+	for i, line := range lines[1:] {
+		mark := green("✓")
+		if !results[i].Passed() {
+			mark = red("✗")
+		}
+		fmt.Fprintf(out, "%s %s\n", mark, strings.TrimRight(line, " "))
+	}
+}
+
+// printNeighborhood prints one row per reported entry, sorted by name, with
+// nested fields flattened to sorted key=value pairs
+func printNeighborhood(out io.Writer, neighborhood map[string]interface{}) {
+	names := make([]string, 0, len(neighborhood))
+	for name := range neighborhood {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tDETAILS")
+	for _, name := range names {
+		fmt.Fprintf(w, "%s\t%s\n", tableCell(name), tableCell(neighborDetails(neighborhood[name])))
+	}
+	w.Flush()
+}
+
+// neighborDetails renders one neighborhood value for printNeighborhood
+func neighborDetails(value interface{}) string {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		fields := make([]string, 0, len(keys))
+		for _, key := range keys {
+			fields = append(fields, key+"="+neighborDetails(v[key]))
+		}
+		return strings.Join(fields, " ")
+	case []interface{}:
+		items := make([]string, 0, len(v))
+		for _, item := range v {
+			items = append(items, neighborDetails(item))
+		}
+		return strings.Join(items, ",")
+	case nil:
+		return "-"
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// tableCell collapses runs of whitespace, including newlines and tabs, to a
+// single space so a cell always renders as one table row
+func tableCell(s string) string {
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// withRebootNotice appends a reboot notice to message when the change
+// reboots the player
+func withRebootNotice(message string, reboot bool) string {
+	if reboot {
+		return message + "\nPlayer will reboot"
+	}
+	return message
+}
@@ -0,0 +1,131 @@
+package cli
+
+import (
+	"fmt"
+	"net"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"bscli/pkg/brightsign"
+	"github.com/spf13/cobra"
+)
+
+func addDiscoverCommands() {
+	discoverCmd := &cobra.Command{
+		Use:   "discover",
+		Short: "Find BrightSign players on the local network",
+		Long: `Sweep a subnet for players by probing the DWS info endpoint of every address.
+
+No host argument is needed. The subnet defaults to the first non-loopback IPv4
+interface, narrowed to its /24. Players that require authentication are listed
+without model and serial unless a password is given with -p.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			subnet, _ := cmd.Flags().GetString("subnet")
+			timeout, _ := cmd.Flags().GetDuration("timeout")
+
+			if subnet == "" {
+				var err error
+				subnet, err = localSubnet()
+				if err != nil {
+					handleError(err)
+				}
+			}
+
+			infof("Scanning %s...", subnet)
+
+			players, err := brightsign.DiscoverPlayers(subnet, timeout)
+			if err != nil {
+				handleError(&usageError{err: err})
+			}
+
+			// With credentials, fill in the details of protected players
+			if password != "" {
+				for i := range players {
+					if players[i].AuthRequired {
+						fillPlayerInfo(&players[i], timeout)
+					}
+				}
+			}
+
+			if jsonOutput {
+				outputJSON(players)
+				return
+			}
+
+			if len(players) == 0 {
+				fmt.Println("No players found")
+				return
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "HOST\tMODEL\tSERIAL\tFIRMWARE")
+			fmt.Fprintln(w, "----\t-----\t------\t--------")
+			for _, player := range players {
+				model, serial, firmware := player.Model, player.Serial, player.FWVersion
+				if player.AuthRequired {
+					model, serial, firmware = "(auth required)", "-", "-"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", player.Host, model, serial, firmware)
+			}
+			w.Flush()
+		},
+	}
+	discoverCmd.Flags().String("subnet", "", "Subnet to scan in CIDR notation (default: local /24)")
+	discoverCmd.Flags().Duration("timeout", time.Second, "Timeout for each probe")
+
+	rootCmd.AddCommand(discoverCmd)
+}
+
+// fillPlayerInfo reads model and serial of a protected player using the
+// global credentials. Players that reject them are left as they are.
+func fillPlayerInfo(player *brightsign.DiscoveredPlayer, timeout time.Duration) {
+	client := brightsign.NewClient(brightsign.Config{
+		Host:     player.Host,
+		Username: username,
+		Password: password,
+		Debug:    debug,
+		Trace:    trace,
+		Timeout:  timeout,
+	})
+
+	info, err := client.Info.GetInfo()
+	if err != nil {
+		return
+	}
+
+	player.Model = info.Model
+	player.Serial = info.Serial
+	player.Family = info.Family
+	player.FWVersion = info.FWVersion
+	player.AuthRequired = false
+}
+
+// localSubnet returns the /24 of the first non-loopback IPv4 interface address
+func localSubnet() (string, error) {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return "", fmt.Errorf("failed to list network interfaces: %w", err)
+	}
+
+	for _, addr := range addrs {
+		ipNet, ok := addr.(*net.IPNet)
+		if !ok || ipNet.IP.IsLoopback() {
+			continue
+		}
+		ip := ipNet.IP.To4()
+		if ip == nil {
+			continue
+		}
+
+		ones, _ := ipNet.Mask.Size()
+		if ones < 24 {
+			ones = 24
+		}
+		network := ip.Mask(net.CIDRMask(ones, 32))
+		return fmt.Sprintf("%s/%d", network, ones), nil
+	}
+
+	return "", &usageError{err: fmt.Errorf("no IPv4 network interface found, use --subnet")}
+}
@@ -1,12 +1,51 @@
 package cli
 
 import (
-	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 
+	"bscli/pkg/brightsign"
 	"github.com/spf13/cobra"
 )
 
+// getDisplayClient returns a client for a player whose firmware supports
+// display control
+func getDisplayClient() (*brightsign.Client, error) {
+	client, err := getClient()
+	if err != nil {
+		return nil, err
+	}
+	if err := requireVersion(client, "display control", brightsign.MinVersionDisplay); err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// setDisplayPower switches the connected display on or to standby. It backs
+// both display power and the control display-on/display-off shortcuts.
+func setDisplayPower(state string) {
+	client, err := getDisplayClient()
+	if err != nil {
+		handleError(err)
+	}
+
+	if err := client.Display.SetPowerSettings(state); err != nil {
+		handleError(err)
+	}
+
+	if jsonOutput {
+		outputSuccess("power", map[string]interface{}{"state": state})
+		return
+	}
+
+	if state == "on" {
+		fmt.Println("Display turned on")
+	} else {
+		fmt.Println("Display in standby mode")
+	}
+}
+
 func addDisplayCommands() {
 	displayCmd := &cobra.Command{
 		Use:   "display",
@@ -19,7 +58,7 @@ func addDisplayCommands() {
 		Use:   "get-all",
 		Short: "Get all display settings",
 		Run: func(cmd *cobra.Command, args []string) {
-			client, err := getClient()
+			client, err := getDisplayClient()
 			if err != nil {
 				handleError(err)
 			}
@@ -29,8 +68,12 @@ func addDisplayCommands() {
 				handleError(err)
 			}
 
-			data, _ := json.MarshalIndent(settings, "", "  ")
-			fmt.Println(string(data))
+			if jsonOutput {
+				outputJSON(settings)
+				return
+			}
+
+			printDisplaySettings(os.Stdout, settings)
 		},
 	}
 
@@ -39,7 +82,7 @@ func addDisplayCommands() {
 		Use:   "info",
 		Short: "Get display information",
 		Run: func(cmd *cobra.Command, args []string) {
-			client, err := getClient()
+			client, err := getDisplayClient()
 			if err != nil {
 				handleError(err)
 			}
@@ -49,6 +92,11 @@ func addDisplayCommands() {
 				handleError(err)
 			}
 
+			if jsonOutput {
+				outputJSON(info)
+				return
+			}
+
 			fmt.Printf("Model: %s\n", info.Model)
 			fmt.Printf("Serial: %s\n", info.SerialNumber)
 			fmt.Printf("Version: %s\n", info.Version)
@@ -66,7 +114,7 @@ func addDisplayCommands() {
 		Use:   "get",
 		Short: "Get brightness setting",
 		Run: func(cmd *cobra.Command, args []string) {
-			client, err := getClient()
+			client, err := getDisplayClient()
 			if err != nil {
 				handleError(err)
 			}
@@ -76,7 +124,12 @@ func addDisplayCommands() {
 				handleError(err)
 			}
 
-			fmt.Printf("Brightness: %d (min: %d, max: %d)\n", 
+			if jsonOutput {
+				outputJSON(brightness)
+				return
+			}
+
+			fmt.Printf("Brightness: %d (min: %d, max: %d)\n",
 				brightness.Value, brightness.Min, brightness.Max)
 		},
 	}
@@ -86,19 +139,35 @@ func addDisplayCommands() {
 		Short: "Set brightness value",
 		Args:  cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			var value int
-			fmt.Sscanf(args[0], "%d", &value)
+			value, err := parseIntArg(args[0], "brightness")
+			if err != nil {
+				handleError(err)
+			}
+
+			client, err := getDisplayClient()
+			if err != nil {
+				handleError(err)
+			}
 
-			client, err := getClient()
+			current, err := client.Display.GetBrightness()
 			if err != nil {
 				handleError(err)
 			}
 
+			if err := checkDisplayLevel("brightness", value, current.Min, current.Max); err != nil {
+				handleError(err)
+			}
+
 			err = client.Display.SetBrightness(value)
 			if err != nil {
 				handleError(err)
 			}
 
+			if jsonOutput {
+				outputSuccess("brightness", map[string]interface{}{"value": value})
+				return
+			}
+
 			fmt.Printf("Brightness set to %d\n", value)
 		},
 	}
@@ -115,7 +184,7 @@ func addDisplayCommands() {
 		Use:   "get",
 		Short: "Get contrast setting",
 		Run: func(cmd *cobra.Command, args []string) {
-			client, err := getClient()
+			client, err := getDisplayClient()
 			if err != nil {
 				handleError(err)
 			}
@@ -125,7 +194,12 @@ func addDisplayCommands() {
 				handleError(err)
 			}
 
-			fmt.Printf("Contrast: %d (min: %d, max: %d)\n", 
+			if jsonOutput {
+				outputJSON(contrast)
+				return
+			}
+
+			fmt.Printf("Contrast: %d (min: %d, max: %d)\n",
 				contrast.Value, contrast.Min, contrast.Max)
 		},
 	}
@@ -135,19 +209,35 @@ func addDisplayCommands() {
 		Short: "Set contrast value",
 		Args:  cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			var value int
-			fmt.Sscanf(args[0], "%d", &value)
+			value, err := parseIntArg(args[0], "contrast")
+			if err != nil {
+				handleError(err)
+			}
+
+			client, err := getDisplayClient()
+			if err != nil {
+				handleError(err)
+			}
 
-			client, err := getClient()
+			current, err := client.Display.GetContrast()
 			if err != nil {
 				handleError(err)
 			}
 
+			if err := checkDisplayLevel("contrast", value, current.Min, current.Max); err != nil {
+				handleError(err)
+			}
+
 			err = client.Display.SetContrast(value)
 			if err != nil {
 				handleError(err)
 			}
 
+			if jsonOutput {
+				outputSuccess("contrast", map[string]interface{}{"value": value})
+				return
+			}
+
 			fmt.Printf("Contrast set to %d\n", value)
 		},
 	}
@@ -164,7 +254,7 @@ func addDisplayCommands() {
 		Use:   "get",
 		Short: "Get volume setting",
 		Run: func(cmd *cobra.Command, args []string) {
-			client, err := getClient()
+			client, err := getDisplayClient()
 			if err != nil {
 				handleError(err)
 			}
@@ -174,7 +264,12 @@ func addDisplayCommands() {
 				handleError(err)
 			}
 
-			fmt.Printf("Volume: %d (min: %d, max: %d)\n", 
+			if jsonOutput {
+				outputJSON(volume)
+				return
+			}
+
+			fmt.Printf("Volume: %d (min: %d, max: %d)\n",
 				volume.Value, volume.Min, volume.Max)
 		},
 	}
@@ -184,19 +279,35 @@ func addDisplayCommands() {
 		Short: "Set volume value",
 		Args:  cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			var value int
-			fmt.Sscanf(args[0], "%d", &value)
+			value, err := parseIntArg(args[0], "volume")
+			if err != nil {
+				handleError(err)
+			}
 
-			client, err := getClient()
+			client, err := getDisplayClient()
 			if err != nil {
 				handleError(err)
 			}
 
+			current, err := client.Display.GetVolume()
+			if err != nil {
+				handleError(err)
+			}
+
+			if err := checkDisplayLevel("volume", value, current.Min, current.Max); err != nil {
+				handleError(err)
+			}
+
 			err = client.Display.SetVolume(value)
 			if err != nil {
 				handleError(err)
 			}
 
+			if jsonOutput {
+				outputSuccess("volume", map[string]interface{}{"value": value})
+				return
+			}
+
 			fmt.Printf("Volume set to %d\n", value)
 		},
 	}
@@ -213,7 +324,7 @@ func addDisplayCommands() {
 		Use:   "get",
 		Short: "Get power state",
 		Run: func(cmd *cobra.Command, args []string) {
-			client, err := getClient()
+			client, err := getDisplayClient()
 			if err != nil {
 				handleError(err)
 			}
@@ -223,6 +334,11 @@ func addDisplayCommands() {
 				handleError(err)
 			}
 
+			if jsonOutput {
+				outputJSON(power)
+				return
+			}
+
 			fmt.Printf("Power state: %s\n", power.State)
 		},
 	}
@@ -231,17 +347,7 @@ func addDisplayCommands() {
 		Use:   "on",
 		Short: "Turn display on",
 		Run: func(cmd *cobra.Command, args []string) {
-			client, err := getClient()
-			if err != nil {
-				handleError(err)
-			}
-
-			err = client.Display.SetPowerSettings("on")
-			if err != nil {
-				handleError(err)
-			}
-
-			fmt.Println("Display turned on")
+			setDisplayPower("on")
 		},
 	}
 
@@ -249,17 +355,7 @@ func addDisplayCommands() {
 		Use:   "standby",
 		Short: "Put display in standby",
 		Run: func(cmd *cobra.Command, args []string) {
-			client, err := getClient()
-			if err != nil {
-				handleError(err)
-			}
-
-			err = client.Display.SetPowerSettings("standby")
-			if err != nil {
-				handleError(err)
-			}
-
-			fmt.Println("Display in standby mode")
+			setDisplayPower("standby")
 		},
 	}
 
@@ -271,15 +367,11 @@ func addDisplayCommands() {
 		Short: "Update display firmware",
 		Args:  cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			fmt.Print("Update display firmware? This may take several minutes. Continue? (y/N): ")
-			var response string
-			fmt.Scanln(&response)
-			if response != "y" && response != "Y" {
-				fmt.Println("Cancelled")
+			if !confirm("Update display firmware? This may take several minutes. Continue?") {
 				return
 			}
 
-			client, err := getClient()
+			client, err := getDisplayClient()
 			if err != nil {
 				handleError(err)
 			}
@@ -289,6 +381,11 @@ func addDisplayCommands() {
 				handleError(err)
 			}
 
+			if jsonOutput {
+				outputSuccess("firmware-update", map[string]interface{}{"source": args[0]})
+				return
+			}
+
 			fmt.Println("Firmware update initiated")
 		},
 	}
@@ -296,4 +393,82 @@ func addDisplayCommands() {
 	displayCmd.AddCommand(getAllCmd, infoCmd, brightnessCmd, contrastCmd, 
 		volumeCmd, powerCmd, firmwareUpdateCmd)
 	rootCmd.AddCommand(displayCmd)
-}
\ No newline at end of file
+}
+
+// checkDisplayLevel checks a brightness, contrast or volume value against
+// the bounds reported by the display
+func checkDisplayLevel(name string, value, min, max int) error {
+	if _, err := clampOrError(value, min, max); err != nil {
+		return &usageError{err: fmt.Errorf("invalid %s: %w", name, err)}
+	}
+	return nil
+}
+
+// clampOrError returns value if it lies within [min, max] and an error
+// naming the bounds otherwise. Displays that report no bounds (both zero)
+// accept any value.
+func clampOrError(value, min, max int) (int, error) {
+	if min == 0 && max == 0 {
+		return value, nil
+	}
+	if value < min || value > max {
+		return 0, fmt.Errorf("%d is out of range, must be between %d and %d", value, min, max)
+	}
+	return value, nil
+}
+
+// printDisplaySettings prints one labeled line per setting the display
+// reported; settings it left out are skipped
+func printDisplaySettings(out io.Writer, settings *brightsign.DisplaySettings) {
+	// valueRange formats the min/max suffix when the display reported a range
+	valueRange := func(min, max int) string {
+		if max == 0 {
+			return ""
+		}
+		return fmt.Sprintf(" (min: %d, max: %d)", min, max)
+	}
+	yesNo := func(b bool) string {
+		if b {
+			return "yes"
+		}
+		return "no"
+	}
+
+	printed := false
+	line := func(format string, args ...interface{}) {
+		fmt.Fprintf(out, format+"\n", args...)
+		printed = true
+	}
+
+	if s := settings.PowerSettings; s != nil {
+		line("Power: %s", s.State)
+	}
+	if s := settings.Brightness; s != nil {
+		line("Brightness: %d%s", s.Value, valueRange(s.Min, s.Max))
+	}
+	if s := settings.Contrast; s != nil {
+		line("Contrast: %d%s", s.Value, valueRange(s.Min, s.Max))
+	}
+	if s := settings.Volume; s != nil {
+		line("Volume: %d%s", s.Value, valueRange(s.Min, s.Max))
+	}
+	if s := settings.WhiteBalance; s != nil {
+		line("White Balance: red %d, green %d, blue %d%s", s.Red, s.Green, s.Blue, valueRange(s.Min, s.Max))
+	}
+	if s := settings.StandbyTimeout; s != nil {
+		line("Standby Timeout: %ds%s", s.Seconds, valueRange(s.Min, s.Max))
+	}
+	if s := settings.VideoOutput; s != nil {
+		line("Video Output: %s", s.Output)
+	}
+	if s := settings.SDConnection; s != nil {
+		line("SD Connection: %s", s.Target)
+	}
+	if s := settings.AlwaysConnected; s != nil {
+		line("Always Connected: %s", yesNo(s.Enabled))
+	}
+
+	if !printed {
+		fmt.Fprintln(out, "No display settings reported")
+	}
+}
@@ -0,0 +1,23 @@
+package cli
+
+import "os"
+
+// envPrefixes lists the environment variable prefixes in order of precedence.
+// BSCLI_TEST_ was the original prefix and is still honored for compatibility.
+var envPrefixes = []string{"BSCLI_", "BSCLI_TEST_"}
+
+// getenv returns BSCLI_<name>, falling back to BSCLI_TEST_<name> when the
+// former is unset or empty
+func getenv(name string) string {
+	for _, prefix := range envPrefixes {
+		if value := os.Getenv(prefix + name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// getenvBool reports whether BSCLI_<name> (or BSCLI_TEST_<name>) is "true"
+func getenvBool(name string) bool {
+	return getenv(name) == "true"
+}
@@ -0,0 +1,86 @@
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// projectFields returns the parts of data named by paths, as selected with
+// --fields. Each path is a dot-separated list of JSON keys such as
+// "network.hostname"; the result keeps the nesting of the original. Arrays
+// are projected element by element, and keys missing from data are left out.
+func projectFields(data interface{}, paths []string) (interface{}, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, err
+	}
+
+	var keys [][]string
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		parts := strings.Split(path, ".")
+		for _, part := range parts {
+			if part == "" {
+				return nil, fmt.Errorf("invalid field %q", path)
+			}
+		}
+		keys = append(keys, parts)
+	}
+	if len(keys) == 0 {
+		return value, nil
+	}
+
+	projected, _ := project(value, keys)
+	return projected, nil
+}
+
+// project selects keys from value, reporting whether anything was found
+func project(value interface{}, keys [][]string) (interface{}, bool) {
+	switch v := value.(type) {
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		found := false
+		for i, elem := range v {
+			var ok bool
+			out[i], ok = project(elem, keys)
+			found = found || ok
+		}
+		return out, found
+
+	case map[string]interface{}:
+		// Group paths by their first key so that "a.b" and "a.c" are
+		// projected from a together
+		whole := map[string]bool{}
+		rest := map[string][][]string{}
+		for _, path := range keys {
+			if len(path) == 1 {
+				whole[path[0]] = true
+			} else {
+				rest[path[0]] = append(rest[path[0]], path[1:])
+			}
+		}
+
+		out := map[string]interface{}{}
+		for key, child := range v {
+			if whole[key] {
+				out[key] = child
+			} else if paths, ok := rest[key]; ok {
+				if sub, found := project(child, paths); found {
+					out[key] = sub
+				}
+			}
+		}
+		return out, len(out) > 0
+	}
+
+	// A scalar has no fields to select
+	return nil, false
+}
@@ -1,10 +1,21 @@
 package cli
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"net/http"
 	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"text/tabwriter"
+	"time"
 
 	"bscli/pkg/brightsign"
 	"github.com/spf13/cobra"
@@ -23,8 +34,22 @@ func addFileCommands() {
 		Use:   "list [path]",
 		Aliases: []string{"ls"},
 		Short: "List files and directories",
+		Long: `List files and directories. Use --limit and --offset to page through large
+directories; a "Showing X of Y" footer is printed when only part of the
+listing is shown. With --recursive entries are printed as they are found.`,
 		Args:  cobra.MaximumNArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
+			raw, _ := cmd.Flags().GetBool("raw")
+			recursive, _ := cmd.Flags().GetBool("recursive")
+			limit, _ := cmd.Flags().GetInt("limit")
+			offset, _ := cmd.Flags().GetInt("offset")
+			if limit < 0 || offset < 0 {
+				handleError(&usageError{err: fmt.Errorf("--limit and --offset must not be negative")})
+			}
+			if raw && recursive {
+				handleError(&usageError{err: fmt.Errorf("--raw cannot be combined with --recursive")})
+			}
+
 			client, err := getClient()
 			if err != nil {
 				handleError(err)
@@ -35,7 +60,13 @@ func addFileCommands() {
 				path = args[0]
 			}
 
-			raw, _ := cmd.Flags().GetBool("raw")
+			if recursive {
+				if err := listRecursive(client.Storage, path, offset, limit); err != nil {
+					handleError(err)
+				}
+				return
+			}
+
 			options := &brightsign.ListOptions{Raw: raw}
 
 			files, err := client.Storage.ListFiles(path, options)
@@ -43,18 +74,30 @@ func addFileCommands() {
 				handleError(err)
 			}
 
+			// The DWS has no paging of its own, so the listing is sliced here
+			total := len(files)
+			start, end := pageBounds(total, offset, limit)
+			files = files[start:end]
+
 			if jsonOutput {
 				outputJSON(files)
 				return
 			}
 
-			if len(files) == 0 {
+			if total == 0 {
 				fmt.Println("No files found")
 				return
 			}
+			if len(files) == 0 {
+				infof("Showing 0 of %d entries", total)
+				return
+			}
 
-			// Print in table format
-			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			// Print in table format. Rows are colored after alignment since
+			// tabwriter would count escape sequences as width; tableCell keeps
+			// each file on exactly one row so rows and files line up.
+			var table bytes.Buffer
+			w := tabwriter.NewWriter(&table, 0, 0, 2, ' ', 0)
 			fmt.Fprintln(w, "TYPE\tNAME\tSIZE\tMODIFIED")
 			fmt.Fprintln(w, "----\t----\t----\t--------")
 			
@@ -64,19 +107,47 @@ func addFileCommands() {
 					fileType = "dir"
 				}
 				size := formatSize(file.Size)
-				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", fileType, file.Name, size, file.Modified)
+				if raw {
+					// Raw listings only carry names
+					size = "-"
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", fileType, tableCell(file.Name), size, tableCell(file.Modified))
 			}
 			w.Flush()
+
+			lines := strings.Split(strings.TrimSuffix(table.String(), "\n"), "\n")
+			fmt.Println(bold(lines[0]))
+			fmt.Println(lines[1])
+			for i, file := range files {
+				line := lines[2+i]
+				if file.Type == "directory" {
+					line = blue(line)
+				}
+				fmt.Println(line)
+			}
+
+			if len(files) < total {
+				infof("Showing %d of %d entries", len(files), total)
+			}
 		},
 	}
 	listCmd.Flags().Bool("raw", false, "Return raw directory listing")
+	listCmd.Flags().BoolP("recursive", "r", false, "List subdirectories recursively")
+	listCmd.Flags().Int("limit", 0, "Show at most N entries (0 for all)")
+	listCmd.Flags().Int("offset", 0, "Skip the first N entries")
 
 	// Upload command
 	uploadCmd := &cobra.Command{
 		Use:   "upload [local-file] [remote-path]",
 		Aliases: []string{"put", "cp"},
 		Short: "Upload file to player",
-		Args:  cobra.ExactArgs(2),
+		Long: `Upload a local file to the player. A relative remote path is placed under
+/storage/sd/, and uploading to a directory keeps the local file name. With
+--atomic the file is written as .NAME.tmp and renamed once complete.`,
+		Example: `  bscli 192.168.1.100 file upload video.mp4 /storage/sd/video.mp4
+  bscli 192.168.1.100 file upload video.mp4 /storage/usb1/media/
+  bscli 192.168.1.100 file upload autorun.brs autorun.brs`,
+		Args:  uploadArgs,
 		Run: func(cmd *cobra.Command, args []string) {
 			client, err := getClient()
 			if err != nil {
@@ -96,27 +167,208 @@ func addFileCommands() {
 				handleError(fmt.Errorf("local file not found: %s", localPath))
 			}
 
-			if !jsonOutput {
-				fmt.Printf("Uploading %s to %s...\n", localPath, remotePath)
+			// Upload into an existing directory under the local file name
+			if !strings.HasSuffix(remotePath, "/") && isRemoteDirectory(client.Storage, remotePath) {
+				remotePath += "/"
 			}
+			remotePath = brightsign.UploadDestination(localPath, remotePath)
+
+			atomic, _ := cmd.Flags().GetBool("atomic")
+
+			infof("Uploading %s to %s...", localPath, remotePath)
 			
-			err = client.Storage.UploadFile(localPath, remotePath)
+			err = client.Storage.UploadFileWithOptions(localPath, remotePath, &brightsign.UploadOptions{Atomic: atomic})
 			if err != nil {
 				handleError(err)
 			}
 
 			if jsonOutput {
-				outputJSON(map[string]interface{}{
-					"success": true,
-					"action":  "upload",
-					"source":  localPath,
+				outputSuccess("upload", map[string]interface{}{
+					"source":      localPath,
 					"destination": remotePath,
 				})
 			} else {
-				fmt.Println("Upload complete")
+				infof("Upload complete")
+			}
+		},
+	}
+
+	uploadCmd.Flags().Bool("atomic", false, "Upload to a temporary name and rename it when complete, so the player never sees a partial file")
+
+	// Batch upload command
+	uploadBatchCmd := &cobra.Command{
+		Use:   "upload-batch [manifest]",
+		Short: "Upload many files listed in a manifest",
+		Long: `Upload many files concurrently. The manifest is either a JSON array of
+{"local": "...", "remote": "..."} objects or text with one "local -> remote"
+mapping per line (blank lines and lines starting with # are ignored).
+Relative local paths are resolved against the manifest's directory. Every
+entry is attempted even if some uploads fail.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			concurrency, _ := cmd.Flags().GetInt("concurrency")
+			if concurrency < 1 {
+				handleError(&usageError{err: fmt.Errorf("--concurrency must be at least 1")})
+			}
+
+			data, err := os.ReadFile(args[0])
+			if err != nil {
+				handleError(fmt.Errorf("failed to read manifest: %w", err))
+			}
+
+			entries, err := parseUploadManifest(data, filepath.Dir(args[0]))
+			if err != nil {
+				handleError(err)
+			}
+
+			client, err := getClient()
+			if err != nil {
+				handleError(err)
+			}
+
+			results, err := uploadBatch(client.Storage, entries, concurrency)
+			// A dry run printed the uploads instead of sending them
+			if err != nil {
+				handleError(err)
+			}
+
+			failed := 0
+			for _, result := range results {
+				if !result.Success {
+					failed++
+				}
+			}
+
+			if jsonOutput {
+				outputJSON(map[string]interface{}{
+					"total":     len(results),
+					"succeeded": len(results) - failed,
+					"failed":    failed,
+					"results":   results,
+				})
+			} else {
+				for _, result := range results {
+					if result.Success {
+						fmt.Printf("OK      %s -> %s\n", result.Local, result.Remote)
+					} else {
+						fmt.Printf("FAILED  %s -> %s: %s\n", result.Local, result.Remote, result.Error)
+					}
+				}
+				fmt.Printf("%d of %d files uploaded\n", len(results)-failed, len(results))
+			}
+
+			if failed > 0 {
+				handleError(fmt.Errorf("%d of %d uploads failed", failed, len(results)))
 			}
 		},
 	}
+	uploadBatchCmd.Flags().Int("concurrency", 4, "Number of uploads to run in parallel")
+
+	// Sync command
+	syncCmd := &cobra.Command{
+		Use:   "sync [local-dir] [remote-dir]",
+		Short: "Upload new and changed files from a local directory",
+		Long: `One-way sync of a local directory tree to the player. Files missing on the
+player or with a different size are uploaded (with --mtime, also files that are
+newer locally). Missing remote directories are created. With --delete, remote
+files that do not exist locally are removed.
+
+The plan is printed first; with --dry-run nothing is changed.`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			useMtime, _ := cmd.Flags().GetBool("mtime")
+			deleteExtra, _ := cmd.Flags().GetBool("delete")
+			force, _ := cmd.Flags().GetBool("force")
+			concurrency, _ := cmd.Flags().GetInt("concurrency")
+			if concurrency < 1 {
+				handleError(&usageError{err: fmt.Errorf("--concurrency must be at least 1")})
+			}
+
+			localDir := args[0]
+			if info, err := os.Stat(localDir); err != nil || !info.IsDir() {
+				handleError(&usageError{err: fmt.Errorf("local directory not found: %s", localDir)})
+			}
+
+			remoteDir := strings.TrimSuffix(args[1], "/")
+			if !strings.HasPrefix(remoteDir, "/") {
+				remoteDir = "/storage/sd/" + remoteDir
+			}
+
+			client, err := getClient()
+			if err != nil {
+				handleError(err)
+			}
+
+			// A missing remote directory is synced as if it were empty
+			var remote []brightsign.FileInfo
+			remoteExists := true
+			err = client.Storage.Walk(remoteDir, func(file brightsign.FileInfo) error {
+				remote = append(remote, file)
+				return nil
+			})
+			if errors.Is(err, brightsign.ErrNotFound) {
+				remoteExists = false
+			} else if err != nil {
+				handleError(err)
+			}
+
+			plan, err := planSync(localDir, remoteDir, remote, remoteExists, useMtime, deleteExtra)
+			if err != nil {
+				handleError(err)
+			}
+
+			if dryRun || len(plan) == 0 {
+				if jsonOutput {
+					outputJSON(map[string]interface{}{"plan": plan, "executed": false})
+				} else if len(plan) == 0 {
+					fmt.Println("Already in sync")
+				} else {
+					printSyncPlan(plan)
+				}
+				return
+			}
+
+			if !jsonOutput {
+				printSyncPlan(plan)
+			}
+
+			deletes := 0
+			for _, action := range plan {
+				if action.Action == "delete" {
+					deletes++
+				}
+			}
+			if deletes > 0 && !force && !confirm(fmt.Sprintf("Delete %d remote files not present locally?", deletes)) {
+				return
+			}
+
+			results := executeSyncPlan(client.Storage, plan, concurrency)
+
+			failed := 0
+			for _, result := range results {
+				if !result.Success {
+					failed++
+					if !jsonOutput {
+						fmt.Printf("FAILED  %s %s: %s\n", result.Action, result.Remote, result.Error)
+					}
+				}
+			}
+
+			if jsonOutput {
+				outputJSON(map[string]interface{}{"plan": plan, "executed": true, "results": results})
+			} else {
+				fmt.Printf("%d of %d changes applied\n", len(results)-failed, len(results))
+			}
+
+			if failed > 0 {
+				handleError(fmt.Errorf("%d of %d sync changes failed", failed, len(results)))
+			}
+		},
+	}
+	syncCmd.Flags().Bool("mtime", false, "Also upload files whose local modification time is newer")
+	syncCmd.Flags().Bool("delete", false, "Delete remote files that do not exist locally")
+	syncCmd.Flags().BoolP("force", "f", false, "Skip the delete confirmation")
+	syncCmd.Flags().Int("concurrency", 4, "Number of uploads to run in parallel")
 
 	// Download command
 	downloadCmd := &cobra.Command{
@@ -138,35 +390,119 @@ func addFileCommands() {
 				remotePath = "/storage/sd/" + remotePath
 			}
 
-			if !jsonOutput {
-				fmt.Printf("Downloading %s to %s...\n", remotePath, localPath)
+			resume, _ := cmd.Flags().GetBool("resume")
+			parts, _ := cmd.Flags().GetInt("parts")
+
+			if resume && parts > 1 {
+				handleError(&usageError{err: fmt.Errorf("--resume and --parts cannot be combined")})
+			}
+
+			infof("Downloading %s to %s...", remotePath, localPath)
+
+			resumed := false
+			if resume {
+				resumed, err = client.Storage.DownloadFileResume(remotePath, localPath)
+			} else if parts > 1 {
+				err = client.Storage.DownloadFileParallel(remotePath, localPath, parts)
+			} else {
+				err = client.Storage.DownloadFile(remotePath, localPath)
 			}
-			
-			err = client.Storage.DownloadFile(remotePath, localPath)
 			if err != nil {
 				handleError(err)
 			}
 
 			if jsonOutput {
-				outputJSON(map[string]interface{}{
-					"success": true,
-					"action":  "download",
-					"source":  remotePath,
+				outputSuccess("download", map[string]interface{}{
+					"source":      remotePath,
 					"destination": localPath,
+					"resumed":     resumed,
 				})
+			} else if resumed {
+				infof("Download complete (resumed)")
 			} else {
-				fmt.Println("Download complete")
+				infof("Download complete")
 			}
 		},
 	}
+	downloadCmd.Flags().Bool("resume", false, "Continue a partial download of local-file using a Range request")
+	downloadCmd.Flags().Int("parts", 1, "Download in N concurrent byte ranges (falls back to one stream if unsupported)")
+
+	// Cat command
+	catCmd := &cobra.Command{
+		Use:   "cat [remote-path]",
+		Short: "Print a remote file to stdout",
+		Long: `Print the contents of a file on the player to stdout without saving it.
+Files larger than 1 MB or that look binary are refused unless --force is given.`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			force, _ := cmd.Flags().GetBool("force")
+
+			remotePath := args[0]
+			if !strings.HasPrefix(remotePath, "/") {
+				remotePath = "/storage/sd/" + remotePath
+			}
+
+			client, err := getClient()
+			if err != nil {
+				handleError(err)
+			}
+
+			if err := catFile(client.Storage, remotePath, os.Stdout, force); err != nil {
+				handleError(err)
+			}
+		},
+	}
+	catCmd.Flags().BoolP("force", "f", false, "Print large or binary files anyway")
+
+	// Write command
+	writeCmd := &cobra.Command{
+		Use:   "write [remote-path]",
+		Short: "Create a remote file from stdin",
+		Long: `Read stdin and upload it as a file on the player, replacing any existing file:
+
+  echo 'config' | bscli 192.168.1.100 file write /storage/sd/settings.txt`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			remotePath := args[0]
+			if !strings.HasPrefix(remotePath, "/") {
+				remotePath = "/storage/sd/" + remotePath
+			}
+
+			client, err := getClient()
+			if err != nil {
+				handleError(err)
+			}
+
+			if err := client.Storage.UploadReader(os.Stdin, -1, remotePath); err != nil {
+				handleError(err)
+			}
+
+			if jsonOutput {
+				outputSuccess("write", map[string]interface{}{
+					"destination": remotePath,
+				})
+				return
+			}
+
+			fmt.Printf("Wrote %s\n", remotePath)
+		},
+	}
 
 	// Delete command
 	deleteCmd := &cobra.Command{
 		Use:   "delete [path]",
 		Aliases: []string{"rm"},
 		Short: "Delete file or directory",
+		Long: `Delete a file or directory. Some firmware refuses to delete a directory
+that is not empty; --recursive deletes its contents first, after confirming
+how many entries will be removed.`,
+		Example: `  bscli 192.168.1.100 file delete /storage/sd/old.mp4
+  bscli 192.168.1.100 file delete -r /storage/sd/media --force`,
 		Args:  cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
+			force, _ := cmd.Flags().GetBool("force")
+			recursive, _ := cmd.Flags().GetBool("recursive")
+
 			client, err := getClient()
 			if err != nil {
 				handleError(err)
@@ -179,13 +515,34 @@ func addFileCommands() {
 				path = "/storage/sd/" + path
 			}
 
-			force, _ := cmd.Flags().GetBool("force")
+			// Refuse a device root before walking it or asking to confirm
+			if recursive && brightsign.IsStorageDeviceRoot(path) {
+				handleError(fmt.Errorf("refusing to recursively delete storage device root %s; use file format to erase a device", path))
+			}
+
+			if recursive && isRemoteDirectory(client.Storage, path) {
+				count, err := countEntries(client.Storage, path)
+				if err != nil {
+					handleError(err)
+				}
+				if !force {
+					if !confirm(fmt.Sprintf("Delete %s and the %d entries in it?", path, count)) {
+						return
+					}
+				}
+
+				deleted, err := client.Storage.DeleteRecursive(path)
+				if err != nil {
+					handleError(fmt.Errorf("deleted %d of %d entries: %w", deleted, count+1, err))
+				}
+
+				reportSuccess("delete", fmt.Sprintf("Deleted %s (%d entries)", path, deleted),
+					map[string]interface{}{"path": path, "deleted": deleted})
+				return
+			}
+
 			if !force {
-				fmt.Printf("Delete %s? (y/N): ", path)
-				var response string
-				fmt.Scanln(&response)
-				if response != "y" && response != "Y" {
-					fmt.Println("Cancelled")
+				if !confirm(fmt.Sprintf("Delete %s?", path)) {
 					return
 				}
 			}
@@ -195,10 +552,11 @@ func addFileCommands() {
 				handleError(err)
 			}
 
-			fmt.Printf("Deleted %s\n", path)
+			reportSuccess("delete", "Deleted "+path, map[string]interface{}{"path": path})
 		},
 	}
 	deleteCmd.Flags().BoolP("force", "f", false, "Skip confirmation")
+	deleteCmd.Flags().BoolP("recursive", "r", false, "Delete a directory together with its contents")
 
 	// Rename command
 	renameCmd := &cobra.Command{
@@ -225,7 +583,7 @@ func addFileCommands() {
 				handleError(err)
 			}
 
-			fmt.Printf("Renamed to %s\n", newName)
+			reportSuccess("rename", "Renamed to "+newName, map[string]interface{}{"path": oldPath, "newName": newName})
 		},
 	}
 
@@ -252,7 +610,7 @@ func addFileCommands() {
 				handleError(err)
 			}
 
-			fmt.Printf("Created directory %s\n", path)
+			reportSuccess("mkdir", "Created directory "+path, map[string]interface{}{"path": path})
 		},
 	}
 
@@ -260,17 +618,25 @@ func addFileCommands() {
 	formatCmd := &cobra.Command{
 		Use:   "format [device]",
 		Short: "Format storage device (requires autorun disabled)",
-		Args:  cobra.ExactArgs(1),
+		Long: `Format a storage device, deleting all of its data.
+
+The device must be one of the known storage names (` + strings.Join(brightsign.KnownStorageDevices, ", ") + `)
+unless --allow-unknown is given. Formatting fails while autorun is using the
+device, so when an autorun script is found you are asked to confirm that autorun
+was disabled first (control reboot --disable-autorun).`,
+		Args: cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
 			device := args[0]
 
 			force, _ := cmd.Flags().GetBool("force")
+			allowUnknown, _ := cmd.Flags().GetBool("allow-unknown")
+
+			if err := validateFormatDevice(device, allowUnknown); err != nil {
+				handleError(err)
+			}
+
 			if !force {
-				fmt.Printf("WARNING: This will format %s and delete all data. Continue? (y/N): ", device)
-				var response string
-				fmt.Scanln(&response)
-				if response != "y" && response != "Y" {
-					fmt.Println("Cancelled")
+				if !confirmDestructive(fmt.Sprintf("WARNING: This will format %s and delete all data.", device), device) {
 					return
 				}
 			}
@@ -280,20 +646,241 @@ func addFileCommands() {
 				handleError(err)
 			}
 
+			autorun, err := client.Control.IsAutorunEnabled()
+			if err != nil {
+				handleError(err)
+			}
+			if autorun && !force {
+				if !confirm("An autorun script is present; formatting fails while autorun is running. Was autorun disabled?") {
+					return
+				}
+			}
+
 			err = client.Storage.FormatStorage(device)
 			if err != nil {
 				handleError(err)
 			}
 
-			fmt.Printf("Formatted %s\n", device)
+			reportSuccess("format", "Formatted "+device, map[string]interface{}{"device": device})
 		},
 	}
 	formatCmd.Flags().BoolP("force", "f", false, "Skip confirmation")
+	formatCmd.Flags().Bool("allow-unknown", false, "Allow a device name that is not a known storage device")
+
+	// Disk usage command
+	duCmd := &cobra.Command{
+		Use:   "du [path]",
+		Short: "Show the size of a directory and its subdirectories",
+		Long: `Sum the sizes of all files below a directory, listing the total of each
+directory (including the given one). Use --max-depth to limit how deep the
+listing goes, or --summarize to print only the total. Sizes always include
+everything below a directory, whatever the depth shown.`,
+		Example: `  bscli 192.168.1.100 file du /storage/sd/ --max-depth 1
+  bscli 192.168.1.100 file du media --summarize`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			summarize, _ := cmd.Flags().GetBool("summarize")
+			maxDepth, _ := cmd.Flags().GetInt("max-depth")
+			if summarize {
+				if cmd.Flags().Changed("max-depth") {
+					handleError(&usageError{err: fmt.Errorf("--summarize cannot be combined with --max-depth")})
+				}
+				maxDepth = 0
+			}
+
+			client, err := getClient()
+			if err != nil {
+				handleError(err)
+			}
+
+			path := "/storage/sd/"
+			if len(args) > 0 {
+				path = args[0]
+			}
+			if !strings.HasPrefix(path, "/") {
+				path = "/storage/sd/" + path
+			}
+
+			entries, err := diskUsage(client.Storage, path, maxDepth)
+			if err != nil {
+				handleError(err)
+			}
+
+			if jsonOutput {
+				outputJSON(entries)
+				return
+			}
+
+			for _, entry := range entries {
+				fmt.Printf("%10s  %s\n", formatSize(entry.Bytes), entry.Path)
+			}
+		},
+	}
+	duCmd.Flags().BoolP("summarize", "s", false, "Print only the total for the path")
+	duCmd.Flags().Int("max-depth", -1, "List directories at most N levels below the path (-1 for all)")
 
-	fileCmd.AddCommand(listCmd, uploadCmd, downloadCmd, deleteCmd, renameCmd, mkdirCmd, formatCmd)
+	fileCmd.AddCommand(listCmd, uploadCmd, uploadBatchCmd, syncCmd, downloadCmd, catCmd, writeCmd, deleteCmd, renameCmd, mkdirCmd, formatCmd, duCmd)
 	rootCmd.AddCommand(fileCmd)
 }
 
+// validateFormatDevice rejects device names that are not known storage
+// devices unless allowUnknown is set
+func validateFormatDevice(device string, allowUnknown bool) error {
+	if brightsign.IsKnownStorageDevice(device) || (allowUnknown && device != "") {
+		return nil
+	}
+	return &usageError{err: fmt.Errorf("unknown storage device %q (known: %s); use --allow-unknown to format it anyway",
+		device, strings.Join(brightsign.KnownStorageDevices, ", "))}
+}
+
+// countEntries returns the number of files and directories below root
+func countEntries(storage *brightsign.StorageService, root string) (int, error) {
+	count := 0
+	err := storage.Walk(root, func(brightsign.FileInfo) error {
+		count++
+		return nil
+	})
+	return count, err
+}
+
+// isRemoteDirectory reports whether remotePath is an existing directory on
+// the player. Storage device roots always are; anything else is looked up in
+// its parent listing, and lookup failures count as "not a directory".
+func isRemoteDirectory(storage *brightsign.StorageService, remotePath string) bool {
+	parent, name := path.Split(strings.TrimSuffix(remotePath, "/"))
+	if parent == "/storage/" {
+		return true
+	}
+
+	files, err := storage.ListFiles(parent, nil)
+	if err != nil {
+		return false
+	}
+	for _, file := range files {
+		if file.Name == name {
+			return file.Type == "directory"
+		}
+	}
+	return false
+}
+
+// pageBounds returns the slice bounds of the page of total entries that
+// starts at offset and holds at most limit entries. A limit of 0 means no
+// limit.
+func pageBounds(total, offset, limit int) (start, end int) {
+	start = offset
+	if start > total {
+		start = total
+	}
+	end = total
+	if limit > 0 && limit < end-start {
+		end = start + limit
+	}
+	return start, end
+}
+
+// listRecursive walks root and prints each entry of the requested page as it
+// is found rather than buffering the whole tree. Entries past the page are
+// still walked so the total can be reported. With --json only the page is
+// collected.
+func listRecursive(storage *brightsign.StorageService, root string, offset, limit int) error {
+	var page []brightsign.FileInfo
+	total := 0
+
+	if !jsonOutput {
+		fmt.Println(bold(fmt.Sprintf("%-4s  %10s  %-20s  %s", "TYPE", "SIZE", "MODIFIED", "PATH")))
+	}
+
+	err := storage.Walk(root, func(file brightsign.FileInfo) error {
+		index := total
+		total++
+		if index < offset || (limit > 0 && index >= offset+limit) {
+			return nil
+		}
+
+		if jsonOutput {
+			page = append(page, file)
+			return nil
+		}
+
+		fileType, size := "file", formatSize(file.Size)
+		if file.Type == "directory" {
+			fileType, size = "dir", "-"
+		}
+		line := fmt.Sprintf("%-4s  %10s  %-20s  %s", fileType, size, file.Modified, file.Path)
+		if file.Type == "directory" {
+			line = blue(line)
+		}
+		fmt.Println(line)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if jsonOutput {
+		if page == nil {
+			page = []brightsign.FileInfo{}
+		}
+		outputJSON(page)
+		return nil
+	}
+
+	start, end := pageBounds(total, offset, limit)
+	if shown := end - start; shown < total {
+		infof("Showing %d of %d entries", shown, total)
+	}
+	return nil
+}
+
+// duEntry is the total size of the files below a directory
+type duEntry struct {
+	Path  string `json:"path"`
+	Bytes int64  `json:"bytes"`
+}
+
+// diskUsage walks root and returns the total size of root and of each
+// directory at most maxDepth levels below it (all when maxDepth < 0), root
+// first and the rest in walk order
+func diskUsage(storage *brightsign.StorageService, root string, maxDepth int) ([]duEntry, error) {
+	root = strings.TrimSuffix(root, "/")
+	entries := []duEntry{{Path: root}}
+	index := map[string]int{root: 0}
+
+	err := storage.Walk(root, func(file brightsign.FileInfo) error {
+		if file.Type == "directory" {
+			index[file.Path] = len(entries)
+			entries = append(entries, duEntry{Path: file.Path})
+			return nil
+		}
+
+		// Count the file in its directory and every directory above it
+		for dir := path.Dir(file.Path); ; dir = path.Dir(dir) {
+			if i, ok := index[dir]; ok {
+				entries[i].Bytes += file.Size
+			}
+			if dir == root || dir == "/" || dir == "." {
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if maxDepth < 0 {
+		return entries, nil
+	}
+	shown := entries[:0]
+	for _, entry := range entries {
+		if strings.Count(strings.TrimPrefix(entry.Path, root), "/") <= maxDepth {
+			shown = append(shown, entry)
+		}
+	}
+	return shown, nil
+}
+
 // formatSize formats bytes into human-readable size
 func formatSize(size int64) string {
 	const unit = 1024
@@ -306,4 +893,326 @@ func formatSize(size int64) string {
 		exp++
 	}
 	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
-}
\ No newline at end of file
+}
+
+// uploadEntry maps a local file to its destination on the player
+type uploadEntry struct {
+	Local  string `json:"local"`
+	Remote string `json:"remote"`
+}
+
+// uploadResult reports the outcome of one batch upload entry
+type uploadResult struct {
+	Local   string `json:"local"`
+	Remote  string `json:"remote"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// parseUploadManifest parses a JSON or "local -> remote" manifest. Relative
+// local paths are resolved against baseDir.
+func parseUploadManifest(data []byte, baseDir string) ([]uploadEntry, error) {
+	var entries []uploadEntry
+
+	if trimmed := strings.TrimSpace(string(data)); strings.HasPrefix(trimmed, "[") {
+		if err := json.Unmarshal(data, &entries); err != nil {
+			return nil, fmt.Errorf("invalid JSON manifest: %w", err)
+		}
+	} else {
+		for i, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+
+			parts := strings.SplitN(line, "->", 2)
+			if len(parts) != 2 {
+				return nil, fmt.Errorf("manifest line %d: expected \"local -> remote\"", i+1)
+			}
+			entries = append(entries, uploadEntry{
+				Local:  strings.TrimSpace(parts[0]),
+				Remote: strings.TrimSpace(parts[1]),
+			})
+		}
+	}
+
+	for i := range entries {
+		if entries[i].Local == "" || entries[i].Remote == "" {
+			return nil, fmt.Errorf("manifest entry %d: local and remote paths are required", i+1)
+		}
+		if !filepath.IsAbs(entries[i].Local) {
+			entries[i].Local = filepath.Join(baseDir, entries[i].Local)
+		}
+		if !strings.HasPrefix(entries[i].Remote, "/") {
+			entries[i].Remote = "/storage/sd/" + entries[i].Remote
+		}
+	}
+
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("manifest contains no entries")
+	}
+
+	return entries, nil
+}
+
+// uploadBatch uploads every entry using at most concurrency parallel uploads.
+// Results are returned in manifest order. In dry-run mode every upload is
+// logged and brightsign.ErrDryRun is returned.
+func uploadBatch(storage *brightsign.StorageService, entries []uploadEntry, concurrency int) ([]uploadResult, error) {
+	results := make([]uploadResult, len(entries))
+	skipped := make([]bool, len(entries))
+	jobs := make(chan int)
+
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				entry := entries[i]
+				result := uploadResult{Local: entry.Local, Remote: entry.Remote, Success: true}
+				err := storage.UploadFile(entry.Local, entry.Remote)
+				if errors.Is(err, brightsign.ErrDryRun) {
+					skipped[i] = true
+				} else if err != nil {
+					result.Success = false
+					result.Error = err.Error()
+				}
+				results[i] = result
+			}
+		}()
+	}
+
+	for i := range entries {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	for _, dryRun := range skipped {
+		if dryRun {
+			return nil, brightsign.ErrDryRun
+		}
+	}
+	return results, nil
+}
+
+// syncAction is one step of a file sync plan
+type syncAction struct {
+	Action string `json:"action"` // mkdir, upload or delete
+	Local  string `json:"local,omitempty"`
+	Remote string `json:"remote"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// syncResult reports the outcome of one sync action
+type syncResult struct {
+	syncAction
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// planSync compares the local tree under localDir with the remote entries
+// under remoteDir (as listed by Walk) and returns the directories to create,
+// the files to upload and, with deleteExtra, the remote files to delete.
+// remoteExists is false when remoteDir itself is missing.
+func planSync(localDir, remoteDir string, remote []brightsign.FileInfo, remoteExists, useMtime, deleteExtra bool) ([]syncAction, error) {
+	remoteFiles := make(map[string]brightsign.FileInfo)
+	remoteDirs := make(map[string]bool)
+	if remoteExists {
+		remoteDirs[remoteDir] = true
+	}
+	for _, file := range remote {
+		if file.Type == "directory" {
+			remoteDirs[file.Path] = true
+		} else {
+			remoteFiles[file.Path] = file
+		}
+	}
+
+	var mkdirs, uploads, deletes []syncAction
+	localFiles := make(map[string]bool)
+
+	err := filepath.WalkDir(localDir, func(localPath string, entry os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(localDir, localPath)
+		if err != nil {
+			return err
+		}
+		remotePath := remoteDir + "/" + filepath.ToSlash(rel)
+		localFiles[remotePath] = true
+
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+
+		reason := ""
+		if existing, ok := remoteFiles[remotePath]; !ok {
+			reason = "new"
+		} else if existing.Size != info.Size() {
+			reason = "size changed"
+		} else if modified, ok := parseModified(existing.Modified); useMtime && ok && info.ModTime().After(modified) {
+			reason = "newer"
+		}
+		if reason == "" {
+			return nil
+		}
+
+		// Create missing parent directories, outermost first
+		var missing []string
+		for dir := path.Dir(remotePath); !remoteDirs[dir] && strings.HasPrefix(dir, remoteDir); dir = path.Dir(dir) {
+			missing = append([]string{dir}, missing...)
+			remoteDirs[dir] = true
+		}
+		for _, dir := range missing {
+			mkdirs = append(mkdirs, syncAction{Action: "mkdir", Remote: dir})
+		}
+
+		uploads = append(uploads, syncAction{Action: "upload", Local: localPath, Remote: remotePath, Reason: reason})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read local directory: %w", err)
+	}
+
+	if deleteExtra {
+		for remotePath := range remoteFiles {
+			if !localFiles[remotePath] {
+				deletes = append(deletes, syncAction{Action: "delete", Remote: remotePath, Reason: "not present locally"})
+			}
+		}
+		sort.Slice(deletes, func(i, j int) bool { return deletes[i].Remote < deletes[j].Remote })
+	}
+
+	plan := append(mkdirs, uploads...)
+	return append(plan, deletes...), nil
+}
+
+// parseModified parses a remote modification time, which firmware reports
+// either as a date string or as Unix seconds
+func parseModified(s string) (time.Time, bool) {
+	if s == "" {
+		return time.Time{}, false
+	}
+	if seconds, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(seconds, 0), true
+	}
+	for _, layout := range []string{time.RFC3339, time.RFC1123, time.RFC1123Z, time.UnixDate, "2006-01-02 15:04:05"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// printSyncPlan prints the actions of a sync plan
+func printSyncPlan(plan []syncAction) {
+	counts := make(map[string]int)
+	for _, action := range plan {
+		counts[action.Action]++
+		switch action.Action {
+		case "upload":
+			fmt.Printf("  upload  %s -> %s (%s)\n", action.Local, action.Remote, action.Reason)
+		default:
+			fmt.Printf("  %-6s  %s\n", action.Action, action.Remote)
+		}
+	}
+	fmt.Printf("Plan: %d to upload, %d to delete, %d directories to create\n", counts["upload"], counts["delete"], counts["mkdir"])
+}
+
+// executeSyncPlan creates directories, then uploads files with at most
+// concurrency parallel uploads, then deletes. Results are in plan order.
+func executeSyncPlan(storage *brightsign.StorageService, plan []syncAction, concurrency int) []syncResult {
+	results := make([]syncResult, len(plan))
+
+	var entries []uploadEntry
+	var uploadIndex []int
+	for i, action := range plan {
+		results[i] = syncResult{syncAction: action, Success: true}
+		if action.Action == "upload" {
+			entries = append(entries, uploadEntry{Local: action.Local, Remote: action.Remote})
+			uploadIndex = append(uploadIndex, i)
+		}
+	}
+
+	fail := func(i int, err error) {
+		results[i].Success = false
+		results[i].Error = err.Error()
+	}
+
+	for i, action := range plan {
+		if action.Action == "mkdir" {
+			if err := storage.CreateDirectory(action.Remote); err != nil {
+				fail(i, err)
+			}
+		}
+	}
+
+	uploads, err := uploadBatch(storage, entries, concurrency)
+	if err != nil {
+		for _, i := range uploadIndex {
+			fail(i, err)
+		}
+	}
+	for j, result := range uploads {
+		if !result.Success {
+			fail(uploadIndex[j], errors.New(result.Error))
+		}
+	}
+
+	for i, action := range plan {
+		if action.Action == "delete" {
+			if err := storage.DeleteFile(action.Remote); err != nil {
+				fail(i, err)
+			}
+		}
+	}
+
+	return results
+}
+
+// maxCatSize is the largest file printed by file cat without --force
+const maxCatSize = 1 << 20
+
+// catFile copies a remote file to w. Unless force is set, files that are
+// larger than maxCatSize or look binary are refused before anything is written.
+func catFile(storage *brightsign.StorageService, remotePath string, w io.Writer, force bool) error {
+	body, size, err := storage.OpenFile(remotePath)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if force {
+		_, err := io.Copy(w, body)
+		return err
+	}
+
+	if size > maxCatSize {
+		return fmt.Errorf("%s is %s; use --force to print it anyway or 'file download' to save it", remotePath, formatSize(size))
+	}
+
+	// The size may be unknown up front, so read up to the limit before
+	// writing anything
+	data, err := io.ReadAll(io.LimitReader(body, maxCatSize+1))
+	if err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
+	}
+	if len(data) > maxCatSize {
+		return fmt.Errorf("%s is larger than %s; use --force to print it anyway or 'file download' to save it", remotePath, formatSize(maxCatSize))
+	}
+
+	if contentType := http.DetectContentType(data); !strings.HasPrefix(contentType, "text/") {
+		return fmt.Errorf("%s looks like binary data (%s); use --force to print it anyway or 'file download' to save it", remotePath, contentType)
+	}
+
+	_, err = w.Write(data)
+	return err
+}
@@ -1,7 +1,13 @@
 package cli
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"bscli/pkg/brightsign"
 	"github.com/spf13/cobra"
@@ -11,9 +17,34 @@ func addInfoCommands() {
 	infoCmd := &cobra.Command{
 		Use:   "info",
 		Short: "Get player information",
-		Long:  "Commands for retrieving various player information",
+		Long:  "Commands for retrieving various player information. Without a subcommand, prints everything (info all).",
+		Args:  cobra.NoArgs,
 	}
 
+	// Combined status command
+	allCmd := &cobra.Command{
+		Use:   "all",
+		Short: "Get device info, health, time and video mode in one report",
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := getClient()
+			if err != nil {
+				handleError(err)
+			}
+
+			status, err := client.Info.GetFullStatus()
+			if err != nil {
+				handleError(err)
+			}
+
+			if jsonOutput {
+				outputJSON(status)
+				return
+			}
+			printFullStatus(status)
+		},
+	}
+	infoCmd.Run = allCmd.Run
+
 	// Device info command
 	deviceInfoCmd := &cobra.Command{
 		Use:   "device",
@@ -91,17 +122,66 @@ func addInfoCommands() {
 		},
 	}
 
+	// Network info command
+	networkCmd := &cobra.Command{
+		Use:   "network",
+		Short: "Show hostname and network interfaces",
+		Long: `Show the player's hostname and a table of its network interfaces with their
+type, addressing (DHCP or static), IP, netmask, gateway, DNS, MAC and route
+metric. The details come from the device information.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := getClient()
+			if err != nil {
+				handleError(err)
+			}
+
+			info, err := client.Info.GetInfo()
+			if err != nil {
+				handleError(err)
+			}
+
+			if jsonOutput {
+				outputJSON(info.Network)
+				return
+			}
+			renderNetworkInfo(os.Stdout, info.Network)
+		},
+	}
+
 	// Health command
 	healthCmd := &cobra.Command{
 		Use:   "health",
 		Short: "Get player health status",
+		Long: `Get the player's health status. With --expect the command exits 1 unless
+the status matches, for monitoring checks. With --wait-healthy it polls until
+the status matches (running by default), tolerating a player that is still
+booting, and exits 1 if --timeout passes first.`,
+		Example: `  bscli 192.168.1.100 info health --expect running
+  bscli 192.168.1.100 info health --wait-healthy --timeout 5m`,
 		Run: func(cmd *cobra.Command, args []string) {
+			wait, _ := cmd.Flags().GetBool("wait-healthy")
+			expect, _ := cmd.Flags().GetString("expect")
+			timeout, _ := cmd.Flags().GetDuration("timeout")
+			if cmd.Flags().Changed("timeout") && !wait {
+				handleError(&usageError{err: fmt.Errorf("--timeout requires --wait-healthy")})
+			}
+			if wait && expect == "" {
+				expect = "running"
+			}
+
 			client, err := getClient()
 			if err != nil {
 				handleError(err)
 			}
 
-			health, err := client.Info.GetHealth()
+			var health *brightsign.HealthInfo
+			if wait {
+				infof("Waiting for status %s...", expect)
+				health, err = waitForHealth(client, expect, timeout)
+			} else {
+				health, err = client.Info.GetHealth()
+			}
 			if err != nil {
 				handleError(err)
 			}
@@ -109,11 +189,18 @@ func addInfoCommands() {
 			if jsonOutput {
 				outputJSON(health)
 			} else {
-				fmt.Printf("Status: %s\n", health.Status)
+				fmt.Printf("Status: %s\n", healthStatus(health.Status))
 				fmt.Printf("Status Time: %s\n", health.StatusTime)
 			}
+
+			if expect != "" && !strings.EqualFold(health.Status, expect) {
+				exit(exitError)
+			}
 		},
 	}
+	healthCmd.Flags().Bool("wait-healthy", false, "Poll until the status matches --expect (default running)")
+	healthCmd.Flags().String("expect", "", "Exit 1 unless the status is STATUS")
+	healthCmd.Flags().Duration("timeout", time.Minute, "Maximum time to wait with --wait-healthy")
 
 	// Time command
 	timeCmd := &cobra.Command{
@@ -133,11 +220,7 @@ func addInfoCommands() {
 			if jsonOutput {
 				outputJSON(timeInfo)
 			} else {
-				fmt.Printf("Date: %v\n", timeInfo.Date)
-				fmt.Printf("Time: %s\n", timeInfo.Time)
-				if timeInfo.Timezone != "" {
-					fmt.Printf("Timezone: %s\n", timeInfo.Timezone)
-				}
+				printTimeInfo(os.Stdout, timeInfo, "")
 			}
 		},
 	}
@@ -154,7 +237,16 @@ func addInfoCommands() {
 			}
 
 			timezone, _ := cmd.Flags().GetString("timezone")
-			
+			if timezone != "" {
+				zones, err := client.Info.ListTimezones()
+				if err != nil {
+					handleError(err)
+				}
+				if err := brightsign.ValidateTimezone(timezone, zones); err != nil {
+					handleError(&usageError{err: err})
+				}
+			}
+
 			err = client.Info.SetTime(brightsign.TimeInfo{
 				Date:     args[0],
 				Time:     args[1],
@@ -165,7 +257,11 @@ func addInfoCommands() {
 			}
 
 			if jsonOutput {
-				outputJSON(map[string]bool{"success": true})
+				outputSuccess("set-time", map[string]interface{}{
+					"date":     args[0],
+					"time":     args[1],
+					"timezone": timezone,
+				})
 			} else {
 				fmt.Println("Time set successfully")
 			}
@@ -173,6 +269,212 @@ func addInfoCommands() {
 	}
 	setTimeCmd.Flags().String("timezone", "", "Timezone to apply")
 
+	// Sync time command
+	syncTimeCmd := &cobra.Command{
+		Use:   "sync-time",
+		Short: "Set player time from this machine's clock",
+		Long: `Set the player's date, time and timezone from the local machine's clock.
+The timezone is sent by its IANA name, such as Europe/Berlin, and must be one
+the player accepts. With --utc, or when the local timezone has no IANA name,
+the time is sent in UTC instead.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			utc, _ := cmd.Flags().GetBool("utc")
+
+			client, err := getClient()
+			if err != nil {
+				handleError(err)
+			}
+
+			info, err := syncTime(client, systemClock, utc)
+			if err != nil {
+				handleError(err)
+			}
+
+			if jsonOutput {
+				outputSuccess("sync-time", map[string]interface{}{
+					"date":     info.Date,
+					"time":     info.Time,
+					"timezone": info.Timezone,
+				})
+			} else {
+				fmt.Printf("Time set to %s %s %s\n", info.Date, info.Time, info.Timezone)
+			}
+		},
+	}
+	syncTimeCmd.Flags().Bool("utc", false, "Send UTC instead of local time")
+
+	// Device info diff command
+	diffCmd := &cobra.Command{
+		Use:   "diff [host1] [host2]",
+		Short: "Compare device information of two players",
+		Long: `Fetch device information from two players and print the fields that differ
+(firmware, model, network). Serial numbers, uptime and MAC addresses are not
+compared. Both players use the same credentials.
+
+Either name both hosts:    bscli info diff HOST1 HOST2
+or compare against one:    bscli HOST1 info diff --against HOST2`,
+		Args: cobra.MaximumNArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			against, _ := cmd.Flags().GetString("against")
+
+			var hostA, hostB string
+			switch {
+			case len(args) == 2 && against == "":
+				hostA, hostB = args[0], args[1]
+			case len(args) == 0 && host != "" && against != "":
+				hostA, hostB = host, against
+			default:
+				handleError(&usageError{err: fmt.Errorf("specify two hosts, or a host and --against")})
+			}
+
+			infos := make([]*brightsign.DeviceInfo, 2)
+			for i, h := range []string{hostA, hostB} {
+				client, err := getClientFor(h)
+				if err != nil {
+					handleError(err)
+				}
+				info, err := client.Info.GetInfo()
+				if err != nil {
+					handleError(fmt.Errorf("%s: %w", h, err))
+				}
+				infos[i] = info
+			}
+
+			diffs := brightsign.DiffDeviceInfo(infos[0], infos[1])
+
+			if jsonOutput {
+				if diffs == nil {
+					diffs = []brightsign.FieldDiff{}
+				}
+				outputJSON(diffs)
+				return
+			}
+
+			if len(diffs) == 0 {
+				fmt.Printf("No differences between %s and %s\n", hostA, hostB)
+				return
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintf(w, "FIELD\t%s\t%s\n", hostA, hostB)
+			fmt.Fprintln(w, "-----\t----\t----")
+			for _, diff := range diffs {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", diff.Field, valueOrDash(diff.A), valueOrDash(diff.B))
+			}
+			w.Flush()
+		},
+	}
+	diffCmd.Flags().String("against", "", "Second player to compare the current host with")
+
+	// Timezones command
+	timezonesCmd := &cobra.Command{
+		Use:   "timezones",
+		Short: "List timezones accepted by set-time --timezone",
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := getClient()
+			if err != nil {
+				handleError(err)
+			}
+
+			zones, err := client.Info.ListTimezones()
+			if err != nil {
+				handleError(err)
+			}
+
+			if jsonOutput {
+				outputJSON(zones)
+				return
+			}
+
+			for _, zone := range zones {
+				fmt.Println(zone)
+			}
+		},
+	}
+
+	// NTP commands
+	ntpCmd := &cobra.Command{
+		Use:   "ntp",
+		Short: "Manage network time synchronization",
+	}
+
+	ntpGetCmd := &cobra.Command{
+		Use:   "get",
+		Short: "Get NTP configuration",
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := getClient()
+			if err != nil {
+				handleError(err)
+			}
+
+			config, err := client.Info.GetNTP()
+			if err != nil {
+				handleError(err)
+			}
+
+			if jsonOutput {
+				outputJSON(config)
+				return
+			}
+
+			if config.Enabled {
+				fmt.Println("NTP: enabled")
+			} else {
+				fmt.Println("NTP: disabled")
+			}
+			if config.Server != "" {
+				fmt.Printf("Server: %s\n", config.Server)
+			}
+		},
+	}
+
+	ntpSetCmd := &cobra.Command{
+		Use:   "set [server]",
+		Short: "Set NTP server, or disable NTP with --disable",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			disable, _ := cmd.Flags().GetBool("disable")
+
+			var server string
+			if len(args) > 0 {
+				server = args[0]
+			}
+
+			if !disable && server == "" {
+				handleError(&usageError{err: fmt.Errorf("an NTP server is required unless --disable is given")})
+			}
+			if server != "" {
+				if err := brightsign.ValidateNTPServer(server); err != nil {
+					handleError(&usageError{err: err})
+				}
+			}
+
+			client, err := getClient()
+			if err != nil {
+				handleError(err)
+			}
+
+			if err := client.Info.SetNTP(server, !disable); err != nil {
+				handleError(err)
+			}
+
+			if jsonOutput {
+				outputSuccess("ntp-set", map[string]interface{}{
+					"server":  server,
+					"enabled": !disable,
+				})
+			} else if disable {
+				fmt.Println("NTP disabled")
+			} else {
+				fmt.Printf("NTP server set to %s\n", server)
+			}
+		},
+	}
+	ntpSetCmd.Flags().Bool("disable", false, "Disable NTP")
+
+	ntpCmd.AddCommand(ntpGetCmd, ntpSetCmd)
+
 	// Video mode command
 	videoModeCmd := &cobra.Command{
 		Use:   "video-mode",
@@ -221,27 +523,229 @@ func addInfoCommands() {
 				outputJSON(apis)
 			} else {
 				fmt.Println("Available APIs:")
-				// Handle different possible return types
-				switch apiList := apis.(type) {
-				case []string:
-					for _, api := range apiList {
-						fmt.Printf("  - %s\n", api)
-					}
-				case []interface{}:
-					for _, api := range apiList {
-						fmt.Printf("  - %v\n", api)
+				for _, api := range apis {
+					line := api.Path
+					if api.Method != "" {
+						line = fmt.Sprintf("%-6s %s", api.Method, api.Path)
 					}
-				case map[string]interface{}:
-					for key, value := range apiList {
-						fmt.Printf("  - %s: %v\n", key, value)
+					if api.Description != "" {
+						line += " - " + api.Description
 					}
-				default:
-					fmt.Printf("  %v\n", apis)
+					fmt.Printf("  - %s\n", line)
+				}
+				if len(apis) == 0 {
+					fmt.Println("  No APIs reported")
 				}
 			}
 		},
 	}
 
-	infoCmd.AddCommand(deviceInfoCmd, healthCmd, timeCmd, setTimeCmd, videoModeCmd, listAPIsCmd)
+	infoCmd.AddCommand(allCmd, deviceInfoCmd, networkCmd, diffCmd, healthCmd, timeCmd, setTimeCmd, syncTimeCmd, timezonesCmd, ntpCmd, videoModeCmd, listAPIsCmd)
 	rootCmd.AddCommand(infoCmd)
-}
\ No newline at end of file
+}
+
+// clock provides the current time so sync-time can be tested
+type clock interface {
+	Now() time.Time
+}
+
+type realClock struct{}
+
+func (realClock) Now() time.Time { return time.Now() }
+
+var systemClock clock = realClock{}
+
+// syncTime sets the player clock from clk and returns what was sent. The
+// timezone is checked against the zones the player accepts first.
+func syncTime(client *brightsign.Client, clk clock, utc bool) (brightsign.TimeInfo, error) {
+	now := clk.Now()
+	switch {
+	case utc:
+		now = now.UTC()
+	case now.Location() == time.Local:
+		if loc := localZone(); loc != nil {
+			now = now.In(loc)
+		}
+	}
+
+	info := brightsign.TimeInfoFromTime(now)
+	zones, err := client.Info.ListTimezones()
+	if err != nil {
+		return info, err
+	}
+	if err := brightsign.ValidateTimezone(info.Timezone, zones); err != nil {
+		return info, fmt.Errorf("the player does not accept this machine's timezone: %w; use --utc instead", err)
+	}
+	return info, client.Info.SetTime(info)
+}
+
+// localZone returns this machine's timezone as an IANA location, from $TZ or
+// the /etc/localtime link, or nil if it has no IANA name
+func localZone() *time.Location {
+	name := strings.TrimPrefix(os.Getenv("TZ"), ":")
+	if name == "" {
+		target, err := os.Readlink("/etc/localtime")
+		if err != nil {
+			return nil
+		}
+		_, name, _ = strings.Cut(target, "zoneinfo/")
+	}
+	if name == "" {
+		return nil
+	}
+
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil
+	}
+	return loc
+}
+
+// renderNetworkInfo writes the hostname and one table row per interface
+func renderNetworkInfo(out io.Writer, network brightsign.NetworkInfo) {
+	fmt.Fprintf(out, "Hostname: %s\n", valueOrDash(network.Hostname))
+	if len(network.Interfaces) == 0 {
+		fmt.Fprintln(out, "No network interfaces reported")
+		return
+	}
+
+	fmt.Fprintln(out)
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "INTERFACE\tTYPE\tPROTO\tIP\tNETMASK\tGATEWAY\tDNS\tMAC\tMETRIC")
+	for _, iface := range network.Interfaces {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d\n",
+			iface.Name, valueOrDash(iface.Type), valueOrDash(iface.Proto), valueOrDash(iface.IP),
+			valueOrDash(iface.Netmask), valueOrDash(iface.Gateway), valueOrDash(iface.DNS),
+			valueOrDash(iface.MAC), iface.Metric)
+	}
+	w.Flush()
+}
+
+// valueOrDash shows empty values as "-" in tables
+func valueOrDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// healthStatus colors a health status green when the player is healthy and
+// red otherwise
+func healthStatus(status string) string {
+	switch strings.ToLower(status) {
+	case "running", "active", "ok", "healthy":
+		return green(status)
+	}
+	return red(status)
+}
+
+// waitForHealth polls the player's health until its status matches expect,
+// case-insensitively. Like waitForReboot it treats failed requests as a player
+// that is not up yet, except rejected credentials, which no wait will fix.
+func waitForHealth(client *brightsign.Client, expect string, timeout time.Duration) (*brightsign.HealthInfo, error) {
+	deadline := time.Now().Add(timeout)
+	last := "no response"
+
+	for {
+		health, err := client.Info.GetHealth()
+		switch {
+		case errors.Is(err, brightsign.ErrUnauthorized), errors.Is(err, brightsign.ErrForbidden):
+			return nil, err
+		case err != nil:
+			last = err.Error()
+		case strings.EqualFold(health.Status, expect):
+			return health, nil
+		default:
+			last = "status " + health.Status
+		}
+
+		if time.Now().Add(healthPollInterval).After(deadline) {
+			return nil, fmt.Errorf("player did not report status %s within %s (last: %s)", expect, timeout, last)
+		}
+		time.Sleep(healthPollInterval)
+	}
+}
+
+// printFullStatus prints each section of a combined status report, noting the
+// sections that could not be read
+func printFullStatus(status *brightsign.FullStatus) {
+	unavailable := func(section string) {
+		fmt.Printf("  unavailable: %s\n", status.Errors[section])
+	}
+
+	fmt.Println(bold("Device"))
+	if info := status.Device; info != nil {
+		fmt.Printf("  Model: %s\n", info.Model)
+		fmt.Printf("  Serial: %s\n", info.Serial)
+		fmt.Printf("  Firmware Version: %s\n", info.FWVersion)
+		fmt.Printf("  Uptime: %s\n", info.Uptime)
+		if info.Network.Hostname != "" {
+			fmt.Printf("  Hostname: %s\n", info.Network.Hostname)
+		}
+		for _, iface := range info.Network.Interfaces {
+			fmt.Printf("  Interface %s (%s): %s\n", iface.Name, iface.Type, iface.IP)
+		}
+	} else {
+		unavailable("device")
+	}
+
+	fmt.Println(bold("Health"))
+	if health := status.Health; health != nil {
+		fmt.Printf("  Status: %s\n", healthStatus(health.Status))
+		fmt.Printf("  Status Time: %s\n", health.StatusTime)
+	} else {
+		unavailable("health")
+	}
+
+	fmt.Println(bold("Time"))
+	if timeInfo := status.Time; timeInfo != nil {
+		printTimeInfo(os.Stdout, timeInfo, "  ")
+	} else {
+		unavailable("time")
+	}
+
+	fmt.Println(bold("Video Mode"))
+	if mode := status.VideoMode; mode != nil {
+		fmt.Printf("  Resolution: %s\n", mode.Resolution)
+		fmt.Printf("  Frame Rate: %d Hz\n", mode.FrameRate)
+		fmt.Printf("  Scan Method: %s\n", mode.ScanMethod)
+	} else {
+		unavailable("videoMode")
+	}
+}
+
+// printTimeInfo prints the player's date, time and timezone. An epoch date
+// is shown as a date and time in the player's timezone, when it is a known
+// IANA name, or UTC. The timezone's current UTC offset is added when known.
+func printTimeInfo(out io.Writer, info *brightsign.TimeInfo, indent string) {
+	loc, known := time.UTC, false
+	if info.Timezone != "" {
+		if zone, err := time.LoadLocation(info.Timezone); err == nil {
+			loc, known = zone, true
+		}
+	}
+
+	reference := time.Now()
+	if epoch, ok := info.Epoch(); ok {
+		reference = epoch
+		local := epoch.In(loc)
+		fmt.Fprintf(out, "%sDate: %s\n", indent, local.Format("2006-01-02"))
+		if info.Time != "" {
+			fmt.Fprintf(out, "%sTime: %s\n", indent, info.Time)
+		} else {
+			fmt.Fprintf(out, "%sTime: %s\n", indent, local.Format("15:04:05 MST"))
+		}
+	} else {
+		if info.Date != nil && info.Date != "" {
+			fmt.Fprintf(out, "%sDate: %v\n", indent, info.Date)
+		}
+		fmt.Fprintf(out, "%sTime: %s\n", indent, info.Time)
+	}
+
+	switch {
+	case known:
+		fmt.Fprintf(out, "%sTimezone: %s (UTC%s)\n", indent, info.Timezone, reference.In(loc).Format("-07:00"))
+	case info.Timezone != "":
+		fmt.Fprintf(out, "%sTimezone: %s\n", indent, info.Timezone)
+	}
+}
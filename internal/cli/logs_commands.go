@@ -2,7 +2,12 @@ package cli
 
 import (
 	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
 
+	"bscli/pkg/brightsign"
 	"github.com/spf13/cobra"
 )
 
@@ -17,12 +22,26 @@ func addLogsCommands() {
 	getCmd := &cobra.Command{
 		Use:   "get",
 		Short: "Get player serial logs",
+		Long: `Get the player's serial logs. With --raw, or when the global --output-file
+(-O) writes them to a file, the log text is streamed as is.`,
+		Example: `  bscli 192.168.1.100 logs get --raw
+  bscli 192.168.1.100 logs get -O player.log`,
 		Run: func(cmd *cobra.Command, args []string) {
+			raw, _ := cmd.Flags().GetBool("raw")
+
 			client, err := getClient()
 			if err != nil {
 				handleError(err)
 			}
 
+			// Raw output streams the decoded text without JSON quoting
+			if raw || outputFile != "" {
+				if err := client.Logs.StreamLogs(os.Stdout); err != nil {
+					handleError(err)
+				}
+				return
+			}
+
 			logs, err := client.Logs.GetLogs()
 			if err != nil {
 				handleError(err)
@@ -42,6 +61,8 @@ func addLogsCommands() {
 		},
 	}
 
+	getCmd.Flags().Bool("raw", false, "Write the log text to stdout without JSON wrapping")
+
 	// Supervisor logging level commands
 	supervisorCmd := &cobra.Command{
 		Use:   "supervisor",
@@ -65,17 +86,7 @@ func addLogsCommands() {
 			if jsonOutput {
 				outputJSON(level)
 			} else {
-				// Handle different possible level formats
-				switch levelData := level.(type) {
-				case string:
-					fmt.Printf("Supervisor logging level: %s\n", levelData)
-				case float64:
-					fmt.Printf("Supervisor logging level: %.0f\n", levelData)
-				case int:
-					fmt.Printf("Supervisor logging level: %d\n", levelData)
-				default:
-					fmt.Printf("Supervisor logging level: %v\n", level)
-				}
+				fmt.Printf("Supervisor logging level: %s\n", describeSupervisorLevel(level))
 			}
 		},
 	}
@@ -83,34 +94,151 @@ func addLogsCommands() {
 	supervisorSetCmd := &cobra.Command{
 		Use:   "set-level [level]",
 		Short: "Set supervisor logging level (0=error, 1=warn, 2=info, 3=trace)",
-		Args:  cobra.ExactArgs(1),
+		Example: `  bscli 192.168.1.100 logs supervisor set-level info
+  bscli 192.168.1.100 logs supervisor set-level 2`,
+		Args: cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			var level int
-			n, err := fmt.Sscanf(args[0], "%d", &level)
-			if err != nil || n != 1 {
-				handleError(fmt.Errorf("invalid level: must be 0-3"))
+			level, err := parseSupervisorLevel(args[0])
+			if err != nil {
+				handleError(err)
 			}
 
-			if level < 0 || level > 3 {
-				handleError(fmt.Errorf("invalid level: must be 0-3"))
+			client, err := getClient()
+			if err != nil {
+				handleError(err)
 			}
 
+			err = client.Logs.SetSupervisorLoggingLevel(level)
+			if err != nil {
+				handleError(err)
+			}
+
+			if jsonOutput {
+				outputSuccess("supervisor-level", map[string]interface{}{"level": level, "name": supervisorLevelNames[level]})
+				return
+			}
+
+			fmt.Printf("Supervisor logging level set to %d (%s)\n", level, supervisorLevelNames[level])
+		},
+	}
+
+	// Crash dump commands
+	crashesCmd := &cobra.Command{
+		Use:   "crashes",
+		Short: "Manage crash dumps",
+		Long:  "Commands for listing and downloading crash dumps stored in " + brightsign.CrashDumpDir,
+	}
+
+	crashesListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List crash dumps, newest first",
+		Run: func(cmd *cobra.Command, args []string) {
 			client, err := getClient()
 			if err != nil {
 				handleError(err)
 			}
 
-			err = client.Logs.SetSupervisorLoggingLevel(level)
+			dumps, err := client.Logs.GetCrashDumps()
+			if err != nil {
+				handleError(err)
+			}
+
+			if jsonOutput {
+				outputJSON(dumps)
+				return
+			}
+
+			if len(dumps) == 0 {
+				fmt.Println("No crash dumps found")
+				return
+			}
+
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "ID\tTIMESTAMP\tSIZE")
+			fmt.Fprintln(w, "--\t---------\t----")
+			for _, dump := range dumps {
+				fmt.Fprintf(w, "%s\t%s\t%s\n", dump.ID, dump.Timestamp, formatSize(dump.Size))
+			}
+			w.Flush()
+		},
+	}
+
+	crashesDownloadCmd := &cobra.Command{
+		Use:   "download [id] [local-file]",
+		Short: "Download a crash dump (default: ./<id>)",
+		Args:  cobra.RangeArgs(1, 2),
+		Run: func(cmd *cobra.Command, args []string) {
+			id := args[0]
+			localPath := id
+			if len(args) > 1 {
+				localPath = args[1]
+			}
+
+			client, err := getClient()
 			if err != nil {
 				handleError(err)
 			}
 
-			levelNames := []string{"error", "warn", "info", "trace"}
-			fmt.Printf("Supervisor logging level set to %d (%s)\n", level, levelNames[level])
+			if err := client.Logs.DownloadCrashDump(id, localPath); err != nil {
+				handleError(err)
+			}
+
+			if jsonOutput {
+				outputSuccess("download", map[string]interface{}{
+					"source":      brightsign.CrashDumpDir + id,
+					"destination": localPath,
+				})
+			} else {
+				fmt.Printf("Crash dump saved to %s\n", localPath)
+			}
 		},
 	}
 
+	crashesCmd.AddCommand(crashesListCmd, crashesDownloadCmd)
 	supervisorCmd.AddCommand(supervisorGetCmd, supervisorSetCmd)
-	logsCmd.AddCommand(getCmd, supervisorCmd)
+	logsCmd.AddCommand(getCmd, supervisorCmd, crashesCmd)
 	rootCmd.AddCommand(logsCmd)
-}
\ No newline at end of file
+}
+
+// supervisorLevelNames are the supervisor logging levels indexed by number
+var supervisorLevelNames = []string{"error", "warn", "info", "trace"}
+
+// parseSupervisorLevel accepts a level number (0-3) or name (error, warn,
+// info, trace)
+func parseSupervisorLevel(s string) (int, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	for level, name := range supervisorLevelNames {
+		if s == name {
+			return level, nil
+		}
+	}
+
+	level, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, &usageError{err: fmt.Errorf("invalid level %q: must be 0-3 or one of %s", s, strings.Join(supervisorLevelNames, ", "))}
+	}
+	if level < 0 || level >= len(supervisorLevelNames) {
+		return 0, &usageError{err: fmt.Errorf("invalid level %d: must be 0-3", level)}
+	}
+	return level, nil
+}
+
+// describeSupervisorLevel formats a level reported by the player as
+// "2 (info)", whether it was sent as a number, a numeric string or a name
+func describeSupervisorLevel(level interface{}) string {
+	var text string
+	switch v := level.(type) {
+	case float64:
+		text = strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		text = v
+	default:
+		return fmt.Sprint(level)
+	}
+
+	n, err := parseSupervisorLevel(text)
+	if err != nil {
+		return text
+	}
+	return fmt.Sprintf("%d (%s)", n, supervisorLevelNames[n])
+}
@@ -1,10 +1,15 @@
 package cli
 
 import (
+	"errors"
 	"encoding/json"
 	"fmt"
+	"os"
+	"regexp"
+	"sort"
 	"strings"
 
+	"bscli/pkg/brightsign"
 	"github.com/spf13/cobra"
 )
 
@@ -20,12 +25,32 @@ func addRegistryCommands() {
 	getAllCmd := &cobra.Command{
 		Use:   "get-all",
 		Short: "Get entire registry dump",
+		Long: `Print the entire registry as indented JSON. With --flat, print one sorted
+"section/key = value" line per entry instead, which is easier to grep.
+--json always prints the nested JSON.`,
 		Run: func(cmd *cobra.Command, args []string) {
+			flat, _ := cmd.Flags().GetBool("flat")
+
 			client, err := getClient()
 			if err != nil {
 				handleError(err)
 			}
 
+			if flat && !jsonOutput {
+				snapshot, err := client.Registry.GetSnapshot()
+				if err != nil {
+					handleError(err)
+				}
+				if len(snapshot) == 0 {
+					fmt.Println("Registry is empty")
+					return
+				}
+				for _, entry := range flattenRegistry(snapshot) {
+					fmt.Println(formatMatch(entry))
+				}
+				return
+			}
+
 			registry, err := client.Registry.GetAll()
 			if err != nil {
 				handleError(err)
@@ -33,12 +58,69 @@ func addRegistryCommands() {
 
 			if jsonOutput {
 				outputJSON(registry)
+			} else if sections, ok := registry.(map[string]interface{}); ok && len(sections) == 0 {
+				fmt.Println("Registry is empty")
 			} else {
 				data, _ := json.MarshalIndent(registry, "", "  ")
 				fmt.Println(string(data))
 			}
 		},
 	}
+	getAllCmd.Flags().Bool("flat", false, "Print one sorted section/key = value line per entry")
+
+	// List sections
+	sectionsCmd := &cobra.Command{
+		Use:   "sections",
+		Short: "List registry section names",
+		Args:  cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := getClient()
+			if err != nil {
+				handleError(err)
+			}
+
+			sections, err := client.Registry.ListSections()
+			if err != nil {
+				handleError(err)
+			}
+
+			if jsonOutput {
+				outputJSON(sections)
+				return
+			}
+
+			for _, section := range sections {
+				fmt.Println(section)
+			}
+		},
+	}
+
+	// List keys in a section
+	keysCmd := &cobra.Command{
+		Use:   "keys [section]",
+		Short: "List keys in a registry section",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := getClient()
+			if err != nil {
+				handleError(err)
+			}
+
+			keys, err := client.Registry.ListKeys(args[0])
+			if err != nil {
+				handleError(err)
+			}
+
+			if jsonOutput {
+				outputJSON(keys)
+				return
+			}
+
+			for _, key := range keys {
+				fmt.Println(key)
+			}
+		},
+	}
 
 	// Get specific value
 	getCmd := &cobra.Command{
@@ -70,30 +152,71 @@ func addRegistryCommands() {
 		},
 	}
 
+	// Check whether a key exists
+	hasCmd := &cobra.Command{
+		Use:   "has [section] [key]",
+		Short: "Check whether a registry key exists",
+		Long: `Print true and exit 0 if the key exists, or print false and exit 1 if it
+does not. Other failures exit with their usual code.`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			client, err := getClient()
+			if err != nil {
+				handleError(err)
+			}
+
+			exists, err := client.Registry.Exists(args[0], args[1])
+			if err != nil {
+				handleError(err)
+			}
+
+			if jsonOutput {
+				outputJSON(map[string]interface{}{"exists": exists})
+			} else {
+				fmt.Println(exists)
+			}
+
+			if !exists {
+				exit(exitError)
+			}
+		},
+	}
+
 	// Set value
 	setCmd := &cobra.Command{
 		Use:   "set [section] [key] [value]",
 		Short: "Set registry value",
-		Args:  cobra.ExactArgs(3),
+		Long: `Set a registry value. Registry values are stored as strings; use --type to
+validate the value as an int, bool or json before it is written.`,
+		Example: `  bscli 192.168.1.100 registry set networking hostname player-1
+  bscli 192.168.1.100 registry set brightscript debug 1 --type int
+  bscli 192.168.1.100 registry set html motd "Welcome to the lobby"`,
+		Args: registrySetArgs,
 		Run: func(cmd *cobra.Command, args []string) {
+			valueType, _ := cmd.Flags().GetString("type")
+
+			// Validate before connecting so bad input never reaches the player
+			write, err := registryWriter(valueType, args[2])
+			if err != nil {
+				handleError(err)
+			}
+
 			client, err := getClient()
 			if err != nil {
 				handleError(err)
 			}
 
-			err = client.Registry.SetValue(args[0], args[1], args[2])
+			err = write(client.Registry, args[0], args[1])
 			if err != nil {
 				handleError(err)
 			}
 
 			if jsonOutput {
-				result := map[string]interface{}{
+				outputSuccess("set", map[string]interface{}{
 					"section": args[0],
 					"key":     args[1],
 					"value":   args[2],
-					"action":  "set",
-				}
-				outputJSON(result)
+				})
 				return
 			}
 
@@ -101,6 +224,84 @@ func addRegistryCommands() {
 		},
 	}
 
+	setCmd.Flags().String("type", "string", "Value type to validate: string, int, bool or json")
+
+	// Set many values
+	setManyCmd := &cobra.Command{
+		Use:   "set-many [section] [key=value...]",
+		Short: "Set several registry values in one section",
+		Long: `Set several registry values in one section. Pairs are given as key=value
+arguments and/or read from a file with one key=value per line (blank lines
+and lines starting with # are ignored). All pairs are validated before
+anything is written; each value is then written in turn and failures are
+reported per key.`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			section := args[0]
+			fromFile, _ := cmd.Flags().GetString("from-file")
+
+			var lines []string
+			if fromFile != "" {
+				data, err := os.ReadFile(fromFile)
+				if err != nil {
+					handleError(fmt.Errorf("failed to read %s: %w", fromFile, err))
+				}
+				for _, line := range strings.Split(string(data), "\n") {
+					line = strings.TrimSpace(line)
+					if line != "" && !strings.HasPrefix(line, "#") {
+						lines = append(lines, line)
+					}
+				}
+			}
+
+			pairs, err := parseKeyValues(append(lines, args[1:]...))
+			if err != nil {
+				handleError(err)
+			}
+			if len(pairs) == 0 {
+				handleError(&usageError{err: fmt.Errorf("no key=value pairs given")})
+			}
+
+			client, err := getClient()
+			if err != nil {
+				handleError(err)
+			}
+
+			results, err := setRegistryValues(client.Registry, section, pairs)
+			// A dry run printed the writes instead of making them
+			if err != nil {
+				handleError(err)
+			}
+
+			failed := 0
+			for _, result := range results {
+				if !result.Success {
+					failed++
+				}
+			}
+
+			if jsonOutput {
+				outputJSON(map[string]interface{}{
+					"section": section,
+					"results": results,
+				})
+			} else {
+				for _, result := range results {
+					if result.Success {
+						fmt.Printf("Set %s/%s = %s\n", section, result.Key, result.Value)
+					} else {
+						fmt.Printf("FAILED %s/%s: %s\n", section, result.Key, result.Error)
+					}
+				}
+			}
+
+			if failed > 0 {
+				handleError(fmt.Errorf("%d of %d registry values failed to set", failed, len(results)))
+			}
+		},
+	}
+	setManyCmd.Flags().String("from-file", "", "Read key=value lines from a file")
+
 	// Delete value
 	deleteCmd := &cobra.Command{
 		Use:   "delete [section] [key]",
@@ -110,11 +311,7 @@ func addRegistryCommands() {
 			force, _ := cmd.Flags().GetBool("force")
 
 			if !force {
-				fmt.Printf("Delete %s/%s? (y/N): ", args[0], args[1])
-				var response string
-				fmt.Scanln(&response)
-				if response != "y" && response != "Y" {
-					fmt.Println("Cancelled")
+				if !confirm(fmt.Sprintf("Delete %s/%s?", args[0], args[1])) {
 					return
 				}
 			}
@@ -129,7 +326,10 @@ func addRegistryCommands() {
 				handleError(err)
 			}
 
-			fmt.Printf("Deleted %s/%s\n", args[0], args[1])
+			reportSuccess("delete", fmt.Sprintf("Deleted %s/%s", args[0], args[1]), map[string]interface{}{
+				"section": args[0],
+				"key":     args[1],
+			})
 		},
 	}
 	deleteCmd.Flags().BoolP("force", "f", false, "Skip confirmation")
@@ -143,11 +343,7 @@ func addRegistryCommands() {
 			force, _ := cmd.Flags().GetBool("force")
 
 			if !force {
-				fmt.Printf("WARNING: Delete entire section %s? This will remove all keys. (y/N): ", args[0])
-				var response string
-				fmt.Scanln(&response)
-				if response != "y" && response != "Y" {
-					fmt.Println("Cancelled")
+				if !confirm(fmt.Sprintf("WARNING: Delete entire section %s? This will remove all keys.", args[0])) {
 					return
 				}
 			}
@@ -162,7 +358,7 @@ func addRegistryCommands() {
 				handleError(err)
 			}
 
-			fmt.Printf("Deleted section %s\n", args[0])
+			reportSuccess("delete-section", "Deleted section "+args[0], map[string]interface{}{"section": args[0]})
 		},
 	}
 	deleteSectionCmd.Flags().BoolP("force", "f", false, "Skip confirmation")
@@ -187,6 +383,11 @@ func addRegistryCommands() {
 				handleError(err)
 			}
 
+			if jsonOutput {
+				outputJSON(map[string]interface{}{"recoveryUrl": url})
+				return
+			}
+
 			if url != "" {
 				fmt.Printf("Recovery URL: %s\n", url)
 			} else {
@@ -217,7 +418,7 @@ func addRegistryCommands() {
 				handleError(err)
 			}
 
-			fmt.Printf("Recovery URL set to: %s\n", url)
+			reportSuccess("recovery-url", "Recovery URL set to: "+url, map[string]interface{}{"url": url})
 		},
 	}
 
@@ -233,75 +434,435 @@ func addRegistryCommands() {
 				handleError(err)
 			}
 
+			if err := requireVersion(client, "registry flush", brightsign.MinVersionRegistryFlush); err != nil {
+				handleError(err)
+			}
+
 			err = client.Registry.Flush()
 			if err != nil {
 				handleError(err)
 			}
 
-			fmt.Println("Registry flushed to persistent storage")
+			reportSuccess("flush", "Registry flushed to persistent storage", nil)
 		},
 	}
 
+	// Copy command
+	copyCmd := &cobra.Command{
+		Use:   "copy",
+		Short: "Copy registry values from one player to another",
+		Long: `Read a registry section, or the whole registry, from one player and write it
+to another to clone its configuration. Both players use the same
+credentials. Keys named with --exclude, either as KEY in any section or as
+SECTION/KEY, are not copied; use it to keep secrets on the source.
+
+The source defaults to the host given before the command. Values are written
+one at a time; if the destination fails, the keys already written are
+reported. With --dry-run the writes are printed instead of sent.`,
+		Example: `  bscli registry copy --from 192.168.1.100 --to 192.168.1.101 --section networking
+  bscli 192.168.1.100 registry copy --to 192.168.1.101 --exclude password,wifi/passphrase --dry-run`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			from, _ := cmd.Flags().GetString("from")
+			to, _ := cmd.Flags().GetString("to")
+			section, _ := cmd.Flags().GetString("section")
+			exclude, _ := cmd.Flags().GetStringSlice("exclude")
+
+			if from == "" {
+				from = host
+			}
+			switch {
+			case from == "" || to == "":
+				handleError(&usageError{err: fmt.Errorf("specify the source with --from (or a host) and the destination with --to")})
+			case from == to:
+				handleError(&usageError{err: fmt.Errorf("source and destination are the same player")})
+			}
+
+			src, err := getClientFor(from)
+			if err != nil {
+				handleError(err)
+			}
+			dst, err := getClientFor(to)
+			if err != nil {
+				handleError(err)
+			}
+
+			snapshot, err := src.Registry.GetSnapshot()
+			if err != nil {
+				handleError(fmt.Errorf("%s: %w", from, err))
+			}
+			if section != "" {
+				values, ok := snapshot[section]
+				if !ok {
+					handleError(fmt.Errorf("%s: registry section %q: %w", from, section, brightsign.ErrNotFound))
+				}
+				snapshot = brightsign.RegistrySnapshot{section: values}
+			}
+
+			written, total, copyErr := copyRegistry(dst.Registry, excludeRegistryKeys(snapshot, exclude))
+			// A dry run printed the writes instead of making them, so
+			// nothing is reported as copied
+			if errors.Is(copyErr, brightsign.ErrDryRun) {
+				handleError(copyErr)
+			}
+
+			if jsonOutput {
+				result := map[string]interface{}{
+					"from":    from,
+					"to":      to,
+					"written": written,
+					"total":   total,
+				}
+				if copyErr != nil {
+					result["error"] = copyErr.Error()
+				}
+				outputJSON(result)
+			} else {
+				for _, key := range written {
+					fmt.Printf("Copied %s\n", key)
+				}
+				if copyErr == nil {
+					infof("Copied %d registry values from %s to %s", len(written), from, to)
+				}
+			}
+
+			if copyErr != nil {
+				handleError(fmt.Errorf("%s: wrote %d of %d registry values before failing: %w", to, len(written), total, copyErr))
+			}
+		},
+	}
+	copyCmd.Flags().String("from", "", "Player to copy from (default: the host before the command)")
+	copyCmd.Flags().String("to", "", "Player to copy to")
+	copyCmd.Flags().String("section", "", "Copy only this section (default: the whole registry)")
+	copyCmd.Flags().StringSlice("exclude", nil, "Keys not to copy, as KEY or SECTION/KEY (comma-separated)")
+
 	// Search command
 	searchCmd := &cobra.Command{
 		Use:   "search [term]",
 		Short: "Search registry keys and values",
-		Args:  cobra.ExactArgs(1),
+		Long: `Search section names, keys and values for a term. Matching is a case-insensitive
+substring match unless --regex or --case-sensitive is given.`,
+		Args: cobra.ExactArgs(1),
 		Run: func(cmd *cobra.Command, args []string) {
-			searchTerm := strings.ToLower(args[0])
-			ignoreCase := true
+			useRegex, _ := cmd.Flags().GetBool("regex")
+			caseSensitive, _ := cmd.Flags().GetBool("case-sensitive")
+			keysOnly, _ := cmd.Flags().GetBool("keys-only")
+			valuesOnly, _ := cmd.Flags().GetBool("values-only")
+
+			if keysOnly && valuesOnly {
+				handleError(&usageError{err: fmt.Errorf("--keys-only and --values-only are mutually exclusive")})
+			}
+
+			opts := SearchOptions{
+				Term:          args[0],
+				Regex:         useRegex,
+				CaseSensitive: caseSensitive,
+				Scope:         searchAll,
+			}
+			if keysOnly {
+				opts.Scope = searchKeys
+			} else if valuesOnly {
+				opts.Scope = searchValues
+			}
+			if err := opts.compile(); err != nil {
+				handleError(&usageError{err: err})
+			}
 
 			client, err := getClient()
 			if err != nil {
 				handleError(err)
 			}
 
-			registry, err := client.Registry.GetAll()
+			snapshot, err := client.Registry.GetSnapshot()
 			if err != nil {
 				handleError(err)
 			}
 
-			fmt.Printf("Search results for '%s':\n", args[0])
-			found := false
-
-			// Handle different registry formats
-			switch regData := registry.(type) {
-			case map[string]interface{}:
-				for section, sectionData := range regData {
-					sectionLower := strings.ToLower(section)
-					
-					// Check if section data is another map
-					if keys, ok := sectionData.(map[string]interface{}); ok {
-						for key, valueInterface := range keys {
-							keyLower := strings.ToLower(key)
-							value := fmt.Sprintf("%v", valueInterface)
-							valueLower := strings.ToLower(value)
-
-							if (ignoreCase && (strings.Contains(sectionLower, searchTerm) ||
-								strings.Contains(keyLower, searchTerm) ||
-								strings.Contains(valueLower, searchTerm))) ||
-								(!ignoreCase && (strings.Contains(section, args[0]) ||
-								strings.Contains(key, args[0]) ||
-								strings.Contains(value, args[0]))) {
-								
-								fmt.Printf("  %s/%s = %s\n", section, key, value)
-								found = true
-							}
-						}
-					}
-				}
-			default:
-				fmt.Printf("Registry data format not supported for search: %T\n", registry)
+			matches := searchRegistry(snapshot, opts)
+
+			if jsonOutput {
+				outputJSON(matches)
 				return
 			}
 
-			if !found {
+			fmt.Printf("Search results for '%s':\n", args[0])
+			for _, match := range matches {
+				fmt.Printf("  %s\n", formatMatch(match))
+			}
+			if len(matches) == 0 {
 				fmt.Println("  No matches found")
 			}
 		},
 	}
+	searchCmd.Flags().Bool("regex", false, "Treat the term as a regular expression")
+	searchCmd.Flags().Bool("case-sensitive", false, "Match case exactly")
+	searchCmd.Flags().Bool("keys-only", false, "Match only section names and keys")
+	searchCmd.Flags().Bool("values-only", false, "Match only values")
 
-	registryCmd.AddCommand(getAllCmd, getCmd, setCmd, deleteCmd, deleteSectionCmd, 
-		recoveryURLCmd, flushCmd, searchCmd)
+	registryCmd.AddCommand(getAllCmd, sectionsCmd, keysCmd, getCmd, hasCmd, setCmd, setManyCmd, deleteCmd, deleteSectionCmd,
+		recoveryURLCmd, flushCmd, copyCmd, searchCmd)
 	rootCmd.AddCommand(registryCmd)
-}
\ No newline at end of file
+}
+
+// registryWriter parses value as valueType and returns a function that
+// stores it with the matching typed setter
+func registryWriter(valueType, value string) (func(r *brightsign.RegistryService, section, key string) error, error) {
+	switch valueType {
+	case "", "string":
+		return func(r *brightsign.RegistryService, section, key string) error {
+			return r.SetValue(section, key, value)
+		}, nil
+	case "int":
+		n, err := brightsign.ParseRegistryInt(value)
+		if err != nil {
+			return nil, err
+		}
+		return func(r *brightsign.RegistryService, section, key string) error {
+			return r.SetInt(section, key, n)
+		}, nil
+	case "bool":
+		b, err := brightsign.ParseRegistryBool(value)
+		if err != nil {
+			return nil, err
+		}
+		return func(r *brightsign.RegistryService, section, key string) error {
+			return r.SetBool(section, key, b)
+		}, nil
+	case "json":
+		// Stored as typed, so key order, spacing and number formats survive
+		if !json.Valid([]byte(value)) {
+			return nil, fmt.Errorf("value is not valid JSON: %s", value)
+		}
+		return func(r *brightsign.RegistryService, section, key string) error {
+			return r.SetValue(section, key, value)
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown value type %q (use string, int, bool or json)", valueType)
+}
+
+// keyValue is a registry key and the value to write to it
+type keyValue struct {
+	Key   string
+	Value string
+}
+
+// registrySetResult reports the outcome of writing one registry value
+type registrySetResult struct {
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+// parseKeyValues parses key=value tokens, rejecting malformed and duplicate keys
+func parseKeyValues(tokens []string) ([]keyValue, error) {
+	var pairs []keyValue
+	seen := make(map[string]bool)
+
+	for _, token := range tokens {
+		idx := strings.Index(token, "=")
+		if idx <= 0 {
+			return nil, &usageError{err: fmt.Errorf("invalid pair %q: expected key=value", token)}
+		}
+
+		key := strings.TrimSpace(token[:idx])
+		if key == "" {
+			return nil, &usageError{err: fmt.Errorf("invalid pair %q: empty key", token)}
+		}
+		if seen[key] {
+			return nil, &usageError{err: fmt.Errorf("duplicate key %q", key)}
+		}
+		seen[key] = true
+
+		pairs = append(pairs, keyValue{Key: key, Value: token[idx+1:]})
+	}
+
+	return pairs, nil
+}
+
+// setRegistryValues writes each pair, continuing past failures. In dry-run
+// mode every write is logged and brightsign.ErrDryRun is returned.
+func setRegistryValues(registry *brightsign.RegistryService, section string, pairs []keyValue) ([]registrySetResult, error) {
+	results := make([]registrySetResult, 0, len(pairs))
+	var dryRunErr error
+
+	for _, pair := range pairs {
+		result := registrySetResult{Key: pair.Key, Value: pair.Value, Success: true}
+		err := registry.SetValue(section, pair.Key, pair.Value)
+		if errors.Is(err, brightsign.ErrDryRun) {
+			dryRunErr = err
+			continue
+		}
+		if err != nil {
+			result.Success = false
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+
+	return results, dryRunErr
+}
+
+// excludeRegistryKeys returns snapshot without the keys named in exclude,
+// each given as KEY in any section or as SECTION/KEY
+func excludeRegistryKeys(snapshot brightsign.RegistrySnapshot, exclude []string) brightsign.RegistrySnapshot {
+	skip := make(map[string]bool, len(exclude))
+	for _, name := range exclude {
+		if name = strings.TrimSpace(name); name != "" {
+			skip[name] = true
+		}
+	}
+
+	filtered := make(brightsign.RegistrySnapshot, len(snapshot))
+	for section, values := range snapshot {
+		kept := make(map[string]string, len(values))
+		for key, value := range values {
+			if !skip[key] && !skip[section+"/"+key] {
+				kept[key] = value
+			}
+		}
+		if len(kept) > 0 {
+			filtered[section] = kept
+		}
+	}
+	return filtered
+}
+
+// copyRegistry writes snapshot to registry section by section in sorted
+// order. It stops at the first failure and returns the values written so far
+// as SECTION/KEY, together with the number of values it meant to write. In
+// dry-run mode it logs every write and returns brightsign.ErrDryRun.
+func copyRegistry(registry *brightsign.RegistryService, snapshot brightsign.RegistrySnapshot) ([]string, int, error) {
+	sections := make([]string, 0, len(snapshot))
+	total := 0
+	for section, values := range snapshot {
+		sections = append(sections, section)
+		total += len(values)
+	}
+	sort.Strings(sections)
+
+	written := make([]string, 0, total)
+	var dryRunErr error
+	for _, section := range sections {
+		keys, err := registry.SetBatch(section, snapshot[section])
+		for _, key := range keys {
+			written = append(written, section+"/"+key)
+		}
+		if errors.Is(err, brightsign.ErrDryRun) {
+			dryRunErr = err
+			continue
+		}
+		if err != nil {
+			return written, total, err
+		}
+	}
+	return written, total, dryRunErr
+}
+
+// searchScope selects which registry fields a search looks at
+type searchScope int
+
+const (
+	searchAll    searchScope = iota // Sections, keys and values
+	searchKeys                      // Sections and keys
+	searchValues                    // Values
+)
+
+// SearchOptions controls searchRegistry
+type SearchOptions struct {
+	Term          string
+	Regex         bool
+	CaseSensitive bool
+	Scope         searchScope
+
+	match func(string) bool
+}
+
+// compile builds the matcher for the term, reporting an invalid regex
+func (o *SearchOptions) compile() error {
+	if o.Regex {
+		expr := o.Term
+		if !o.CaseSensitive {
+			expr = "(?i)" + expr
+		}
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return fmt.Errorf("invalid regular expression: %w", err)
+		}
+		o.match = re.MatchString
+		return nil
+	}
+
+	if o.CaseSensitive {
+		o.match = func(s string) bool { return strings.Contains(s, o.Term) }
+	} else {
+		term := strings.ToLower(o.Term)
+		o.match = func(s string) bool { return strings.Contains(strings.ToLower(s), term) }
+	}
+	return nil
+}
+
+// Match is a registry entry found by searchRegistry
+type Match struct {
+	Section string `json:"section"`
+	Key     string `json:"key"`
+	Value   string `json:"value"`
+}
+
+// searchRegistry returns the entries of snapshot matching opts, sorted by
+// section and key. An invalid regex matches nothing.
+func searchRegistry(snapshot brightsign.RegistrySnapshot, opts SearchOptions) []Match {
+	if opts.match == nil {
+		if err := opts.compile(); err != nil {
+			return nil
+		}
+	}
+
+	var matches []Match
+	for section, keys := range snapshot {
+		for key, value := range keys {
+			var matched bool
+			switch opts.Scope {
+			case searchKeys:
+				matched = opts.match(section) || opts.match(key)
+			case searchValues:
+				matched = opts.match(value)
+			default:
+				matched = opts.match(section) || opts.match(key) || opts.match(value)
+			}
+			if matched {
+				matches = append(matches, Match{Section: section, Key: key, Value: value})
+			}
+		}
+	}
+
+	sortMatches(matches)
+	return matches
+}
+
+// flattenRegistry returns every entry of snapshot, sorted by section and key
+func flattenRegistry(snapshot brightsign.RegistrySnapshot) []Match {
+	var entries []Match
+	for section, keys := range snapshot {
+		for key, value := range keys {
+			entries = append(entries, Match{Section: section, Key: key, Value: value})
+		}
+	}
+	sortMatches(entries)
+	return entries
+}
+
+// sortMatches orders registry entries by section, then key
+func sortMatches(matches []Match) {
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].Section != matches[j].Section {
+			return matches[i].Section < matches[j].Section
+		}
+		return matches[i].Key < matches[j].Key
+	})
+}
+
+// formatMatch renders a registry entry as a "section/key = value" line
+func formatMatch(m Match) string {
+	return fmt.Sprintf("%s/%s = %s", m.Section, m.Key, m.Value)
+}
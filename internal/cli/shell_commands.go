@@ -0,0 +1,245 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/term"
+)
+
+// shellInput is where the shell reads command lines from. Tests replace it
+// to feed scripted input.
+var shellInput io.Reader = os.Stdin
+
+// shellExit carries the exit code of a failed command back to the prompt
+type shellExit struct {
+	code int
+}
+
+func addShellCommands() {
+	shellCmd := &cobra.Command{
+		Use:   "shell",
+		Short: "Run commands against the player interactively",
+		Long: `Open a prompt that runs bscli commands against this player without
+retyping the host or authenticating again:
+
+  bscli 192.168.1.100 shell
+  bscli 192.168.1.100> info health
+  bscli 192.168.1.100> -j file list /storage/sd/
+
+Global flags given to the shell apply to every command; flags typed at the
+prompt apply to that line only. Arrow keys recall earlier lines on a terminal,
+"history" lists them and "exit", "quit" or Ctrl-D leave the shell.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := runShell(shellInput); err != nil {
+				handleError(err)
+			}
+		},
+	}
+
+	rootCmd.AddCommand(shellCmd)
+}
+
+// runShell reads command lines from in and dispatches them through the
+// command tree until exit or end of input
+func runShell(in io.Reader) error {
+	// Authenticate once up front; a prompted password is kept in the global
+	// flags below. Each line then gets its client from getClient, which
+	// reuses the connection pool and digest challenge unless flags on the
+	// line, such as --dry-run or -u, call for a differently configured one.
+	if _, err := getClient(); err != nil {
+		return err
+	}
+
+	defer func(e func(int)) { exit = e }(exit)
+	exit = func(code int) { panic(shellExit{code: code}) }
+
+	// Global flags as given on the shell command line, restored before each line
+	globals := make(map[string]string)
+	rootCmd.PersistentFlags().VisitAll(func(f *pflag.Flag) {
+		globals[f.Name] = f.Value.String()
+	})
+
+	readLine := newShellReader(in, fmt.Sprintf("bscli %s> ", host))
+	var history []string
+
+	for {
+		line, err := readLine()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed to read command: %w", err)
+		}
+
+		words, err := splitShellLine(line)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			continue
+		}
+		if len(words) == 0 {
+			continue
+		}
+		history = append(history, line)
+
+		switch words[0] {
+		case "exit", "quit":
+			return nil
+		case "history":
+			for i, entry := range history {
+				fmt.Printf("%4d  %s\n", i+1, entry)
+			}
+			continue
+		case "shell":
+			fmt.Fprintln(os.Stderr, "Error: already in a shell")
+			continue
+		}
+
+		resetFlags(rootCmd, globals)
+		runShellLine(words)
+	}
+}
+
+// runShellLine executes one command, returning to the prompt on failure
+func runShellLine(words []string) {
+	// An --output-file given on this line applies to this line only
+	if outputDest == nil {
+		defer restoreOutput()
+	}
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(shellExit); !ok {
+				panic(r)
+			}
+		}
+	}()
+
+	rootCmd.SetArgs(words)
+	if err := rootCmd.Execute(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+	}
+}
+
+// resetFlags returns every command-local flag to its default and the global
+// flags to the values in globals, so flags typed on one line do not leak
+// into the next
+func resetFlags(cmd *cobra.Command, globals map[string]string) {
+	reset := func(f *pflag.Flag, value string) {
+		if slice, ok := f.Value.(pflag.SliceValue); ok {
+			slice.Replace(nil)
+		} else {
+			f.Value.Set(value)
+		}
+		f.Changed = false
+	}
+
+	cmd.LocalNonPersistentFlags().VisitAll(func(f *pflag.Flag) {
+		reset(f, f.DefValue)
+	})
+	if cmd == rootCmd {
+		cmd.PersistentFlags().VisitAll(func(f *pflag.Flag) {
+			reset(f, globals[f.Name])
+		})
+	}
+
+	for _, sub := range cmd.Commands() {
+		resetFlags(sub, globals)
+	}
+}
+
+// newShellReader returns a function reading one line per call. On a terminal
+// it offers line editing and arrow-key history; otherwise lines are read
+// without a prompt, a byte at a time so confirmation prompts of the commands
+// can read the following lines.
+func newShellReader(in io.Reader, prompt string) func() (string, error) {
+	if f, ok := in.(*os.File); ok && term.IsTerminal(int(f.Fd())) {
+		terminal := term.NewTerminal(struct {
+			io.Reader
+			io.Writer
+		}{f, os.Stdout}, prompt)
+
+		return func() (string, error) {
+			state, err := term.MakeRaw(int(f.Fd()))
+			if err != nil {
+				return "", err
+			}
+			defer term.Restore(int(f.Fd()), state)
+			return terminal.ReadLine()
+		}
+	}
+
+	return func() (string, error) {
+		var line []byte
+		buf := make([]byte, 1)
+		for {
+			n, err := in.Read(buf)
+			if n > 0 {
+				if buf[0] == '\n' {
+					return strings.TrimRight(string(line), "\r"), nil
+				}
+				line = append(line, buf[0])
+			}
+			if err == io.EOF && len(line) > 0 {
+				return string(line), nil
+			}
+			if err != nil {
+				return "", err
+			}
+		}
+	}
+}
+
+// splitShellLine splits a command line into words. Single and double quotes
+// group words and a backslash escapes the next character outside single quotes.
+func splitShellLine(line string) ([]string, error) {
+	var words []string
+	var word strings.Builder
+	inWord := false
+	var quote rune
+	escaped := false
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			word.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inWord = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				word.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inWord = true
+		case r == ' ' || r == '\t':
+			if inWord {
+				words = append(words, word.String())
+				word.Reset()
+				inWord = false
+			}
+		default:
+			word.WriteRune(r)
+			inWord = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote", quote)
+	}
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash")
+	}
+	if inWord {
+		words = append(words, word.String())
+	}
+	return words, nil
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+func addStorageCommands() {
+	storageCmd := &cobra.Command{
+		Use:   "storage",
+		Short: "Storage device commands",
+		Long:  "Commands for inspecting storage devices on the player",
+	}
+
+	// Storage info command
+	infoCmd := &cobra.Command{
+		Use:   "info [device]",
+		Short: "Show capacity of a storage device (default: sd)",
+		Long: `Show total, used and free space of a storage device (sd, usb1, ...).
+
+If the player does not report filesystem stats, used space is estimated by
+summing the sizes of the files in the device root and total/free are unknown.`,
+		Args: cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			device := "sd"
+			if len(args) > 0 {
+				device = args[0]
+			}
+
+			client, err := getClient()
+			if err != nil {
+				handleError(err)
+			}
+
+			stats, err := client.Storage.GetStorageInfo(device)
+			if err != nil {
+				handleError(err)
+			}
+
+			if jsonOutput {
+				outputJSON(stats)
+				return
+			}
+
+			fmt.Printf("Device: /storage/%s\n", stats.Device)
+			if stats.Estimated {
+				fmt.Printf("Used: %s (estimated from top-level files)\n", formatSize(stats.UsedBytes))
+				fmt.Println("Total/Free: not reported by player")
+				return
+			}
+
+			if stats.FileSystemType != "" {
+				fmt.Printf("Filesystem: %s\n", stats.FileSystemType)
+			}
+			fmt.Printf("Total: %s\n", formatSize(stats.TotalBytes))
+			fmt.Printf("Used: %s", formatSize(stats.UsedBytes))
+			if stats.TotalBytes > 0 {
+				fmt.Printf(" (%.1f%%)", float64(stats.UsedBytes)*100/float64(stats.TotalBytes))
+			}
+			fmt.Println()
+			fmt.Printf("Free: %s\n", formatSize(stats.FreeBytes))
+			if stats.ReadOnly {
+				fmt.Println("Read-only: yes")
+			}
+		},
+	}
+
+	storageCmd.AddCommand(infoCmd)
+	rootCmd.AddCommand(storageCmd)
+}
@@ -0,0 +1,68 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// Build information, set at build time with
+// -ldflags "-X bscli/internal/cli.Version=... -X bscli/internal/cli.Commit=... -X bscli/internal/cli.BuildDate=..."
+var (
+	Version   = "dev"
+	Commit    = "unknown"
+	BuildDate = "unknown"
+)
+
+func addVersionCommands() {
+	versionCmd := &cobra.Command{
+		Use:   "version",
+		Short: "Show bscli build information",
+		Long: `Show the bscli version, git commit and build date.
+
+No host is needed. When a host is given (bscli [host] version) the player is
+queried as well and its model and firmware version are reported.`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			result := map[string]interface{}{
+				"version":   Version,
+				"commit":    Commit,
+				"buildDate": BuildDate,
+			}
+
+			var player map[string]interface{}
+			if host != "" {
+				client, err := getClient()
+				if err != nil {
+					handleError(err)
+				}
+
+				info, err := client.Info.GetInfo()
+				if err != nil {
+					handleError(err)
+				}
+
+				player = map[string]interface{}{
+					"host":      host,
+					"model":     info.Model,
+					"fwVersion": info.FWVersion,
+				}
+				result["player"] = player
+			}
+
+			if jsonOutput {
+				outputJSON(result)
+				return
+			}
+
+			fmt.Printf("bscli %s\n", Version)
+			fmt.Printf("Commit: %s\n", Commit)
+			fmt.Printf("Built: %s\n", BuildDate)
+			if player != nil {
+				fmt.Printf("Player %s: %s firmware %s\n", host, player["model"], player["fwVersion"])
+			}
+		},
+	}
+
+	rootCmd.AddCommand(versionCmd)
+}
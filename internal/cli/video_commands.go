@@ -2,7 +2,12 @@ package cli
 
 import (
 	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
 
+	"bscli/pkg/brightsign"
 	"github.com/spf13/cobra"
 )
 
@@ -17,13 +22,35 @@ func addVideoCommands() {
 	outputInfoCmd := &cobra.Command{
 		Use:   "output-info [connector] [device]",
 		Short: "Get video output information",
-		Args:  cobra.ExactArgs(2),
+		Example: `  bscli 192.168.1.100 video output-info hdmi 0
+  bscli 192.168.1.100 video output-info --all`,
+		Args: func(cmd *cobra.Command, args []string) error {
+			if all, _ := cmd.Flags().GetBool("all"); all {
+				return cobra.NoArgs(cmd, args)
+			}
+			return videoOutputArgs()(cmd, args)
+		},
 		Run: func(cmd *cobra.Command, args []string) {
+			all, _ := cmd.Flags().GetBool("all")
+
 			client, err := getClient()
 			if err != nil {
 				handleError(err)
 			}
 
+			if all {
+				outputs, err := client.Video.GetAllOutputInfo()
+				if err != nil {
+					handleError(err)
+				}
+				if jsonOutput {
+					outputJSON(outputs)
+					return
+				}
+				printOutputTable(outputs)
+				return
+			}
+
 			info, err := client.Video.GetOutputInfo(args[0], args[1])
 			if err != nil {
 				handleError(err)
@@ -53,7 +80,8 @@ func addVideoCommands() {
 	edidCmd := &cobra.Command{
 		Use:   "edid [connector] [device]",
 		Short: "Get EDID information from connected display",
-		Args:  cobra.ExactArgs(2),
+		Example: `  bscli 192.168.1.100 video edid hdmi 0`,
+		Args:  videoOutputArgs(),
 		Run: func(cmd *cobra.Command, args []string) {
 			client, err := getClient()
 			if err != nil {
@@ -65,25 +93,36 @@ func addVideoCommands() {
 				handleError(err)
 			}
 
-			if jsonOutput {
-				outputJSON(edid)
-				return
+			printEDID(edid)
+		},
+	}
+
+	// Offline EDID decode command
+	decodeEDIDCmd := &cobra.Command{
+		Use:   "decode-edid [hexfile]",
+		Short: "Decode an EDID hex dump locally (no player required)",
+		Long: `Decode a raw EDID blob saved as hex into the same fields reported by 'video edid'.
+No network access is performed, so the host argument may be omitted:
+
+  bscli video decode-edid edid.hex`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			data, err := os.ReadFile(args[0])
+			if err != nil {
+				handleError(fmt.Errorf("failed to read EDID file: %w", err))
 			}
 
-			fmt.Printf("Manufacturer: %s\n", edid.Manufacturer)
-			fmt.Printf("Product: %s\n", edid.Product)
-			fmt.Printf("Serial Number: %s\n", edid.SerialNumber)
-			fmt.Printf("Manufacturing: Week %d of %d\n", edid.WeekOfManufacture, edid.YearOfManufacture)
-			fmt.Printf("EDID Version: %s\n", edid.Version)
-			fmt.Printf("Digital: %v\n", edid.Digital)
-			fmt.Printf("Display Size: %dx%d\n", edid.Width, edid.Height)
-			
-			if len(edid.SupportedModes) > 0 {
-				fmt.Println("Supported Modes:")
-				for _, mode := range edid.SupportedModes {
-					fmt.Printf("  - %s\n", mode)
-				}
+			raw, err := brightsign.ParseEDIDHex(string(data))
+			if err != nil {
+				handleError(err)
 			}
+
+			edid, err := brightsign.DecodeEDID(raw)
+			if err != nil {
+				handleError(err)
+			}
+
+			printEDID(edid)
 		},
 	}
 
@@ -96,7 +135,7 @@ func addVideoCommands() {
 	powerSaveGetCmd := &cobra.Command{
 		Use:   "get [connector] [device]",
 		Short: "Get power save status",
-		Args:  cobra.ExactArgs(2),
+		Args:  videoOutputArgs(),
 		Run: func(cmd *cobra.Command, args []string) {
 			client, err := getClient()
 			if err != nil {
@@ -108,6 +147,11 @@ func addVideoCommands() {
 				handleError(err)
 			}
 
+			if jsonOutput {
+				outputJSON(status)
+				return
+			}
+
 			if status.Enabled {
 				fmt.Printf("Power save is enabled for %s/%s\n", args[0], args[1])
 			} else {
@@ -119,7 +163,7 @@ func addVideoCommands() {
 	powerSaveEnableCmd := &cobra.Command{
 		Use:   "enable [connector] [device]",
 		Short: "Enable power save",
-		Args:  cobra.ExactArgs(2),
+		Args:  videoOutputArgs(),
 		Run: func(cmd *cobra.Command, args []string) {
 			client, err := getClient()
 			if err != nil {
@@ -131,6 +175,11 @@ func addVideoCommands() {
 				handleError(err)
 			}
 
+			if jsonOutput {
+				outputSuccess("power-save", map[string]interface{}{"connector": args[0], "device": args[1], "enabled": true})
+				return
+			}
+
 			fmt.Printf("Power save enabled for %s/%s\n", args[0], args[1])
 		},
 	}
@@ -138,7 +187,7 @@ func addVideoCommands() {
 	powerSaveDisableCmd := &cobra.Command{
 		Use:   "disable [connector] [device]",
 		Short: "Disable power save",
-		Args:  cobra.ExactArgs(2),
+		Args:  videoOutputArgs(),
 		Run: func(cmd *cobra.Command, args []string) {
 			client, err := getClient()
 			if err != nil {
@@ -150,6 +199,11 @@ func addVideoCommands() {
 				handleError(err)
 			}
 
+			if jsonOutput {
+				outputSuccess("power-save", map[string]interface{}{"connector": args[0], "device": args[1], "enabled": false})
+				return
+			}
+
 			fmt.Printf("Power save disabled for %s/%s\n", args[0], args[1])
 		},
 	}
@@ -165,7 +219,8 @@ func addVideoCommands() {
 	modesListCmd := &cobra.Command{
 		Use:   "list [connector] [device]",
 		Short: "List available video modes",
-		Args:  cobra.ExactArgs(2),
+		Example: `  bscli 192.168.1.100 video modes list hdmi 0`,
+		Args:  videoOutputArgs(),
 		Run: func(cmd *cobra.Command, args []string) {
 			client, err := getClient()
 			if err != nil {
@@ -177,6 +232,11 @@ func addVideoCommands() {
 				handleError(err)
 			}
 
+			if jsonOutput {
+				outputJSON(modes)
+				return
+			}
+
 			fmt.Printf("Available video modes for %s/%s:\n", args[0], args[1])
 			for _, mode := range modes {
 				interlaced := ""
@@ -190,13 +250,16 @@ func addVideoCommands() {
 				fmt.Printf("  %s: %dx%d @ %dHz%s%s\n", 
 					mode.Mode, mode.Width, mode.Height, mode.RefreshRate, interlaced, preferred)
 			}
+			if len(modes) == 0 {
+				fmt.Println("  No modes reported")
+			}
 		},
 	}
 
 	modesGetCmd := &cobra.Command{
 		Use:   "current [connector] [device]",
 		Short: "Get current video mode",
-		Args:  cobra.ExactArgs(2),
+		Args:  videoOutputArgs(),
 		Run: func(cmd *cobra.Command, args []string) {
 			client, err := getClient()
 			if err != nil {
@@ -208,6 +271,11 @@ func addVideoCommands() {
 				handleError(err)
 			}
 
+			if jsonOutput {
+				outputJSON(mode)
+				return
+			}
+
 			interlaced := ""
 			if mode.Interlaced {
 				interlaced = " (interlaced)"
@@ -227,8 +295,20 @@ func addVideoCommands() {
 	modesSetCmd := &cobra.Command{
 		Use:   "set [connector] [device] [mode]",
 		Short: "Set video mode",
-		Args:  cobra.ExactArgs(3),
+		Long: `Set the video mode of an output.
+
+The player accepts a mode before the display has switched to it, and a
+display that cannot show it may fall back to another mode. --verify re-reads
+the current mode after --verify-delay and fails, exiting with status 1, if
+it is not the requested one.`,
+		Example: `  bscli 192.168.1.100 video modes list hdmi 0
+  bscli 192.168.1.100 video modes set hdmi 0 1920x1080x60p
+  bscli 192.168.1.100 video modes set hdmi 0 3840x2160x60p --verify`,
+		Args:  videoOutputArgs("a mode"),
 		Run: func(cmd *cobra.Command, args []string) {
+			verify, _ := cmd.Flags().GetBool("verify")
+			verifyDelay, _ := cmd.Flags().GetDuration("verify-delay")
+
 			client, err := getClient()
 			if err != nil {
 				handleError(err)
@@ -239,9 +319,32 @@ func addVideoCommands() {
 				handleError(err)
 			}
 
-			fmt.Printf("Video mode set to %s for %s/%s\n", args[2], args[0], args[1])
+			fields := map[string]interface{}{"connector": args[0], "device": args[1], "mode": args[2]}
+			message := fmt.Sprintf("Video mode set to %s for %s/%s", args[2], args[0], args[1])
+			if !verify {
+				reportSuccess("video-mode", message, fields)
+				return
+			}
+
+			time.Sleep(verifyDelay)
+			current, err := client.Video.GetCurrentMode(args[0], args[1])
+			if err != nil {
+				handleError(fmt.Errorf("video mode was set but could not be verified: %w", err))
+			}
+
+			// A mode that did not take effect is a failure, never a success
+			if !strings.EqualFold(current.Mode, args[2]) {
+				handleError(fmt.Errorf("requested video mode %s for %s/%s but the output reports %s; the display may have fallen back",
+					args[2], args[0], args[1], current.Mode))
+			}
+
+			fields["currentMode"] = current.Mode
+			fields["verified"] = true
+			reportSuccess("video-mode", message+" (verified)", fields)
 		},
 	}
+	modesSetCmd.Flags().Bool("verify", false, "Re-read the current mode after setting it and fail with exit code 1 if it differs")
+	modesSetCmd.Flags().Duration("verify-delay", 2*time.Second, "How long to wait for the display before verifying")
 
 	modesCmd.AddCommand(modesListCmd, modesGetCmd, modesSetCmd)
 
@@ -265,10 +368,74 @@ Note: This is an experimental feature.`,
 				handleError(err)
 			}
 
+			if jsonOutput {
+				outputSuccess("cec", map[string]interface{}{"command": args[0]})
+				return
+			}
+
 			fmt.Printf("CEC command sent: %s\n", args[0])
 		},
 	}
 
-	videoCmd.AddCommand(outputInfoCmd, edidCmd, powerSaveCmd, modesCmd, cecCmd)
+	outputInfoCmd.Flags().Bool("all", false, "Show every video output of the player")
+
+	videoCmd.AddCommand(outputInfoCmd, edidCmd, decodeEDIDCmd, powerSaveCmd, modesCmd, cecCmd)
 	rootCmd.AddCommand(videoCmd)
-}
\ No newline at end of file
+}
+
+// printEDID prints decoded EDID information
+func printEDID(edid *brightsign.EDIDInfo) {
+	if jsonOutput {
+		outputJSON(edid)
+		return
+	}
+
+	fmt.Printf("Manufacturer: %s\n", edid.Manufacturer)
+	fmt.Printf("Product: %s\n", edid.Product)
+	fmt.Printf("Serial Number: %s\n", edid.SerialNumber)
+	fmt.Printf("Manufacturing: Week %d of %d\n", edid.WeekOfManufacture, edid.YearOfManufacture)
+	fmt.Printf("EDID Version: %s\n", edid.Version)
+	fmt.Printf("Digital: %v\n", edid.Digital)
+	fmt.Printf("Display Size: %dx%d\n", edid.Width, edid.Height)
+
+	if len(edid.SupportedModes) > 0 {
+		fmt.Println("Supported Modes:")
+		for _, mode := range edid.SupportedModes {
+			fmt.Printf("  - %s\n", mode)
+		}
+	}
+}
+
+// printOutputTable prints one line per video output with its connection state
+// and current resolution. Outputs that could not be read are listed as
+// warnings on stderr after the table.
+func printOutputTable(outputs []brightsign.VideoOutputInfo) {
+	if len(outputs) == 0 {
+		fmt.Println("No video outputs reported")
+		return
+	}
+
+	var warnings []string
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "OUTPUT\tCONNECTED\tRESOLUTION\tPREFERRED MODE")
+	for _, info := range outputs {
+		if info.Error != "" {
+			warnings = append(warnings, fmt.Sprintf("%s %s: %s", info.Connector, info.Device, info.Error))
+			continue
+		}
+		resolution := "-"
+		if info.Connected {
+			resolution = fmt.Sprintf("%dx%d @ %dHz", info.Width, info.Height, info.RefreshRate)
+		}
+		preferred := info.PreferredMode
+		if preferred == "" {
+			preferred = "-"
+		}
+		fmt.Fprintf(w, "%s %s\t%v\t%s\t%s\n", info.Connector, info.Device, info.Connected, resolution, preferred)
+	}
+	w.Flush()
+
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+}
@@ -0,0 +1,107 @@
+package brightsign
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"time"
+)
+
+// authCacheTTL is how long a persisted challenge is reused. Players expire
+// nonces on their own schedule; a stale nonce costs one extra round trip
+// since the player answers it with a fresh challenge.
+const authCacheTTL = 5 * time.Minute
+
+// unsafeFileChars matches characters not allowed in cache file names
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// authCacheEntry is the persisted form of a digest challenge. It holds no
+// credentials, only what the player sent in its WWW-Authenticate header.
+type authCacheEntry struct {
+	Challenge  map[string]string `json:"challenge"`
+	NonceCount uint32            `json:"nonceCount"`
+	Saved      time.Time         `json:"saved"`
+}
+
+// authCachePath returns the cache file for the player at baseURL
+func authCachePath(dir, baseURL string) string {
+	name := baseURL
+	if u, err := url.Parse(baseURL); err == nil {
+		name = u.Host + u.Path
+	}
+	return filepath.Join(dir, unsafeFileChars.ReplaceAllString(name, "_")+".json")
+}
+
+// loadAuthCache seeds the client with a persisted challenge that is still
+// fresh. Missing, unreadable or expired entries are ignored.
+func (c *Client) loadAuthCache() {
+	data, err := os.ReadFile(c.authCache)
+	if err != nil {
+		return
+	}
+
+	var entry authCacheEntry
+	if err := json.Unmarshal(data, &entry); err != nil || entry.Challenge == nil {
+		return
+	}
+	if time.Since(entry.Saved) > authCacheTTL {
+		c.debugf("auth cache expired: %s", c.authCache)
+		return
+	}
+
+	c.authMu.Lock()
+	c.challenge = entry.Challenge
+	c.nonceCount = entry.NonceCount
+	c.authMu.Unlock()
+	c.debugf("using cached digest challenge from %s", c.authCache)
+}
+
+// updateAuthCache persists the current challenge after a request the player
+// accepted, and removes it after one it rejected
+func (c *Client) updateAuthCache(statusCode int) {
+	if c.authCache == "" {
+		return
+	}
+
+	if statusCode == http.StatusUnauthorized {
+		os.Remove(c.authCache)
+		return
+	}
+
+	c.authMu.Lock()
+	if c.challenge == nil {
+		c.authMu.Unlock()
+		return
+	}
+	entry := authCacheEntry{Challenge: c.challenge, NonceCount: c.nonceCount, Saved: time.Now()}
+	data, err := json.Marshal(entry)
+	c.authMu.Unlock()
+	if err != nil {
+		return
+	}
+
+	// Write to a temporary file first so concurrent invocations never read a partial entry
+	if err := os.MkdirAll(filepath.Dir(c.authCache), 0700); err != nil {
+		c.debugf("auth cache not saved: %v", err)
+		return
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(c.authCache), ".auth-*")
+	if err != nil {
+		c.debugf("auth cache not saved: %v", err)
+		return
+	}
+	_, err = tmp.Write(data)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), c.authCache)
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+		c.debugf("auth cache not saved: %v", err)
+	}
+}
@@ -0,0 +1,120 @@
+package brightsign
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+)
+
+// newDigestServer answers requests carrying a valid digest for *nonce and
+// challenges everything else, counting every request it receives
+func newDigestServer(t *testing.T, nonce *string, requests *int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		if !validDigest(r, "admin", "password", *nonce) {
+			stale := ""
+			if r.Header.Get("Authorization") != "" {
+				stale = `, stale="true"`
+			}
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Digest realm="BrightSign", nonce="%s", qop="auth"%s`, *nonce, stale))
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{}}}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// cachedRequest makes one request with a fresh client using cacheDir
+func cachedRequest(t *testing.T, server *httptest.Server, cacheDir string) {
+	t.Helper()
+	client := NewClient(Config{Host: server.URL[7:], Password: "password", AuthCacheDir: cacheDir})
+
+	resp, err := client.doRequest("GET", "/info/", nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+}
+
+func TestAuthCacheHit(t *testing.T) {
+	nonce := "abc123"
+	var requests int
+	server := newDigestServer(t, &nonce, &requests)
+	cacheDir := t.TempDir()
+
+	cachedRequest(t, server, cacheDir)
+	if requests != 2 {
+		t.Fatalf("Expected challenge and retry on first use, got %d requests", requests)
+	}
+
+	requests = 0
+	cachedRequest(t, server, cacheDir)
+	if requests != 1 {
+		t.Errorf("Expected a single request with a cached challenge, got %d", requests)
+	}
+
+	info, err := os.Stat(authCachePath(cacheDir, "http://"+server.URL[7:]+"/api/v1"))
+	if err != nil {
+		t.Fatalf("Cache file not written: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected cache file mode 0600, got %o", info.Mode().Perm())
+	}
+}
+
+func TestAuthCacheStaleNonce(t *testing.T) {
+	nonce := "abc123"
+	var requests int
+	server := newDigestServer(t, &nonce, &requests)
+	cacheDir := t.TempDir()
+
+	cachedRequest(t, server, cacheDir)
+
+	// The player rotated its nonce; the cached one is answered with a new challenge
+	nonce = "def456"
+	requests = 0
+	cachedRequest(t, server, cacheDir)
+	if requests != 2 {
+		t.Errorf("Expected re-challenge for a stale nonce, got %d requests", requests)
+	}
+
+	requests = 0
+	cachedRequest(t, server, cacheDir)
+	if requests != 1 {
+		t.Errorf("Expected the new nonce to be cached, got %d requests", requests)
+	}
+}
+
+func TestAuthCacheExpired(t *testing.T) {
+	nonce := "abc123"
+	var requests int
+	server := newDigestServer(t, &nonce, &requests)
+	cacheDir := t.TempDir()
+
+	path := authCachePath(cacheDir, "http://"+server.URL[7:]+"/api/v1")
+	data, _ := json.Marshal(authCacheEntry{
+		Challenge: map[string]string{"realm": "BrightSign", "nonce": nonce, "qop": "auth"},
+		Saved:     time.Now().Add(-2 * authCacheTTL),
+	})
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatalf("Failed to write cache: %v", err)
+	}
+
+	cachedRequest(t, server, cacheDir)
+	if requests != 2 {
+		t.Errorf("Expected an expired entry to be ignored, got %d requests", requests)
+	}
+}
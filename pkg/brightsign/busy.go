@@ -0,0 +1,72 @@
+package brightsign
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Players answer with 503 or 409 and a "busy" message while another
+// operation, such as an upload or a reboot, is in progress. Safe requests are
+// retried busyRetries times, waiting busyBackoff and doubling it each time.
+var (
+	busyRetries = 3
+	busyBackoff = time.Second
+)
+
+// maxBusyPeek limits how much of an error body is read to look for the busy
+// message
+const maxBusyPeek = 4096
+
+// peekedBody replays the bytes read by playerBusy before the rest of the body
+type peekedBody struct {
+	io.Reader
+	io.Closer
+}
+
+// isSafeMethod reports whether a request can be repeated without side effects
+func isSafeMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead
+}
+
+// isBusyResponse reports whether status and body are a player's busy answer
+func isBusyResponse(status int, body string) bool {
+	if status != http.StatusServiceUnavailable && status != http.StatusConflict {
+		return false
+	}
+	return strings.Contains(strings.ToLower(body), "busy")
+}
+
+// playerBusy reports whether resp says the player is busy. The body is left
+// readable for the caller either way.
+func playerBusy(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusServiceUnavailable && resp.StatusCode != http.StatusConflict {
+		return false
+	}
+	peek, _ := io.ReadAll(io.LimitReader(resp.Body, maxBusyPeek))
+	resp.Body = &peekedBody{Reader: io.MultiReader(bytes.NewReader(peek), resp.Body), Closer: resp.Body}
+	return isBusyResponse(resp.StatusCode, string(peek))
+}
+
+// retryBusy repeats a safe request while the player reports it is busy, with
+// exponential backoff. When the player stays busy the last response is
+// returned; its APIError matches ErrPlayerBusy.
+func (c *Client) retryBusy(method string, send func() (*http.Response, error)) (*http.Response, error) {
+	resp, err := send()
+	if !isSafeMethod(method) {
+		return resp, err
+	}
+
+	delay := busyBackoff
+	for attempt := 1; err == nil && attempt <= busyRetries && playerBusy(resp); attempt++ {
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		c.debugf("player busy, retrying in %s (%d/%d)", delay, attempt, busyRetries)
+		time.Sleep(delay)
+		delay *= 2
+		resp, err = send()
+	}
+	return resp, err
+}
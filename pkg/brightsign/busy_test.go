@@ -0,0 +1,107 @@
+package brightsign
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newBusyServer answers the first busyCount requests with a busy 503 and
+// the rest with device info, counting the requests it receives
+func newBusyServer(t *testing.T, busyCount int, requests *int) *httptest.Server {
+	t.Helper()
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		*requests++
+		n := *requests
+		mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		if n <= busyCount {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			w.Write([]byte(`{"message":"Player is busy, try again later"}`))
+			return
+		}
+		w.Write([]byte(`{"data":{"result":{"model":"XD1034","serial":"ABC123"}}}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+// fastBusyBackoff shortens the busy backoff for the duration of a test
+func fastBusyBackoff(t *testing.T) {
+	t.Helper()
+	saved := busyBackoff
+	busyBackoff = time.Millisecond
+	t.Cleanup(func() { busyBackoff = saved })
+}
+
+func TestBusyRetrySucceeds(t *testing.T) {
+	fastBusyBackoff(t)
+	var requests int
+	server := newBusyServer(t, 2, &requests)
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	info, err := client.Info.GetInfo()
+	if err != nil {
+		t.Fatalf("Expected the request to succeed once the player is free, got %v", err)
+	}
+	if info.Serial != "ABC123" {
+		t.Errorf("Expected serial ABC123, got %q", info.Serial)
+	}
+	if requests != 3 {
+		t.Errorf("Expected 2 busy answers and 1 success, got %d requests", requests)
+	}
+}
+
+func TestBusyRetryGivesUp(t *testing.T) {
+	fastBusyBackoff(t)
+	var requests int
+	server := newBusyServer(t, 100, &requests)
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	_, err := client.Info.GetInfo()
+	if !errors.Is(err, ErrPlayerBusy) {
+		t.Fatalf("Expected ErrPlayerBusy, got %v", err)
+	}
+	if requests != busyRetries+1 {
+		t.Errorf("Expected %d requests, got %d", busyRetries+1, requests)
+	}
+}
+
+func TestBusyRetrySkipsUnsafeRequests(t *testing.T) {
+	fastBusyBackoff(t)
+	var requests int
+	server := newBusyServer(t, 100, &requests)
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	err := client.Control.Reboot(nil)
+	if !errors.Is(err, ErrPlayerBusy) {
+		t.Fatalf("Expected ErrPlayerBusy, got %v", err)
+	}
+	if requests != 1 {
+		t.Errorf("Expected a mutating request to be sent once, got %d requests", requests)
+	}
+}
+
+func TestBusyRequiresBusyMessage(t *testing.T) {
+	if isBusyResponse(http.StatusServiceUnavailable, `{"message":"internal failure"}`) {
+		t.Error("Expected a 503 without a busy message not to count as busy")
+	}
+	if isBusyResponse(http.StatusInternalServerError, "busy") {
+		t.Error("Expected a 500 not to count as busy")
+	}
+	if !isBusyResponse(http.StatusConflict, "Device BUSY") {
+		t.Error("Expected a 409 with a busy message to count as busy")
+	}
+}
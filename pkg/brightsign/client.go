@@ -6,16 +6,28 @@ import (
 	"bytes"
 	"crypto/md5"
 	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"io"
 	"math/rand"
+	"net"
 	"net/http"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 )
 
+// DefaultUsername is the DWS account used when Config.Username is empty
+const DefaultUsername = "admin"
+
+// idleConnsPerHost is the number of idle keep-alive connections kept per
+// player; enough for the upload and batch worker pools
+const idleConnsPerHost = 16
+
 // Client is the main client for interacting with a BrightSign player's DWS API
 type Client struct {
 	host     string
@@ -23,7 +35,37 @@ type Client struct {
 	password string
 	client   *http.Client
 	debug    bool
+	trace    bool
+	logger   io.Writer
 	baseURL  string
+	preAuth  bool
+	dryRun   bool
+
+	// allowBasic answers a Basic challenge instead of failing
+	allowBasic bool
+
+	// passwordPrompt asks for a new password after the player rejects one
+	passwordPrompt func() (string, error)
+
+	// dumpPath receives the raw body of each response, "" if disabled
+	dumpPath string
+
+	// noDecompress keeps gzip-encoded download and log bodies as received
+	noDecompress bool
+
+	// configErr is a configuration problem found by NewClient, such as an
+	// unreadable client certificate. Every request fails with it.
+	configErr error
+
+	// Cached digest challenge used to authenticate requests on the first attempt
+	authMu     sync.Mutex
+	challenge  map[string]string
+	nonceCount uint32
+	authCache  string // File the challenge is persisted to, "" if disabled
+
+	// Firmware version, read once by APIVersion
+	versionMu sync.Mutex
+	version   string
 
 	// Services
 	Info        *InfoService
@@ -39,11 +81,65 @@ type Client struct {
 // Config contains configuration options for the client
 type Config struct {
 	Host     string
-	Username string // Default is "admin"
+	Username string // Default is DefaultUsername
 	Password string
 	Debug    bool
 	Timeout  time.Duration
-	Insecure bool // Skip TLS certificate verification for local certificates
+	Insecure bool // Skip TLS certificate verification for local certificates (implies HTTPS)
+
+	// HTTPS connects over TLS with normal certificate verification
+	HTTPS bool
+
+	// RootCAs verifies the player's certificate against these CAs instead of
+	// the system roots. Setting it implies HTTPS.
+	RootCAs *x509.CertPool
+
+	// ClientCert and ClientKey are PEM files with a client certificate and
+	// its private key, presented to players that require mutual TLS. The key
+	// may be omitted when ClientCert contains both.
+	ClientCert string
+	ClientKey  string
+
+	// Trace logs full request/response headers, digest challenges and
+	// truncated bodies. Credentials are redacted.
+	Trace bool
+
+	// Logger receives debug and trace output. Default is os.Stderr.
+	Logger io.Writer
+
+	// Port overrides the default HTTP(S) port. Ignored if Host already has a port.
+	Port int
+
+	// BasePath is a path prefix for players proxied behind another server,
+	// e.g. "/custom" targets http://host/custom/api/v1
+	BasePath string
+
+	// PreAuthenticate obtains the digest challenge with a cheap request before
+	// uploads so that large bodies are only transmitted once
+	PreAuthenticate bool
+
+	// DryRun logs mutating requests to Logger instead of sending them and
+	// fails them with ErrDryRun. Reads are still sent.
+	DryRun bool
+
+	// AuthCacheDir persists the digest challenge per player in this directory
+	// so that a later client, such as the next CLI invocation, authenticates
+	// on its first request. Only the challenge is stored, never credentials.
+	AuthCacheDir string
+
+	// NoDecompress saves downloads and logs the player sends gzip-encoded as
+	// received instead of decompressing them
+	NoDecompress bool
+
+	// AllowBasic answers a Basic challenge from players that do not offer
+	// digest authentication. Basic sends the password unencrypted, so over
+	// plain HTTP anyone on the network can read it.
+	AllowBasic bool
+
+	// DumpResponse writes the raw body of each response to this file as it
+	// is read, replacing it every time, for offline debugging of unexpected
+	// responses
+	DumpResponse string
 }
 
 // Response is the standard API response wrapper
@@ -55,40 +151,63 @@ type Response struct {
 
 // NewClient creates a new BrightSign DWS API client
 func NewClient(config Config) *Client {
-	if config.Username == "" {
-		config.Username = "admin"
+	// An empty user would be sent as username="" in the digest header
+	if strings.TrimSpace(config.Username) == "" {
+		config.Username = DefaultUsername
 	}
 	if config.Timeout == 0 {
 		config.Timeout = 30 * time.Second
 	}
-
-	// Create HTTP client with optional insecure TLS
-	transport := &http.Transport{}
-	if config.Insecure {
-		transport.TLSClientConfig = &tls.Config{
-			InsecureSkipVerify: true,
-		}
+	if config.Logger == nil {
+		config.Logger = os.Stderr
 	}
 
+	tlsConfig, configErr := newTLSConfig(config)
+
+	// One transport per client so every service shares its idle connections
 	httpClient := &http.Client{
 		Timeout:   config.Timeout,
-		Transport: transport,
+		Transport: newTransport(tlsConfig),
+	}
+	if config.NoDecompress {
+		httpClient.Transport.(*http.Transport).DisableCompression = true
+	}
+
+	// Accept hosts pasted as URLs, e.g. "https://192.168.1.100:8080/"
+	scheme, hostPort, path := splitHostURL(config.Host)
+	config.Host = hostPort
+	if config.BasePath == "" {
+		config.BasePath = path
 	}
 
-	// Determine protocol based on whether insecure mode is enabled
-	// Insecure mode typically means HTTPS with locally signed certs
+	// Any TLS setting selects HTTPS; insecure mode typically means HTTPS
+	// with locally signed certs
 	protocol := "http"
-	if config.Insecure {
+	if config.HTTPS || tlsConfig != nil || scheme == "https" {
 		protocol = "https"
 	}
 
 	c := &Client{
-		host:     config.Host,
-		username: config.Username,
-		password: config.Password,
-		client:   httpClient,
-		debug:    config.Debug,
-		baseURL:  fmt.Sprintf("%s://%s/api/v1", protocol, config.Host),
+		host:      config.Host,
+		username:  config.Username,
+		password:  config.Password,
+		client:    httpClient,
+		debug:     config.Debug,
+		trace:     config.Trace,
+		logger:    config.Logger,
+		baseURL:   buildBaseURL(protocol, config.Host, config.Port, config.BasePath),
+		preAuth:   config.PreAuthenticate,
+		dryRun:    config.DryRun,
+		configErr: configErr,
+
+		noDecompress: config.NoDecompress,
+		allowBasic:   config.AllowBasic,
+		dumpPath:     config.DumpResponse,
+	}
+
+	if config.AuthCacheDir != "" {
+		c.authCache = authCachePath(config.AuthCacheDir, c.baseURL)
+		c.loadAuthCache()
 	}
 
 	// Initialize services
@@ -104,6 +223,120 @@ func NewClient(config Config) *Client {
 	return c
 }
 
+// splitHostURL separates an optional scheme and path from a host value. Plain
+// hosts and host:port values are returned unchanged with an empty scheme.
+func splitHostURL(host string) (scheme, hostPort, path string) {
+	host = strings.TrimSpace(host)
+	idx := strings.Index(host, "://")
+	if idx == -1 {
+		return "", strings.TrimRight(host, "/"), ""
+	}
+
+	scheme = strings.ToLower(host[:idx])
+	hostPort = host[idx+3:]
+	if slash := strings.Index(hostPort, "/"); slash != -1 {
+		hostPort, path = hostPort[:slash], hostPort[slash:]
+	}
+
+	// A pasted API URL already ends in /api/v1
+	path = strings.TrimRight(path, "/")
+	path = strings.TrimSuffix(path, "/api/v1")
+
+	return scheme, hostPort, path
+}
+
+// buildBaseURL joins the API root URL from its parts. A port in host takes
+// precedence over port so it is never appended twice.
+func buildBaseURL(protocol, host string, port int, basePath string) string {
+	if port != 0 {
+		if _, _, err := net.SplitHostPort(host); err != nil {
+			host = net.JoinHostPort(strings.Trim(host, "[]"), strconv.Itoa(port))
+		}
+	}
+
+	basePath = strings.Trim(basePath, "/")
+	if basePath != "" {
+		basePath = "/" + basePath
+	}
+
+	return fmt.Sprintf("%s://%s%s/api/v1", protocol, host, basePath)
+}
+
+// LoadClientCertificate reads a client certificate and its private key from
+// PEM files for mutual TLS. keyFile may be empty when certFile holds both.
+func LoadClientCertificate(certFile, keyFile string) (tls.Certificate, error) {
+	if keyFile == "" {
+		keyFile = certFile
+	}
+	cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("failed to load client certificate %s with key %s: %w", certFile, keyFile, err)
+	}
+	return cert, nil
+}
+
+// newTLSConfig builds the TLS settings requested by config, or returns nil
+// when the defaults apply
+func newTLSConfig(config Config) (*tls.Config, error) {
+	if !config.Insecure && config.RootCAs == nil && config.ClientCert == "" && config.ClientKey == "" {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: config.Insecure,
+		RootCAs:            config.RootCAs,
+	}
+	if config.ClientCert == "" && config.ClientKey != "" {
+		return tlsConfig, fmt.Errorf("client key %s given without a client certificate", config.ClientKey)
+	}
+	if config.ClientCert != "" {
+		cert, err := LoadClientCertificate(config.ClientCert, config.ClientKey)
+		if err != nil {
+			return tlsConfig, err
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+	}
+	return tlsConfig, nil
+}
+
+// newTransport returns a transport tuned for many sequential requests to a
+// single player, with optional TLS settings
+func newTransport(tlsConfig *tls.Config) *http.Transport {
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   10 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          idleConnsPerHost,
+		MaxIdleConnsPerHost:   idleConnsPerHost,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+		TLSClientConfig:       tlsConfig,
+	}
+	return transport
+}
+
+// Close releases the client's idle connections. The client remains usable;
+// a later request opens a new connection and reuses the digest challenge.
+func (c *Client) Close() error {
+	c.client.CloseIdleConnections()
+	return nil
+}
+
+// DoRaw sends a request to an arbitrary DWS endpoint and returns the response
+// as is, for endpoints or headers the typed services do not cover. path is
+// relative to the API root, e.g. "/info/". body is encoded as JSON when not
+// nil. Digest authentication is handled as for every other call, but the
+// status is not checked. The caller must close the response body.
+func (c *Client) DoRaw(method, path string, body interface{}) (*http.Response, error) {
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	return c.doRequest(method, path, body)
+}
+
 // doRequest performs an HTTP request with digest authentication if needed
 func (c *Client) doRequest(method, path string, body interface{}) (*http.Response, error) {
 	url := c.baseURL + path
@@ -122,6 +355,24 @@ func (c *Client) doRequest(method, path string, body interface{}) (*http.Respons
 
 // doRequestWithBody performs an HTTP request with a pre-formatted body
 func (c *Client) doRequestWithBody(method, url string, body io.Reader, contentType string) (*http.Response, error) {
+	return c.doRequestWithHeaders(method, url, body, contentType, nil)
+}
+
+// doRequestWithHeaders performs an HTTP request with a pre-formatted body and
+// extra headers, which are sent on the authenticated retry as well. Safe
+// requests the player answers as busy are retried with backoff.
+func (c *Client) doRequestWithHeaders(method, url string, body io.Reader, contentType string, header http.Header) (*http.Response, error) {
+	return c.retryBusy(method, func() (*http.Response, error) {
+		return c.sendRequest(method, url, body, contentType, header)
+	})
+}
+
+// sendRequest performs one request, answering an authentication challenge
+func (c *Client) sendRequest(method, url string, body io.Reader, contentType string, header http.Header) (*http.Response, error) {
+	if c.configErr != nil {
+		return nil, c.configErr
+	}
+
 	req, err := http.NewRequest(method, url, body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
@@ -130,81 +381,204 @@ func (c *Client) doRequestWithBody(method, url string, body io.Reader, contentTy
 	if contentType != "" && body != nil {
 		req.Header.Set("Content-Type", contentType)
 	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	c.debugf("%s %s", method, url)
+
+	if c.dryRun && isMutating(method, req.URL.Path) {
+		return c.dryRunResponse(req, body, contentType)
+	}
 
-	if c.debug {
-		fmt.Fprintf(os.Stderr, "DEBUG: %s %s\n", method, url)
+	// Reuse a previously received challenge so the body is only sent once
+	if authHeader := c.authorization(method, req.URL.RequestURI()); authHeader != "" {
+		req.Header.Set("Authorization", authHeader)
 	}
 
-	// First attempt without authentication
+	// First attempt, authenticated only if a challenge is cached
+	c.traceRequest(req, body)
 	resp, err := c.client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("request failed: %w", err)
+		return nil, fmt.Errorf("request failed: %w", redactError(err))
 	}
+	c.traceResponse(resp)
 
 	// If we get 401, handle digest authentication
 	if resp.StatusCode == http.StatusUnauthorized {
 		wwwAuth := resp.Header.Get("WWW-Authenticate")
+		// Drain the challenge body so the connection can be reused for the retry
+		io.Copy(io.Discard, resp.Body)
 		resp.Body.Close()
 
-		if !strings.HasPrefix(wwwAuth, "Digest") {
-			return nil, fmt.Errorf("server requires digest authentication but sent: %s", wwwAuth)
+		switch {
+		case strings.HasPrefix(wwwAuth, "Digest"):
+			// Parse digest challenge and cache it for subsequent requests
+			params := parseDigestAuth(wwwAuth)
+			c.tracef("digest challenge: realm=%q nonce=%q qop=%q opaque=%q", params["realm"], params["nonce"], params["qop"], params["opaque"])
+			c.setChallenge(params)
+		case strings.HasPrefix(wwwAuth, "Basic") && c.allowBasic:
+			c.tracef("basic challenge: %s", wwwAuth)
+			c.setChallenge(map[string]string{"scheme": "Basic"})
+		case strings.HasPrefix(wwwAuth, "Basic"):
+			return nil, fmt.Errorf("%w: %w", ErrBasicAuthDisabled, ErrUnauthorized)
+		default:
+			return nil, fmt.Errorf("server requires digest authentication but sent: %s: %w", wwwAuth, ErrUnauthorized)
 		}
 
-		// Parse digest challenge
-		authParams := parseDigestAuth(wwwAuth)
-
-		// Create new request with same body
-		var newBody io.Reader
-		if body != nil {
-			// Need to re-read the body
-			if seeker, ok := body.(io.Seeker); ok {
-				seeker.Seek(0, io.SeekStart)
-				newBody = body
-			} else if bodyReader, ok := body.(*bytes.Reader); ok {
-				bodyReader.Seek(0, io.SeekStart)
-				newBody = bodyReader
-			} else {
-				return nil, fmt.Errorf("cannot retry request with non-seekable body")
+		// Retry with authentication. A 401 now means the credentials were
+		// rejected rather than that none were sent; the password is asked for
+		// once more when a prompt is set.
+		for prompted := false; ; prompted = true {
+			// Create new request with same body
+			var newBody io.Reader
+			if body != nil {
+				// Need to re-read the body
+				if seeker, ok := body.(io.Seeker); ok {
+					seeker.Seek(0, io.SeekStart)
+					newBody = body
+				} else if bodyReader, ok := body.(*bytes.Reader); ok {
+					bodyReader.Seek(0, io.SeekStart)
+					newBody = bodyReader
+				} else {
+					return nil, fmt.Errorf("cannot retry request with non-seekable body")
+				}
 			}
-		}
 
-		req, err = http.NewRequest(method, url, newBody)
-		if err != nil {
-			return nil, fmt.Errorf("failed to create authenticated request: %w", err)
-		}
+			req, err = http.NewRequest(method, url, newBody)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create authenticated request: %w", err)
+			}
 
-		if contentType != "" && newBody != nil {
-			req.Header.Set("Content-Type", contentType)
-		}
+			if contentType != "" && newBody != nil {
+				req.Header.Set("Content-Type", contentType)
+			}
+			for key, values := range header {
+				req.Header[key] = values
+			}
 
-		// Create digest authorization header
-		authHeader := createDigestAuthHeader(c.username, c.password, method, req.URL.RequestURI(), authParams)
-		req.Header.Set("Authorization", authHeader)
+			// Create digest (or Basic) authorization header
+			req.Header.Set("Authorization", c.authorization(method, req.URL.RequestURI()))
 
-		// Retry with authentication
-		resp, err = c.client.Do(req)
-		if err != nil {
-			return nil, fmt.Errorf("authenticated request failed: %w", err)
+			c.traceRequest(req, newBody)
+			resp, err = c.client.Do(req)
+			if err != nil {
+				return nil, fmt.Errorf("authenticated request failed: %w", redactError(err))
+			}
+			c.traceResponse(resp)
+
+			if resp.StatusCode != http.StatusUnauthorized {
+				break
+			}
+			authErr := checkResponse(resp, fmt.Sprintf("authentication failed for user %q, check the username and password", c.username))
+			resp.Body.Close()
+			c.updateAuthCache(resp.StatusCode)
+
+			if prompted || c.passwordPrompt == nil {
+				return nil, authErr
+			}
+			if err := c.retryPassword(resp.Header.Get("WWW-Authenticate")); err != nil {
+				return nil, err
+			}
 		}
 	}
 
+	c.updateAuthCache(resp.StatusCode)
+	c.dumpResponse(resp)
 	return resp, nil
 }
 
+// authenticate obtains a digest challenge with a cheap GET so that a following
+// large request can carry credentials on its first attempt
+func (c *Client) authenticate() error {
+	c.authMu.Lock()
+	cached := c.challenge != nil
+	c.authMu.Unlock()
+	if cached {
+		return nil
+	}
+
+	resp, err := c.doRequest("GET", "/info/", nil)
+	if err != nil {
+		return err
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	return nil
+}
+
+// SetPasswordPrompt sets a function that is asked once for a new password
+// when the player rejects the configured one, as an interactive caller might
+// prompt the user. Without it a rejected password fails the request. Set it
+// before making requests.
+func (c *Client) SetPasswordPrompt(prompt func() (string, error)) {
+	c.passwordPrompt = prompt
+}
+
+// retryPassword replaces the rejected password with one from the prompt and
+// caches the fresh digest challenge that came with the rejection, if any
+func (c *Client) retryPassword(wwwAuth string) error {
+	password, err := c.passwordPrompt()
+	if err != nil {
+		return fmt.Errorf("failed to read password: %w", err)
+	}
+
+	c.authMu.Lock()
+	c.password = password
+	c.authMu.Unlock()
+
+	if strings.HasPrefix(wwwAuth, "Digest") {
+		c.setChallenge(parseDigestAuth(wwwAuth))
+	}
+	return nil
+}
+
+// setChallenge caches a digest challenge and resets the nonce counter
+func (c *Client) setChallenge(params map[string]string) {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+	c.challenge = params
+	c.nonceCount = 0
+}
+
+// authorization returns a digest header for the cached challenge, or "" if none.
+// A Basic challenge is answered only when the client allows Basic, since a
+// persisted one may come from an invocation that did.
+func (c *Client) authorization(method, uri string) string {
+	c.authMu.Lock()
+	defer c.authMu.Unlock()
+	if c.challenge == nil {
+		return ""
+	}
+	if c.challenge["scheme"] == "Basic" {
+		if !c.allowBasic {
+			return ""
+		}
+		return basicAuthHeader(c.username, c.password)
+	}
+	c.nonceCount++
+	return createDigestAuthHeader(c.username, c.password, method, uri, c.challenge, c.nonceCount)
+}
+
 // parseJSON parses the JSON response body
 func parseJSON(resp *http.Response, target interface{}) error {
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("request failed with status %d: %s", resp.StatusCode, string(body))
+	if err := checkResponse(resp, ""); err != nil {
+		return err
 	}
 
 	if target == nil {
 		return nil
 	}
 
-	return json.NewDecoder(resp.Body).Decode(target)
+	body, err := jsonBody(resp)
+	if err != nil {
+		return err
+	}
+
+	return json.NewDecoder(body).Decode(target)
 }
 
 // parseDigestAuth parses digest authentication parameters from WWW-Authenticate header
@@ -230,8 +604,13 @@ func parseDigestAuth(wwwAuth string) map[string]string {
 	return params
 }
 
+// basicAuthHeader creates a Basic authentication header
+func basicAuthHeader(username, password string) string {
+	return "Basic " + base64.StdEncoding.EncodeToString([]byte(username+":"+password))
+}
+
 // createDigestAuthHeader creates a digest authentication header
-func createDigestAuthHeader(username, password, method, uri string, params map[string]string) string {
+func createDigestAuthHeader(username, password, method, uri string, params map[string]string, nonceCount uint32) string {
 	realm := params["realm"]
 	nonce := params["nonce"]
 	qop := params["qop"]
@@ -240,7 +619,7 @@ func createDigestAuthHeader(username, password, method, uri string, params map[s
 	// Generate cnonce
 	rand.Seed(time.Now().UnixNano())
 	cnonce := fmt.Sprintf("%08x", rand.Uint32())
-	nc := "00000001"
+	nc := fmt.Sprintf("%08x", nonceCount)
 
 	// Calculate response hash
 	ha1 := md5Hash(fmt.Sprintf("%s:%s:%s", username, realm, password))
@@ -1,8 +1,26 @@
 package brightsign
 
 import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 )
@@ -68,6 +86,18 @@ func TestNewClientDefaults(t *testing.T) {
 	}
 }
 
+func TestEmptyUsernameAuthenticatesAsDefault(t *testing.T) {
+	for _, name := range []string{"", "  "} {
+		client := NewClient(Config{Host: "test.local", Username: name, Password: "test"})
+		client.setChallenge(map[string]string{"realm": "BrightSign", "nonce": "abc123", "qop": "auth"})
+
+		header := client.authorization("GET", "/api/v1/info/")
+		if params := parseDigestAuth(header); params["username"] != DefaultUsername {
+			t.Errorf("Username %q: expected username=%q in %s", name, DefaultUsername, header)
+		}
+	}
+}
+
 func TestParseDigestAuth(t *testing.T) {
 	wwwAuth := `Digest realm="BrightSign", nonce="abc123", qop="auth", opaque="xyz789"`
 	
@@ -176,4 +206,690 @@ func TestMd5Hash(t *testing.T) {
 	if result != expected {
 		t.Errorf("Expected MD5 hash %s, got %s", expected, result)
 	}
-}
\ No newline at end of file
+}
+
+// validDigest reports whether the request carries a digest header that matches
+// the given credentials and nonce
+func validDigest(r *http.Request, username, password, nonce string) bool {
+	authHeader := r.Header.Get("Authorization")
+	if !strings.HasPrefix(authHeader, "Digest ") {
+		return false
+	}
+
+	params := parseDigestAuth(authHeader)
+	if params["username"] != username || params["nonce"] != nonce || params["uri"] != r.URL.RequestURI() {
+		return false
+	}
+
+	ha1 := md5Hash(fmt.Sprintf("%s:%s:%s", username, params["realm"], password))
+	ha2 := md5Hash(fmt.Sprintf("%s:%s", r.Method, params["uri"]))
+	expected := md5Hash(fmt.Sprintf("%s:%s:%s:%s:%s:%s", ha1, nonce, params["nc"], params["cnonce"], params["qop"], ha2))
+
+	return params["response"] == expected
+}
+
+func TestUploadWithPreAuthenticate(t *testing.T) {
+	const nonce = "abc123"
+	var uploadRequests, authorizedUploads int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PUT" {
+			uploadRequests++
+		}
+
+		if !validDigest(r, "admin", "password", nonce) {
+			w.Header().Set("WWW-Authenticate", `Digest realm="BrightSign", nonce="abc123", qop="auth"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if r.Method == "PUT" {
+			authorizedUploads++
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{
+		Host:            server.URL[7:],
+		Username:        "admin",
+		Password:        "password",
+		PreAuthenticate: true,
+	})
+	client.baseURL = server.URL + "/api/v1"
+
+	localFile := filepath.Join(t.TempDir(), "video.mp4")
+	if err := os.WriteFile(localFile, bytes.Repeat([]byte("x"), 64*1024), 0644); err != nil {
+		t.Fatalf("Failed to create local file: %v", err)
+	}
+
+	if err := client.Storage.UploadFile(localFile, "/storage/sd/video.mp4"); err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+
+	if uploadRequests != 1 {
+		t.Errorf("Expected exactly 1 upload request, got %d", uploadRequests)
+	}
+
+	if authorizedUploads != 1 {
+		t.Errorf("Expected the upload to carry a valid Authorization header, got %d authorized uploads", authorizedUploads)
+	}
+}
+
+func TestCachedChallengeIncrementsNonceCount(t *testing.T) {
+	client := NewClient(Config{Host: "test.local", Password: "password"})
+	client.setChallenge(map[string]string{"realm": "BrightSign", "nonce": "abc123", "qop": "auth"})
+
+	first := parseDigestAuth(client.authorization("GET", "/api/v1/info/"))
+	second := parseDigestAuth(client.authorization("GET", "/api/v1/info/"))
+
+	if first["nc"] != "00000001" {
+		t.Errorf("Expected first nc 00000001, got %s", first["nc"])
+	}
+	if second["nc"] != "00000002" {
+		t.Errorf("Expected second nc 00000002, got %s", second["nc"])
+	}
+}
+
+func TestClientReusesConnections(t *testing.T) {
+	var mu sync.Mutex
+	conns := make(map[string]bool)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		conns[r.RemoteAddr] = true
+		mu.Unlock()
+
+		if !validDigest(r, "admin", "password", "abc123") {
+			w.Header().Set("WWW-Authenticate", `Digest realm="BrightSign", nonce="abc123", qop="auth"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte("unauthorized"))
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{"model":"XT1144"}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	for i := 0; i < 5; i++ {
+		if _, err := client.Info.GetInfo(); err != nil {
+			t.Fatalf("GetInfo failed: %v", err)
+		}
+	}
+
+	if len(conns) != 1 {
+		t.Errorf("Expected all requests on 1 connection, got %d", len(conns))
+	}
+}
+
+// benchmarkSequentialGets issues b.N GETs, optionally on a fresh transport each time
+func benchmarkSequentialGets(b *testing.B, reuse bool) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{"model":"XT1144"}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !reuse {
+			client.client.Transport = newTransport(nil)
+		}
+		if _, err := client.Info.GetInfo(); err != nil {
+			b.Fatalf("GetInfo failed: %v", err)
+		}
+		if !reuse {
+			client.client.Transport.(*http.Transport).CloseIdleConnections()
+		}
+	}
+}
+
+func BenchmarkSequentialGetsReused(b *testing.B) {
+	benchmarkSequentialGets(b, true)
+}
+
+func BenchmarkSequentialGetsNewConnection(b *testing.B) {
+	benchmarkSequentialGets(b, false)
+}
+
+func TestBuildBaseURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		expected string
+	}{
+		{"Default", Config{Host: "192.168.1.100"}, "http://192.168.1.100/api/v1"},
+		{"Insecure", Config{Host: "192.168.1.100", Insecure: true}, "https://192.168.1.100/api/v1"},
+		{"Port", Config{Host: "192.168.1.100", Port: 8080}, "http://192.168.1.100:8080/api/v1"},
+		{"HostWithPort", Config{Host: "192.168.1.100:8080"}, "http://192.168.1.100:8080/api/v1"},
+		{"HostPortWins", Config{Host: "192.168.1.100:8080", Port: 9090}, "http://192.168.1.100:8080/api/v1"},
+		{"BasePath", Config{Host: "proxy.local", BasePath: "/custom"}, "http://proxy.local/custom/api/v1"},
+		{"BasePathSlashes", Config{Host: "proxy.local", BasePath: "custom/players/"}, "http://proxy.local/custom/players/api/v1"},
+		{"PortAndBasePath", Config{Host: "host", Port: 8080, BasePath: "/custom"}, "http://host:8080/custom/api/v1"},
+		{"IPv6", Config{Host: "::1", Port: 8080}, "http://[::1]:8080/api/v1"},
+		{"IPv6Bracketed", Config{Host: "[::1]", Port: 8080}, "http://[::1]:8080/api/v1"},
+		{"IPv6WithPort", Config{Host: "[::1]:8080", Port: 9090}, "http://[::1]:8080/api/v1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if client := NewClient(test.config); client.baseURL != test.expected {
+				t.Errorf("Expected baseURL %s, got %s", test.expected, client.baseURL)
+			}
+		})
+	}
+}
+
+func TestNewClientNormalizesHost(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   Config
+		host     string
+		expected string
+	}{
+		{"Host", Config{Host: "192.168.1.100"}, "192.168.1.100", "http://192.168.1.100/api/v1"},
+		{"HostPort", Config{Host: "192.168.1.100:8080"}, "192.168.1.100:8080", "http://192.168.1.100:8080/api/v1"},
+		{"HTTP", Config{Host: "http://192.168.1.100"}, "192.168.1.100", "http://192.168.1.100/api/v1"},
+		{"HTTPPortSlash", Config{Host: "http://192.168.1.100:8080/"}, "192.168.1.100:8080", "http://192.168.1.100:8080/api/v1"},
+		{"HTTPS", Config{Host: "https://player.local:443"}, "player.local:443", "https://player.local:443/api/v1"},
+		{"HTTPWithInsecure", Config{Host: "http://player.local", Insecure: true}, "player.local", "https://player.local/api/v1"},
+		{"HTTPSPortFlagIgnored", Config{Host: "https://player.local:443", Port: 8443}, "player.local:443", "https://player.local:443/api/v1"},
+		{"PathBecomesBasePath", Config{Host: "http://proxy.local/custom"}, "proxy.local", "http://proxy.local/custom/api/v1"},
+		{"APIURL", Config{Host: "http://proxy.local/custom/api/v1/"}, "proxy.local", "http://proxy.local/custom/api/v1"},
+		{"BasePathWins", Config{Host: "http://proxy.local/custom", BasePath: "/other"}, "proxy.local", "http://proxy.local/other/api/v1"},
+		{"TrailingSlash", Config{Host: "player.local/"}, "player.local", "http://player.local/api/v1"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client := NewClient(test.config)
+			if client.host != test.host {
+				t.Errorf("Expected host %s, got %s", test.host, client.host)
+			}
+			if client.baseURL != test.expected {
+				t.Errorf("Expected baseURL %s, got %s", test.expected, client.baseURL)
+			}
+		})
+	}
+}
+
+func TestClientDoRaw(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			w.Header().Set("WWW-Authenticate", `Digest realm="BrightSign", nonce="abc123", qop="auth"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method != "PUT" || r.URL.Path != "/api/v1/custom/endpoint/" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+
+		var body map[string]string
+		json.NewDecoder(r.Body).Decode(&body)
+		if body["key"] != "value" {
+			t.Errorf("Expected JSON body with key=value, got %v", body)
+		}
+
+		w.Header().Set("ETag", `"v42"`)
+		w.WriteHeader(http.StatusAccepted)
+		w.Write([]byte("raw"))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	resp, err := client.DoRaw("PUT", "custom/endpoint/", map[string]string{"key": "value"})
+	if err != nil {
+		t.Fatalf("DoRaw failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		t.Errorf("Expected status 202, got %d", resp.StatusCode)
+	}
+	if etag := resp.Header.Get("ETag"); etag != `"v42"` {
+		t.Errorf("Expected ETag header %q, got %q", `"v42"`, etag)
+	}
+	data, _ := io.ReadAll(resp.Body)
+	if string(data) != "raw" {
+		t.Errorf("Expected body %q, got %q", "raw", data)
+	}
+}
+
+func TestNullResultsAreEmpty(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":null}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	tests := []struct {
+		name string
+		call func() (interface{}, error)
+		json string
+	}{
+		{"registry GetAll", func() (interface{}, error) { return client.Registry.GetAll() }, `{}`},
+		{"registry GetSnapshot", func() (interface{}, error) { return client.Registry.GetSnapshot() }, `{}`},
+		{"ListAPIs", func() (interface{}, error) { return client.Info.ListAPIs() }, `[]`},
+		{"GetNetworkNeighborhood", func() (interface{}, error) { return client.Diagnostics.GetNetworkNeighborhood() }, `{}`},
+		{"GetInterfaces", func() (interface{}, error) { return client.Diagnostics.GetInterfaces() }, `[]`},
+		{"GetAvailableModes", func() (interface{}, error) { return client.Video.GetAvailableModes("hdmi", "0") }, `[]`},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := test.call()
+			if err != nil {
+				t.Fatalf("Expected no error for a null result, got %v", err)
+			}
+			data, _ := json.Marshal(result)
+			if string(data) != test.json {
+				t.Errorf("Expected %s, got %s", test.json, data)
+			}
+		})
+	}
+
+	sections, err := client.Registry.ListSections()
+	if err != nil || len(sections) != 0 {
+		t.Errorf("Expected no sections in a null registry, got %v, %v", sections, err)
+	}
+}
+
+func TestTLSVerification(t *testing.T) {
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{"model":"XT1144"}}}`))
+	}))
+	// The rejected handshake is expected; keep it out of the test output
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	pool := x509.NewCertPool()
+	pool.AddCert(server.Certificate())
+
+	tests := []struct {
+		name    string
+		config  Config
+		wantErr bool
+	}{
+		{"https verifies against system roots", Config{HTTPS: true}, true},
+		{"custom CA", Config{RootCAs: pool}, false},
+		{"insecure", Config{Insecure: true}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.config.Host = host
+			tt.config.Password = "password"
+			client := NewClient(tt.config)
+			if !strings.HasPrefix(client.baseURL, "https://") {
+				t.Fatalf("Expected an https base URL, got %s", client.baseURL)
+			}
+
+			_, err := client.Info.GetInfo()
+			if tt.wantErr && err == nil {
+				t.Error("Expected a certificate verification error")
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("GetInfo failed: %v", err)
+			}
+		})
+	}
+}
+
+// writeClientCert generates a self-signed client certificate and writes it and
+// its key as PEM files to dir
+func writeClientCert(t *testing.T, dir, name string) (certFile, keyFile string, cert *x509.Certificate) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("Failed to create certificate: %v", err)
+	}
+	cert, _ = x509.ParseCertificate(der)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatalf("Failed to marshal key: %v", err)
+	}
+
+	certFile = filepath.Join(dir, name+".crt")
+	keyFile = filepath.Join(dir, name+".key")
+	os.WriteFile(certFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600)
+	os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600)
+	return certFile, keyFile, cert
+}
+
+func TestClientCertificate(t *testing.T) {
+	dir := t.TempDir()
+	certFile, keyFile, cert := writeClientCert(t, dir, "player-admin")
+	_, otherKey, _ := writeClientCert(t, dir, "other")
+
+	var requests int
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{"model":"XT1144"}}}`))
+	}))
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(cert)
+	server.TLS = &tls.Config{ClientAuth: tls.RequireAndVerifyClientCert, ClientCAs: clientCAs}
+	server.Config.ErrorLog = log.New(io.Discard, "", 0)
+	server.StartTLS()
+	defer server.Close()
+	host := strings.TrimPrefix(server.URL, "https://")
+
+	newClient := func(certFile, keyFile string) *Client {
+		return NewClient(Config{Host: host, Password: "password", Insecure: true, ClientCert: certFile, ClientKey: keyFile})
+	}
+
+	if _, err := newClient(certFile, keyFile).Info.GetInfo(); err != nil {
+		t.Fatalf("GetInfo with a client certificate failed: %v", err)
+	}
+
+	if _, err := newClient("", "").Info.GetInfo(); err == nil {
+		t.Error("Expected the server to reject a client without a certificate")
+	}
+
+	requests = 0
+	_, err := newClient(certFile, otherKey).Info.GetInfo()
+	if err == nil || !strings.Contains(err.Error(), "failed to load client certificate") {
+		t.Errorf("Expected a certificate/key mismatch error, got %v", err)
+	}
+	if requests != 0 {
+		t.Errorf("Expected no requests with an invalid certificate, got %d", requests)
+	}
+
+	if _, err := LoadClientCertificate(filepath.Join(dir, "missing.crt"), ""); err == nil {
+		t.Error("Expected an error for a missing certificate file")
+	}
+}
+
+// benchmarkCommands runs b.N commands against one digest-protected player,
+// either on one client or on a new client per command, and reports the
+// number of HTTP requests per command
+func benchmarkCommands(b *testing.B, reuse bool) {
+	var mu sync.Mutex
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests++
+		mu.Unlock()
+		if !validDigest(r, "admin", "password", "abc123") {
+			w.Header().Set("WWW-Authenticate", `Digest realm="BrightSign", nonce="abc123", qop="auth"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{"model":"XT1144"}}}`))
+	}))
+	defer server.Close()
+
+	newClient := func() *Client {
+		return NewClient(Config{Host: server.URL[7:], Password: "password"})
+	}
+	client := newClient()
+	defer client.Close()
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if !reuse {
+			client.Close()
+			client = newClient()
+		}
+		if _, err := client.Info.GetInfo(); err != nil {
+			b.Fatalf("GetInfo failed: %v", err)
+		}
+	}
+	b.StopTimer()
+
+	mu.Lock()
+	b.ReportMetric(float64(requests)/float64(b.N), "requests/op")
+	mu.Unlock()
+}
+
+func BenchmarkCommandsReusedClient(b *testing.B) {
+	benchmarkCommands(b, true)
+}
+
+func BenchmarkCommandsNewClient(b *testing.B) {
+	benchmarkCommands(b, false)
+}
+
+func TestClientClose(t *testing.T) {
+	var mu sync.Mutex
+	conns := map[string]bool{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		conns[r.RemoteAddr] = true
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{"model":"XT1144"}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	if _, err := client.Info.GetInfo(); err != nil {
+		t.Fatalf("GetInfo failed: %v", err)
+	}
+	if err := client.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+
+	// The client still works after Close, on a new connection
+	if _, err := client.Info.GetInfo(); err != nil {
+		t.Fatalf("GetInfo after Close failed: %v", err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(conns) != 2 {
+		t.Errorf("Expected a new connection after Close, got %d connections", len(conns))
+	}
+}
+
+func TestCustomUsernameInDigest(t *testing.T) {
+	const nonce = "abc123"
+	var usernames []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth := r.Header.Get("Authorization"); auth != "" {
+			usernames = append(usernames, parseDigestAuth(auth)["username"])
+		}
+		if !validDigest(r, "operator", "password", nonce) {
+			w.Header().Set("WWW-Authenticate", `Digest realm="BrightSign", nonce="abc123", qop="auth"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{"files":[]}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Username: "operator", Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	// Both the list and the upload path authenticate as the configured user
+	if _, err := client.Storage.ListFiles("/storage/sd/", nil); err != nil {
+		t.Fatalf("ListFiles failed: %v", err)
+	}
+	if err := client.Storage.UploadReader(strings.NewReader("hello"), 5, "/storage/sd/hello.txt"); err != nil {
+		t.Fatalf("UploadReader failed: %v", err)
+	}
+
+	if len(usernames) < 2 {
+		t.Fatalf("Expected authenticated requests, got %d", len(usernames))
+	}
+	for _, username := range usernames {
+		if username != "operator" {
+			t.Errorf("Expected digest username operator, got %q", username)
+		}
+	}
+}
+
+func TestBasicAuthFallback(t *testing.T) {
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		user, pass, ok := r.BasicAuth()
+		if !ok || user != "admin" || pass != "password" {
+			w.Header().Set("WWW-Authenticate", `Basic realm="BrightSign"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{"model":"XD1034"}}}`))
+	}))
+	defer server.Close()
+
+	t.Run("disabled by default", func(t *testing.T) {
+		client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+		_, err := client.Info.GetInfo()
+		if !errors.Is(err, ErrBasicAuthDisabled) || !errors.Is(err, ErrUnauthorized) {
+			t.Fatalf("Expected ErrBasicAuthDisabled and ErrUnauthorized, got %v", err)
+		}
+	})
+
+	t.Run("allowed", func(t *testing.T) {
+		client := NewClient(Config{Host: server.URL[7:], Password: "password", AllowBasic: true})
+		requests = 0
+		info, err := client.Info.GetInfo()
+		if err != nil {
+			t.Fatalf("GetInfo failed: %v", err)
+		}
+		if info.Model != "XD1034" {
+			t.Errorf("Expected model XD1034, got %s", info.Model)
+		}
+		if requests != 2 {
+			t.Errorf("Expected challenge and retry, got %d requests", requests)
+		}
+
+		// The challenge is remembered, so later requests authenticate at once
+		requests = 0
+		if _, err := client.Info.GetInfo(); err != nil {
+			t.Fatalf("Second GetInfo failed: %v", err)
+		}
+		if requests != 1 {
+			t.Errorf("Expected a single request with Basic credentials, got %d", requests)
+		}
+	})
+
+	t.Run("wrong password", func(t *testing.T) {
+		client := NewClient(Config{Host: server.URL[7:], Password: "wrong", AllowBasic: true})
+		_, err := client.Info.GetInfo()
+		if !errors.Is(err, ErrUnauthorized) {
+			t.Fatalf("Expected ErrUnauthorized, got %v", err)
+		}
+	})
+}
+
+func TestBasicChallengeNotReusedWithoutAllowBasic(t *testing.T) {
+	client := NewClient(Config{Host: "player", Password: "password"})
+	client.setChallenge(map[string]string{"scheme": "Basic"})
+	if header := client.authorization("GET", "/api/v1/info/"); header != "" {
+		t.Errorf("Expected no Authorization header, got %q", header)
+	}
+
+	client = NewClient(Config{Host: "player", Password: "password", AllowBasic: true})
+	client.setChallenge(map[string]string{"scheme": "Basic"})
+	if header := client.authorization("GET", "/api/v1/info/"); header != "Basic YWRtaW46cGFzc3dvcmQ=" {
+		t.Errorf("Unexpected Authorization header %q", header)
+	}
+}
+
+// newPasswordServer accepts only digests made with password and counts requests
+func newPasswordServer(t *testing.T, password string, requests *int) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		*requests++
+		if !validDigest(r, "admin", password, "abc123") {
+			w.Header().Set("WWW-Authenticate", `Digest realm="BrightSign", nonce="abc123", qop="auth"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte("Unauthorized"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{"status":"running"}}}`))
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestRejectedCredentials(t *testing.T) {
+	var requests int
+	server := newPasswordServer(t, "password", &requests)
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "wrong"})
+	client.baseURL = server.URL + "/api/v1"
+
+	_, err := client.Info.GetHealth()
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Fatalf("Expected ErrUnauthorized, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "check the username and password") {
+		t.Errorf("Expected a hint about the credentials, got %q", err)
+	}
+	if requests != 2 {
+		t.Errorf("Expected the challenge and one credentialed retry, got %d requests", requests)
+	}
+}
+
+func TestRejectedCredentialsPrompt(t *testing.T) {
+	var requests int
+	server := newPasswordServer(t, "password", &requests)
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "wrong"})
+	client.baseURL = server.URL + "/api/v1"
+
+	prompts := 0
+	client.SetPasswordPrompt(func() (string, error) {
+		prompts++
+		return "password", nil
+	})
+
+	health, err := client.Info.GetHealth()
+	if err != nil {
+		t.Fatalf("Expected the prompted password to be accepted, got %v", err)
+	}
+	if health.Status != "running" || prompts != 1 {
+		t.Errorf("Expected one prompt and a running status, got %d prompts and %q", prompts, health.Status)
+	}
+
+	// A second wrong answer is not prompted for again
+	requests, prompts = 0, 0
+	client = NewClient(Config{Host: server.URL[7:], Password: "wrong"})
+	client.baseURL = server.URL + "/api/v1"
+	client.SetPasswordPrompt(func() (string, error) {
+		prompts++
+		return "still wrong", nil
+	})
+	if _, err := client.Info.GetHealth(); !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("Expected ErrUnauthorized after a second rejection, got %v", err)
+	}
+	if prompts != 1 || requests != 3 {
+		t.Errorf("Expected 1 prompt and 3 requests, got %d and %d", prompts, requests)
+	}
+}
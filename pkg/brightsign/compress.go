@@ -0,0 +1,49 @@
+package brightsign
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// gzipBody closes both the gzip reader and the response body it reads from
+type gzipBody struct {
+	*gzip.Reader
+	body io.ReadCloser
+}
+
+func (g *gzipBody) Close() error {
+	g.Reader.Close()
+	return g.body.Close()
+}
+
+// isGzipEncoded reports whether the player sent resp's body gzip-compressed.
+// The transport already decodes responses to requests it asked to compress;
+// this catches players that compress without being asked.
+func isGzipEncoded(resp *http.Response) bool {
+	return !resp.Uncompressed && strings.EqualFold(strings.TrimSpace(resp.Header.Get("Content-Encoding")), "gzip")
+}
+
+// decompress replaces a gzip-encoded response body with its decoded content
+// unless the client was configured with NoDecompress. The content length is
+// no longer known afterwards.
+func (c *Client) decompress(resp *http.Response) error {
+	if c.noDecompress || !isGzipEncoded(resp) {
+		return nil
+	}
+
+	reader, err := gzip.NewReader(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to decompress response: %w", err)
+	}
+	c.debugf("decompressing gzip-encoded response")
+
+	resp.Body = &gzipBody{Reader: reader, body: resp.Body}
+	resp.Header.Del("Content-Encoding")
+	resp.Header.Del("Content-Length")
+	resp.ContentLength = -1
+	resp.Uncompressed = true
+	return nil
+}
@@ -0,0 +1,90 @@
+package brightsign
+
+import (
+	"bytes"
+	"compress/gzip"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// gzipped compresses data
+func gzipped(t *testing.T, data string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(data))
+	zw.Close()
+	return buf.Bytes()
+}
+
+// newGzipServer serves body gzip-encoded whether or not the client asked for
+// it, and ignores Range headers
+func newGzipServer(t *testing.T, body []byte) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Encoding", "gzip")
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDownloadDecompressesGzip(t *testing.T) {
+	content := strings.Repeat("2026-10-16 player log line\n", 200)
+	compressed := gzipped(t, content)
+	server := newGzipServer(t, compressed)
+	dir := t.TempDir()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	local := filepath.Join(dir, "full.log")
+	if err := client.Storage.DownloadFile("/storage/sd/full.log", local); err != nil {
+		t.Fatalf("DownloadFile failed: %v", err)
+	}
+	if data, _ := os.ReadFile(local); string(data) != content {
+		t.Errorf("Expected decompressed content (%d bytes), got %d bytes", len(content), len(data))
+	}
+
+	// A range request is not compressed by the transport, so the body is
+	// decoded by the client itself
+	partial := filepath.Join(dir, "partial.log")
+	os.WriteFile(partial, []byte("2026"), 0644)
+	if _, err := client.Storage.DownloadFileResume("/storage/sd/full.log", partial); err != nil {
+		t.Fatalf("DownloadFileResume failed: %v", err)
+	}
+	if data, _ := os.ReadFile(partial); string(data) != content {
+		t.Errorf("Expected decompressed content (%d bytes), got %d bytes", len(content), len(data))
+	}
+
+	var logs bytes.Buffer
+	logServer := newGzipServer(t, gzipped(t, `{"data":{"result":"boot ok\n"}}`))
+	logClient := NewClient(Config{Host: logServer.URL[7:], Password: "password"})
+	logClient.baseURL = logServer.URL + "/api/v1"
+	if err := logClient.Logs.StreamLogs(&logs); err != nil {
+		t.Fatalf("StreamLogs failed: %v", err)
+	}
+	if logs.String() != "boot ok\n" {
+		t.Errorf("Expected decompressed logs, got %q", logs.String())
+	}
+}
+
+func TestDownloadNoDecompress(t *testing.T) {
+	compressed := gzipped(t, "raw content")
+	server := newGzipServer(t, compressed)
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password", NoDecompress: true})
+	client.baseURL = server.URL + "/api/v1"
+
+	local := filepath.Join(t.TempDir(), "raw.gz")
+	if err := client.Storage.DownloadFile("/storage/sd/raw", local); err != nil {
+		t.Fatalf("DownloadFile failed: %v", err)
+	}
+	if data, _ := os.ReadFile(local); !bytes.Equal(data, compressed) {
+		t.Errorf("Expected the compressed bytes as received, got %q", data)
+	}
+}
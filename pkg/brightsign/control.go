@@ -1,7 +1,12 @@
 package brightsign
 
 import (
+	"errors"
 	"fmt"
+	"io"
+	"strings"
+	"syscall"
+	"time"
 )
 
 // ControlService handles player control endpoints
@@ -37,6 +42,32 @@ type SnapshotOptions struct {
 	Width                      int  `json:"width,omitempty"`
 	Height                     int  `json:"height,omitempty"`
 	ShouldCaptureFullResolution bool `json:"shouldCaptureFullResolution,omitempty"`
+
+	// Format is the image format, "jpeg" or "png". Empty uses the player default.
+	Format string `json:"format,omitempty"`
+
+	// Quality is the JPEG quality, 1-100. Zero uses the player default.
+	Quality int `json:"quality,omitempty"`
+}
+
+// Validate checks the format and quality options
+func (o *SnapshotOptions) Validate() error {
+	switch o.Format {
+	case "", "jpeg", "png":
+	default:
+		return fmt.Errorf("invalid snapshot format %q (must be jpeg or png)", o.Format)
+	}
+
+	if o.Quality != 0 {
+		if o.Format == "png" {
+			return fmt.Errorf("quality is only supported for jpeg snapshots")
+		}
+		if o.Quality < 1 || o.Quality > 100 {
+			return fmt.Errorf("invalid snapshot quality %d (must be 1-100)", o.Quality)
+		}
+	}
+
+	return nil
 }
 
 // Reboot reboots the player with optional parameters
@@ -47,15 +78,51 @@ func (s *ControlService) Reboot(options *RebootOptions) error {
 
 	resp, err := s.client.doRequest("PUT", "/control/reboot/", options)
 	if err != nil {
+		// Players often go down before answering, so a dropped connection
+		// after the request was sent means the reboot took effect
+		if isConnectionClosed(err) {
+			s.client.debugf("Connection closed after reboot request, assuming the player is rebooting: %v", err)
+			return nil
+		}
 		return err
 	}
-	resp.Body.Close()
+	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("failed to reboot: status %d", resp.StatusCode)
-	}
+	return checkResponse(resp, "failed to reboot")
+}
 
-	return nil
+// isConnectionClosed reports whether err means the peer closed or reset the
+// connection after the request was written, as opposed to never being reached
+func isConnectionClosed(err error) bool {
+	return errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.EPIPE)
+}
+
+// autorunDevices are the storage devices searched for an autorun script at boot
+var autorunDevices = []string{"sd", "usb1", "ssd"}
+
+// IsAutorunEnabled reports whether the player has an autorun script at the
+// root of a boot storage device. The DWS does not expose whether autorun is
+// currently running, so a player rebooted with DisableAutorun still reports
+// true; callers should treat the result as a reason to warn, not to refuse.
+func (s *ControlService) IsAutorunEnabled() (bool, error) {
+	for _, device := range autorunDevices {
+		files, err := s.client.Storage.ListFiles("/storage/"+device+"/", nil)
+		if errors.Is(err, ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return false, err
+		}
+		for _, file := range files {
+			if strings.EqualFold(file.Name, "autorun.brs") || strings.EqualFold(file.Name, "autorun.zip") {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
 }
 
 // GetDWSPassword retrieves DWS password information (not the actual password)
@@ -84,13 +151,9 @@ func (s *ControlService) SetDWSPassword(config DWSPassword) error {
 	if err != nil {
 		return err
 	}
-	resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("failed to set DWS password: status %d", resp.StatusCode)
-	}
+	defer resp.Body.Close()
 
-	return nil
+	return checkResponse(resp, "failed to set DWS password")
 }
 
 // GetLocalDWS retrieves local DWS status
@@ -120,13 +183,9 @@ func (s *ControlService) SetLocalDWS(enabled bool) error {
 	if err != nil {
 		return err
 	}
-	resp.Body.Close()
+	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("failed to set local DWS: status %d", resp.StatusCode)
-	}
-
-	return nil
+	return checkResponse(resp, "failed to set local DWS")
 }
 
 // TakeSnapshot captures a snapshot of the currently playing content
@@ -134,6 +193,9 @@ func (s *ControlService) TakeSnapshot(options *SnapshotOptions) (string, error)
 	if options == nil {
 		options = &SnapshotOptions{}
 	}
+	if err := options.Validate(); err != nil {
+		return "", err
+	}
 
 	resp, err := s.client.doRequest("POST", "/snapshot/", options)
 	if err != nil {
@@ -160,11 +222,76 @@ func (s *ControlService) DownloadFirmware(url string) error {
 	if err != nil {
 		return err
 	}
-	resp.Body.Close()
+	defer resp.Body.Close()
+
+	return checkResponse(resp, "failed to download firmware")
+}
+
+// InstallFirmwareFile installs a firmware image already uploaded to the
+// player. The OS applies a .bsfw file found in the root of a storage device
+// at boot, so remotePath must look like /storage/sd/update.bsfw; the player
+// is rebooted to start the install.
+func (s *ControlService) InstallFirmwareFile(remotePath string) error {
+	if !strings.HasSuffix(strings.ToLower(remotePath), ".bsfw") {
+		return fmt.Errorf("firmware file must have a .bsfw extension: %s", remotePath)
+	}
+
+	rel := strings.TrimPrefix(remotePath, "/storage/")
+	if rel == remotePath || strings.Count(rel, "/") != 1 {
+		return fmt.Errorf("firmware file must be in the root of a storage device (e.g. /storage/sd/update.bsfw): %s", remotePath)
+	}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("failed to download firmware: status %d", resp.StatusCode)
+	if err := s.Reboot(nil); err != nil {
+		return fmt.Errorf("failed to start firmware install: %w", err)
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// identifyBlinkInterval is how long the display stays in each power state
+// while Identify blinks it
+var identifyBlinkInterval = time.Second
+
+// Identify blinks the connected display for duration seconds so a player can
+// be found in a rack. The DWS has no identify, LED or tone endpoint, so the
+// display is switched between standby and on, then left in the power state
+// it had before. This needs display control (BOS 9.0.189+).
+func (s *ControlService) Identify(duration int) error {
+	return s.IdentifyUntil(duration, nil)
+}
+
+// IdentifyUntil is Identify, but stops blinking early once stop is closed,
+// e.g. when the user interrupts. The display is restored either way.
+func (s *ControlService) IdentifyUntil(duration int, stop <-chan struct{}) error {
+	if duration <= 0 {
+		return fmt.Errorf("identify duration must be positive, got %d", duration)
+	}
+
+	display := s.client.Display
+	original := "on"
+	if settings, err := display.GetPowerSettings(); err == nil && settings.State != "" {
+		original = settings.State
+	}
+
+	toggles := int(time.Duration(duration) * time.Second / identifyBlinkInterval)
+	state := original
+	for i := 0; i < toggles; i++ {
+		if state == "on" {
+			state = "standby"
+		} else {
+			state = "on"
+		}
+		if err := display.SetPowerSettings(state); err != nil {
+			// Leave the display as it was, even if blinking failed midway
+			display.SetPowerSettings(original)
+			return err
+		}
+		select {
+		case <-stop:
+			return display.SetPowerSettings(original)
+		case <-time.After(identifyBlinkInterval):
+		}
+	}
+
+	return display.SetPowerSettings(original)
+}
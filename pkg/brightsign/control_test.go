@@ -0,0 +1,297 @@
+package brightsign
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestControlService_UploadAndInstallFirmware(t *testing.T) {
+	var requests []string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+
+		if r.URL.Path == "/api/v1/files/sd/" {
+			file, header, err := r.FormFile("file")
+			if err != nil {
+				t.Errorf("Expected multipart file upload: %v", err)
+			} else {
+				file.Close()
+				if header.Filename != "update.bsfw" {
+					t.Errorf("Expected filename update.bsfw, got %s", header.Filename)
+				}
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	localFile := filepath.Join(t.TempDir(), "firmware.bsfw")
+	if err := os.WriteFile(localFile, []byte("firmware"), 0644); err != nil {
+		t.Fatalf("Failed to create firmware file: %v", err)
+	}
+
+	if err := client.Storage.UploadFile(localFile, "/storage/sd/update.bsfw"); err != nil {
+		t.Fatalf("UploadFile failed: %v", err)
+	}
+
+	if err := client.Control.InstallFirmwareFile("/storage/sd/update.bsfw"); err != nil {
+		t.Fatalf("InstallFirmwareFile failed: %v", err)
+	}
+
+	expected := []string{"PUT /api/v1/files/sd/", "PUT /api/v1/control/reboot/"}
+	if len(requests) != len(expected) {
+		t.Fatalf("Expected requests %v, got %v", expected, requests)
+	}
+	for i := range expected {
+		if requests[i] != expected[i] {
+			t.Errorf("Expected request %d to be %s, got %s", i, expected[i], requests[i])
+		}
+	}
+}
+
+func TestControlService_InstallFirmwareFileValidation(t *testing.T) {
+	client := NewClient(Config{Host: "127.0.0.1:1", Password: "password"})
+
+	paths := []string{
+		"/storage/sd/update.zip",
+		"/storage/sd/firmware/update.bsfw",
+		"/tmp/update.bsfw",
+	}
+
+	for _, path := range paths {
+		if err := client.Control.InstallFirmwareFile(path); err == nil {
+			t.Errorf("Expected an error for %s", path)
+		}
+	}
+}
+
+func TestControlService_TakeSnapshotBody(t *testing.T) {
+	tests := []struct {
+		name     string
+		options  *SnapshotOptions
+		expected map[string]interface{}
+	}{
+		{
+			name:     "defaults",
+			options:  nil,
+			expected: map[string]interface{}{},
+		},
+		{
+			name:     "png",
+			options:  &SnapshotOptions{Width: 640, Format: "png"},
+			expected: map[string]interface{}{"width": float64(640), "format": "png"},
+		},
+		{
+			name:     "jpeg quality",
+			options:  &SnapshotOptions{Format: "jpeg", Quality: 85},
+			expected: map[string]interface{}{"format": "jpeg", "quality": float64(85)},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var body map[string]interface{}
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+					t.Errorf("Failed to decode request body: %v", err)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"data":{"result":"/storage/sd/snapshots/snap.jpg"}}`))
+			}))
+			defer server.Close()
+
+			client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+			client.baseURL = server.URL + "/api/v1"
+
+			if _, err := client.Control.TakeSnapshot(test.options); err != nil {
+				t.Fatalf("TakeSnapshot failed: %v", err)
+			}
+
+			if !reflect.DeepEqual(body, test.expected) {
+				t.Errorf("Expected body %v, got %v", test.expected, body)
+			}
+		})
+	}
+}
+
+func TestSnapshotOptionsValidate(t *testing.T) {
+	valid := []SnapshotOptions{
+		{},
+		{Format: "png"},
+		{Format: "jpeg", Quality: 1},
+		{Quality: 100},
+	}
+	for _, options := range valid {
+		if err := options.Validate(); err != nil {
+			t.Errorf("Expected %+v to be valid, got %v", options, err)
+		}
+	}
+
+	invalid := []SnapshotOptions{
+		{Format: "gif"},
+		{Format: "jpeg", Quality: 101},
+		{Quality: -1},
+		{Format: "png", Quality: 50},
+	}
+	for _, options := range invalid {
+		if err := options.Validate(); err == nil {
+			t.Errorf("Expected %+v to be invalid", options)
+		}
+	}
+}
+
+func TestControlService_RebootConnectionClosed(t *testing.T) {
+	var rebootRequested atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			w.Header().Set("WWW-Authenticate", `Digest realm="BrightSign", nonce="abc123", qop="auth"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.Method != "PUT" || r.URL.Path != "/api/v1/control/reboot/" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		rebootRequested.Store(true)
+
+		// Drop the connection without answering, as a rebooting player does
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Fatalf("Hijack failed: %v", err)
+		}
+		conn.Close()
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	if err := client.Control.Reboot(nil); err != nil {
+		t.Errorf("Expected a dropped connection after the reboot request to count as success, got %v", err)
+	}
+	if !rebootRequested.Load() {
+		t.Error("Expected the reboot request to reach the server")
+	}
+}
+
+func TestControlService_RebootUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	addr := server.URL[7:]
+	server.Close()
+
+	client := NewClient(Config{Host: addr, Password: "password"})
+
+	if err := client.Control.Reboot(nil); err == nil {
+		t.Error("Expected an error when the player cannot be reached")
+	}
+}
+
+func TestControlService_IsAutorunEnabled(t *testing.T) {
+	tests := []struct {
+		name     string
+		listings map[string]string
+		expected bool
+	}{
+		{"on sd", map[string]string{"sd": `[{"name":"AUTORUN.BRS","type":"file"}]`}, true},
+		{"on usb1", map[string]string{"sd": `[{"name":"video.mp4","type":"file"}]`, "usb1": `[{"name":"autorun.zip","type":"file"}]`}, true},
+		{"none", map[string]string{"sd": `[{"name":"video.mp4","type":"file"}]`}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				device := strings.Trim(strings.TrimPrefix(r.URL.Path, "/api/v1/files/"), "/")
+				listing, ok := tt.listings[device]
+				if !ok {
+					w.WriteHeader(http.StatusNotFound)
+					return
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"data":{"result":` + listing + `}}`))
+			}))
+			defer server.Close()
+
+			client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+			client.baseURL = server.URL + "/api/v1"
+
+			enabled, err := client.Control.IsAutorunEnabled()
+			if err != nil {
+				t.Fatalf("IsAutorunEnabled failed: %v", err)
+			}
+			if enabled != tt.expected {
+				t.Errorf("Expected %v, got %v", tt.expected, enabled)
+			}
+		})
+	}
+}
+
+func TestControlService_Identify(t *testing.T) {
+	defer func(d time.Duration) { identifyBlinkInterval = d }(identifyBlinkInterval)
+	identifyBlinkInterval = 250 * time.Millisecond
+
+	var mu sync.Mutex
+	var states []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/display-control/power-settings/" {
+			t.Errorf("Unexpected request for %s", r.URL.Path)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == "GET" {
+			w.Write([]byte(`{"data":{"result":{"state":"on"}}}`))
+			return
+		}
+		var payload PowerSettings
+		json.NewDecoder(r.Body).Decode(&payload)
+		mu.Lock()
+		states = append(states, payload.State)
+		mu.Unlock()
+		w.Write([]byte(`{"data":{"result":true}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	if err := client.Control.Identify(1); err != nil {
+		t.Fatalf("Identify failed: %v", err)
+	}
+
+	// Blinks for a second, then restores the original state
+	expected := []string{"standby", "on", "standby", "on", "on"}
+	if !reflect.DeepEqual(states, expected) {
+		t.Errorf("Expected power states %v, got %v", expected, states)
+	}
+
+	if err := client.Control.Identify(0); err == nil {
+		t.Error("Expected an error for a zero duration")
+	}
+
+	// Stopping early still restores the original state
+	mu.Lock()
+	states = nil
+	mu.Unlock()
+	stop := make(chan struct{})
+	time.AfterFunc(100*time.Millisecond, func() { close(stop) })
+	if err := client.Control.IdentifyUntil(10, stop); err != nil {
+		t.Fatalf("IdentifyUntil failed: %v", err)
+	}
+	expected = []string{"standby", "on"}
+	if !reflect.DeepEqual(states, expected) {
+		t.Errorf("Expected power states %v after stopping, got %v", expected, states)
+	}
+}
@@ -1,7 +1,17 @@
 package brightsign
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // DiagnosticsService handles diagnostic operations
@@ -14,6 +24,105 @@ type DiagnosticResult struct {
 	Test    string `json:"test"`
 	Status  string `json:"status"`
 	Message string `json:"message,omitempty"`
+
+	// DurationMs is how long the test took, when the firmware reports it
+	DurationMs float64 `json:"durationMs,omitempty"`
+}
+
+// DiagnosticsReport is the outcome of RunDiagnostics. Results holds the
+// parsed tests; Raw holds the response as returned, which is all there is
+// when the firmware uses a shape that could not be parsed.
+type DiagnosticsReport struct {
+	Results []DiagnosticResult
+	Raw     interface{}
+}
+
+// Passed reports whether the test succeeded
+func (r DiagnosticResult) Passed() bool {
+	switch strings.ToLower(r.Status) {
+	case "pass", "passed", "ok", "success", "true":
+		return true
+	}
+	return false
+}
+
+// Duration returns the test duration, or zero if it was not reported
+func (r DiagnosticResult) Duration() time.Duration {
+	return time.Duration(r.DurationMs * float64(time.Millisecond))
+}
+
+// UnmarshalJSON accepts the field spellings used by different firmware:
+// "test" or "name", "status" or "result" as a string or boolean, and a
+// duration in milliseconds ("duration", "durationMs", "time") or as a
+// duration string such as "120ms".
+func (r *DiagnosticResult) UnmarshalJSON(data []byte) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	r.Test = firstString(fields, "test", "name")
+	r.Status = firstString(fields, "status", "result")
+	r.Message = firstString(fields, "message", "details", "error")
+
+	for _, key := range []string{"durationMs", "duration", "time"} {
+		raw, ok := fields[key]
+		if !ok {
+			continue
+		}
+		if ms, ok := parseDurationMs(raw); ok {
+			r.DurationMs = ms
+			break
+		}
+	}
+	return nil
+}
+
+// firstString returns the first of keys present in fields as a string.
+// Booleans become "pass" or "fail" and numbers their decimal form.
+func firstString(fields map[string]json.RawMessage, keys ...string) string {
+	for _, key := range keys {
+		raw, ok := fields[key]
+		if !ok {
+			continue
+		}
+		var value interface{}
+		if err := json.Unmarshal(raw, &value); err != nil {
+			continue
+		}
+		switch v := value.(type) {
+		case string:
+			return v
+		case bool:
+			if v {
+				return "pass"
+			}
+			return "fail"
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		}
+	}
+	return ""
+}
+
+// parseDurationMs reads a duration given in milliseconds or as a Go
+// duration string
+func parseDurationMs(raw json.RawMessage) (float64, bool) {
+	var ms float64
+	if err := json.Unmarshal(raw, &ms); err == nil {
+		return ms, true
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err != nil {
+		return 0, false
+	}
+	if d, err := time.ParseDuration(text); err == nil {
+		return float64(d) / float64(time.Millisecond), true
+	}
+	if ms, err := strconv.ParseFloat(text, 64); err == nil {
+		return ms, true
+	}
+	return 0, false
 }
 
 // PingResult represents ping test results
@@ -31,12 +140,25 @@ type PingResult struct {
 
 // DNSLookupResult represents DNS lookup results
 type DNSLookupResult struct {
-	Success   bool     `json:"success"`
-	Hostname  string   `json:"hostname"`
-	Addresses []string `json:"addresses"`
-	Error     string   `json:"error,omitempty"`
+	Success   bool        `json:"success"`
+	Hostname  string      `json:"hostname"`
+	Addresses []string    `json:"addresses"`
+	Records   []DNSRecord `json:"records,omitempty"`
+	Error     string      `json:"error,omitempty"`
+}
+
+// DNSRecord represents a single DNS resource record
+type DNSRecord struct {
+	Type     string `json:"type"`
+	Name     string `json:"name,omitempty"`
+	Value    string `json:"value"`
+	TTL      int    `json:"ttl,omitempty"`
+	Priority int    `json:"priority,omitempty"` // MX preference
 }
 
+// DNSRecordTypes lists the record types accepted by DNSLookupWithType
+var DNSRecordTypes = []string{"A", "AAAA", "CNAME", "MX", "TXT"}
+
 // TraceRouteResult represents trace route results
 type TraceRouteResult struct {
 	Success bool        `json:"success"`
@@ -62,6 +184,46 @@ type NetworkConfig struct {
 	Gateway     string   `json:"gateway,omitempty"`
 	DNS         []string `json:"dns,omitempty"`
 	VLANID      int      `json:"vlanId,omitempty"`
+
+	// ClearDNS and ClearVLAN remove the interface's DNS servers or VLAN tag
+	// when the configuration is set. Without them, an empty DNS list or zero
+	// VLANID leaves the player's current setting unchanged.
+	ClearDNS  bool `json:"-"`
+	ClearVLAN bool `json:"-"`
+}
+
+// MarshalJSON sends a cleared DNS list as "dns": [] and a cleared VLAN as
+// "vlanId": 0; both are omitted otherwise when unset
+func (c NetworkConfig) MarshalJSON() ([]byte, error) {
+	type config NetworkConfig
+	out := struct {
+		config
+		DNS    *[]string `json:"dns,omitempty"`
+		VLANID *int      `json:"vlanId,omitempty"`
+	}{config: config(c)}
+
+	switch {
+	case c.ClearDNS:
+		out.DNS = &[]string{}
+	case len(c.DNS) > 0:
+		out.DNS = &c.DNS
+	}
+	switch {
+	case c.ClearVLAN:
+		out.VLANID = new(int)
+	case c.VLANID != 0:
+		out.VLANID = &c.VLANID
+	}
+	return json.Marshal(out)
+}
+
+// NetworkApplyResult is the outcome of a test apply of a network configuration.
+// Reachable is false when the player could not be reached with the new
+// settings, in which case the change was rolled back.
+type NetworkApplyResult struct {
+	Reachable     bool          `json:"reachable"`
+	AppliedConfig NetworkConfig `json:"config"`
+	Error         string        `json:"error,omitempty"`
 }
 
 // PacketCaptureConfig represents packet capture configuration
@@ -98,7 +260,7 @@ type SSHConfig struct {
 }
 
 // RunDiagnostics runs network diagnostics
-func (s *DiagnosticsService) RunDiagnostics() (interface{}, error) {
+func (s *DiagnosticsService) RunDiagnostics() (*DiagnosticsReport, error) {
 	resp, err := s.client.doRequest("GET", "/diagnostics/", nil)
 	if err != nil {
 		return nil, err
@@ -106,7 +268,7 @@ func (s *DiagnosticsService) RunDiagnostics() (interface{}, error) {
 
 	var result struct {
 		Data struct {
-			Result interface{} `json:"result"`
+			Result json.RawMessage `json:"result"`
 		} `json:"data"`
 	}
 
@@ -114,14 +276,156 @@ func (s *DiagnosticsService) RunDiagnostics() (interface{}, error) {
 		return nil, err
 	}
 
-	return result.Data.Result, nil
+	report := &DiagnosticsReport{Results: parseDiagnosticResults(result.Data.Result)}
+	json.Unmarshal(result.Data.Result, &report.Raw)
+	return report, nil
+}
+
+// FilterDiagnostics returns the results whose test is named in only (all
+// tests when only is empty) and not named in skip. Names are compared
+// case-insensitively.
+func FilterDiagnostics(results []DiagnosticResult, only, skip []string) []DiagnosticResult {
+	contains := func(names []string, test string) bool {
+		for _, name := range names {
+			if strings.EqualFold(strings.TrimSpace(name), test) {
+				return true
+			}
+		}
+		return false
+	}
+
+	filtered := []DiagnosticResult{}
+	for _, result := range results {
+		if len(only) > 0 && !contains(only, result.Test) {
+			continue
+		}
+		if contains(skip, result.Test) {
+			continue
+		}
+		filtered = append(filtered, result)
+	}
+	return filtered
+}
+
+// parseDiagnosticResults accepts an array of results, a single result object,
+// or an object keyed by test name. Unknown shapes yield nil.
+func parseDiagnosticResults(raw json.RawMessage) []DiagnosticResult {
+	var list []DiagnosticResult
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return list
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil
+	}
+
+	// A single result names its test
+	if _, ok := fields["test"]; ok {
+		var single DiagnosticResult
+		if err := json.Unmarshal(raw, &single); err == nil {
+			return []DiagnosticResult{single}
+		}
+	}
+
+	// Otherwise each key is a test name mapping to a result or a bare status.
+	// Anything without a status is not a diagnostics result.
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var results []DiagnosticResult
+	for _, name := range names {
+		var entry DiagnosticResult
+		if err := json.Unmarshal(fields[name], &entry); err != nil {
+			status := firstString(map[string]json.RawMessage{"status": fields[name]}, "status")
+			if status == "" {
+				return nil
+			}
+			entry = DiagnosticResult{Status: status}
+		}
+		if entry.Status == "" {
+			return nil
+		}
+		if entry.Test == "" {
+			entry.Test = name
+		}
+		results = append(results, entry)
+	}
+	return results
+}
+
+// hostnameLabel matches one label of a hostname. Underscores are accepted for
+// service names such as _dmarc.
+var hostnameLabel = regexp.MustCompile(`^[A-Za-z0-9_]([A-Za-z0-9_-]{0,61}[A-Za-z0-9_])?$`)
+
+// ValidateTarget checks that address is an IP address (IPv6 optionally in
+// brackets or with a zone) or a plausible hostname, so that malformed input is
+// rejected before it is placed in a request path
+func ValidateTarget(address string) error {
+	host := strings.TrimSuffix(strings.TrimPrefix(address, "["), "]")
+	if host == "" {
+		return fmt.Errorf("address is required")
+	}
+
+	ipPart := host
+	if i := strings.Index(host, "%"); i != -1 && strings.Contains(host, ":") {
+		ipPart = host[:i]
+	}
+	if net.ParseIP(ipPart) != nil {
+		return nil
+	}
+
+	name := strings.TrimSuffix(host, ".")
+	if len(name) > 253 || strings.Contains(host, ":") {
+		return fmt.Errorf("invalid address %q: not an IP address or hostname", address)
+	}
+	for _, label := range strings.Split(name, ".") {
+		if !hostnameLabel.MatchString(label) {
+			return fmt.Errorf("invalid address %q: not an IP address or hostname", address)
+		}
+	}
+	return nil
+}
+
+// targetPathSegment escapes a validated target for use as a path segment.
+// Brackets are dropped and IPv6 colons are percent-encoded.
+func targetPathSegment(address string) string {
+	host := strings.TrimSuffix(strings.TrimPrefix(address, "["), "]")
+	return strings.ReplaceAll(url.PathEscape(host), ":", "%3A")
 }
 
 // DNSLookup performs DNS lookup
 func (s *DiagnosticsService) DNSLookup(address string, resolveAddress bool) (*DNSLookupResult, error) {
-	path := fmt.Sprintf("/diagnostics/dns-lookup/%s", address)
+	return s.DNSLookupWithType(address, "", resolveAddress)
+}
+
+// DNSLookupWithType performs a DNS lookup for a specific record type (A,
+// AAAA, CNAME, MX or TXT). An empty recordType performs the default
+// addresses-only lookup.
+func (s *DiagnosticsService) DNSLookupWithType(address, recordType string, resolveAddress bool) (*DNSLookupResult, error) {
+	if err := ValidateTarget(address); err != nil {
+		return nil, err
+	}
+
+	recordType = strings.ToUpper(recordType)
+	if recordType != "" && !isDNSRecordType(recordType) {
+		return nil, fmt.Errorf("unsupported DNS record type %q (supported: %s)", recordType, strings.Join(DNSRecordTypes, ", "))
+	}
+
+	query := url.Values{}
 	if resolveAddress {
-		path += "?resolveAddress=true"
+		query.Set("resolveAddress", "true")
+	}
+	if recordType != "" {
+		query.Set("recordType", recordType)
+	}
+
+	path := fmt.Sprintf("/diagnostics/dns-lookup/%s", targetPathSegment(address))
+	if len(query) > 0 {
+		path += "?" + query.Encode()
 	}
 
 	resp, err := s.client.doRequest("GET", path, nil)
@@ -139,12 +443,33 @@ func (s *DiagnosticsService) DNSLookup(address string, resolveAddress bool) (*DN
 		return nil, err
 	}
 
-	return &result.Data.Result, nil
+	// Address lookups may only report the flat address list
+	lookup := &result.Data.Result
+	if len(lookup.Records) == 0 && (recordType == "A" || recordType == "AAAA") {
+		for _, addr := range lookup.Addresses {
+			lookup.Records = append(lookup.Records, DNSRecord{Type: recordType, Name: lookup.Hostname, Value: addr})
+		}
+	}
+
+	return lookup, nil
+}
+
+// isDNSRecordType reports whether recordType is one of DNSRecordTypes
+func isDNSRecordType(recordType string) bool {
+	for _, t := range DNSRecordTypes {
+		if t == recordType {
+			return true
+		}
+	}
+	return false
 }
 
 // Ping performs ping test
 func (s *DiagnosticsService) Ping(ipAddress string) (*PingResult, error) {
-	path := fmt.Sprintf("/diagnostics/ping/%s", ipAddress)
+	if err := ValidateTarget(ipAddress); err != nil {
+		return nil, err
+	}
+	path := fmt.Sprintf("/diagnostics/ping/%s", targetPathSegment(ipAddress))
 
 	resp, err := s.client.doRequest("GET", path, nil)
 	if err != nil {
@@ -164,11 +489,42 @@ func (s *DiagnosticsService) Ping(ipAddress string) (*PingResult, error) {
 	return &result.Data.Result, nil
 }
 
+// TraceRouteOptions contains options for trace route
+type TraceRouteOptions struct {
+	ResolveAddress bool
+	MaxHops        int // 0 uses the player default
+	Timeout        int // Per-hop timeout in seconds, 0 uses the player default
+}
+
 // TraceRoute performs trace route
 func (s *DiagnosticsService) TraceRoute(address string, resolveAddress bool) (*TraceRouteResult, error) {
-	path := fmt.Sprintf("/diagnostics/trace-route/%s", address)
-	if resolveAddress {
-		path += "?resolveAddress=true"
+	return s.TraceRouteWithOptions(address, &TraceRouteOptions{ResolveAddress: resolveAddress})
+}
+
+// TraceRouteWithOptions performs trace route with hop and timeout limits
+func (s *DiagnosticsService) TraceRouteWithOptions(address string, options *TraceRouteOptions) (*TraceRouteResult, error) {
+	if options == nil {
+		options = &TraceRouteOptions{}
+	}
+
+	query := url.Values{}
+	if options.ResolveAddress {
+		query.Set("resolveAddress", "true")
+	}
+	if options.MaxHops > 0 {
+		query.Set("maxHops", fmt.Sprintf("%d", options.MaxHops))
+	}
+	if options.Timeout > 0 {
+		query.Set("timeout", fmt.Sprintf("%d", options.Timeout))
+	}
+
+	if err := ValidateTarget(address); err != nil {
+		return nil, err
+	}
+
+	path := fmt.Sprintf("/diagnostics/trace-route/%s", targetPathSegment(address))
+	if len(query) > 0 {
+		path += "?" + query.Encode()
 	}
 
 	resp, err := s.client.doRequest("GET", path, nil)
@@ -206,9 +562,57 @@ func (s *DiagnosticsService) GetNetworkNeighborhood() (map[string]interface{}, e
 		return nil, err
 	}
 
+	if result.Data.Result == nil {
+		return map[string]interface{}{}, nil
+	}
+
 	return result.Data.Result, nil
 }
 
+// WiFiNetwork represents a wireless network seen by a Wi-Fi scan
+type WiFiNetwork struct {
+	SSID     string `json:"ssid"`
+	BSSID    string `json:"bssid"`
+	Signal   int    `json:"signal"` // dBm
+	Channel  int    `json:"channel"`
+	Security string `json:"security,omitempty"`
+}
+
+// WiFiScan lists the wireless networks visible to the player, strongest first
+func (s *DiagnosticsService) WiFiScan() ([]WiFiNetwork, error) {
+	resp, err := s.client.doRequest("GET", "/diagnostics/wifi-scan/", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// Some firmware wraps the list in a networks field
+	var result struct {
+		Data struct {
+			Result json.RawMessage `json:"result"`
+		} `json:"data"`
+	}
+
+	if err := parseJSON(resp, &result); err != nil {
+		return nil, err
+	}
+
+	var networks []WiFiNetwork
+	if err := json.Unmarshal(result.Data.Result, &networks); err != nil {
+		var wrapped struct {
+			Networks []WiFiNetwork `json:"networks"`
+		}
+		if err := json.Unmarshal(result.Data.Result, &wrapped); err != nil {
+			return nil, fmt.Errorf("%w: unexpected wifi scan result: %v", ErrInvalidResponse, err)
+		}
+		networks = wrapped.Networks
+	}
+
+	sort.SliceStable(networks, func(i, j int) bool {
+		return networks[i].Signal > networks[j].Signal
+	})
+	return networks, nil
+}
+
 // GetNetworkConfiguration gets network configuration for interface
 func (s *DiagnosticsService) GetNetworkConfiguration(interfaceName string) (*NetworkConfig, error) {
 	path := fmt.Sprintf("/diagnostics/network-configuration/%s/", interfaceName)
@@ -231,21 +635,69 @@ func (s *DiagnosticsService) GetNetworkConfiguration(interfaceName string) (*Net
 	return &result.Data.Result, nil
 }
 
-// SetNetworkConfiguration applies test network configuration
-func (s *DiagnosticsService) SetNetworkConfiguration(interfaceName string, config NetworkConfig) error {
+// SetNetworkConfiguration test-applies a network configuration and reports
+// whether the player stays reachable with it
+func (s *DiagnosticsService) SetNetworkConfiguration(interfaceName string, config NetworkConfig) (*NetworkApplyResult, error) {
 	path := fmt.Sprintf("/diagnostics/network-configuration/%s/", interfaceName)
 
 	resp, err := s.client.doRequest("PUT", path, config)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	resp.Body.Close()
+	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("failed to set network configuration: status %d", resp.StatusCode)
+	if err := checkResponse(resp, "failed to set network configuration"); err != nil {
+		return nil, err
 	}
 
-	return nil
+	// Older firmware reports success instead of reachable
+	var result struct {
+		Data struct {
+			Result struct {
+				Reachable *bool          `json:"reachable"`
+				Success   *bool          `json:"success"`
+				Config    *NetworkConfig `json:"config"`
+				Error     string         `json:"error"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read network configuration result: %w", err)
+	}
+
+	// Some firmware acknowledges with an empty body; the 2xx status alone
+	// means the configuration was applied
+	apply := &NetworkApplyResult{AppliedConfig: config, Reachable: true}
+	if len(bytes.TrimSpace(data)) == 0 {
+		return apply, nil
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	body, err := jsonBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	if err := json.NewDecoder(body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to parse network configuration result: %w", err)
+	}
+
+	raw := result.Data.Result
+	apply.Error = raw.Error
+	switch {
+	case raw.Reachable != nil:
+		apply.Reachable = *raw.Reachable
+	case raw.Success != nil:
+		apply.Reachable = *raw.Success
+	default:
+		apply.Reachable = raw.Error == ""
+	}
+	if raw.Config != nil {
+		apply.AppliedConfig = *raw.Config
+	}
+
+	return apply, nil
 }
 
 // GetInterfaces returns list of applied network interfaces
@@ -265,6 +717,10 @@ func (s *DiagnosticsService) GetInterfaces() ([]string, error) {
 		return nil, err
 	}
 
+	if result.Data.Result == nil {
+		return []string{}, nil
+	}
+
 	return result.Data.Result, nil
 }
 
@@ -294,13 +750,9 @@ func (s *DiagnosticsService) StartPacketCapture(config PacketCaptureConfig) erro
 	if err != nil {
 		return err
 	}
-	resp.Body.Close()
+	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("failed to start packet capture: status %d", resp.StatusCode)
-	}
-
-	return nil
+	return checkResponse(resp, "failed to start packet capture")
 }
 
 // StopPacketCapture stops packet capture operation
@@ -309,13 +761,9 @@ func (s *DiagnosticsService) StopPacketCapture() error {
 	if err != nil {
 		return err
 	}
-	resp.Body.Close()
+	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("failed to stop packet capture: status %d", resp.StatusCode)
-	}
-
-	return nil
+	return checkResponse(resp, "failed to stop packet capture")
 }
 
 // GetTelnetConfig returns telnet configuration
@@ -344,13 +792,9 @@ func (s *DiagnosticsService) SetTelnetConfig(config TelnetConfig) error {
 	if err != nil {
 		return err
 	}
-	resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("failed to set telnet configuration: status %d", resp.StatusCode)
-	}
+	defer resp.Body.Close()
 
-	return nil
+	return checkResponse(resp, "failed to set telnet configuration")
 }
 
 // GetSSHConfig returns SSH configuration
@@ -379,11 +823,7 @@ func (s *DiagnosticsService) SetSSHConfig(config SSHConfig) error {
 	if err != nil {
 		return err
 	}
-	resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("failed to set SSH configuration: status %d", resp.StatusCode)
-	}
+	defer resp.Body.Close()
 
-	return nil
+	return checkResponse(resp, "failed to set SSH configuration")
 }
\ No newline at end of file
@@ -0,0 +1,521 @@
+package brightsign
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestDiagnosticsService_DNSLookupA(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/diagnostics/dns-lookup/example.com" {
+			t.Errorf("Expected path /api/v1/diagnostics/dns-lookup/example.com, got %s", r.URL.Path)
+		}
+		if got := r.URL.Query().Get("recordType"); got != "A" {
+			t.Errorf("Expected recordType=A, got %q", got)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{"success":true,"hostname":"example.com","addresses":["93.184.216.34","93.184.216.35"]}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	result, err := client.Diagnostics.DNSLookupWithType("example.com", "a", false)
+	if err != nil {
+		t.Fatalf("DNSLookupWithType failed: %v", err)
+	}
+
+	if len(result.Records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(result.Records))
+	}
+
+	if result.Records[0].Type != "A" || result.Records[0].Value != "93.184.216.34" {
+		t.Errorf("Unexpected first record: %+v", result.Records[0])
+	}
+}
+
+func TestDiagnosticsService_DNSLookupTXT(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if got := r.URL.Query().Get("recordType"); got != "TXT" {
+			t.Errorf("Expected recordType=TXT, got %q", got)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{"success":true,"hostname":"example.com","addresses":[],"records":[
+			{"type":"TXT","name":"example.com","value":"v=spf1 -all","ttl":3600},
+			{"type":"TXT","name":"example.com","value":"wgyf8z8cgvm2qmxpnbnldrcltvk4xqfn"}
+		]}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	result, err := client.Diagnostics.DNSLookupWithType("example.com", "TXT", false)
+	if err != nil {
+		t.Fatalf("DNSLookupWithType failed: %v", err)
+	}
+
+	if len(result.Records) != 2 {
+		t.Fatalf("Expected 2 records, got %d", len(result.Records))
+	}
+
+	if result.Records[0].Value != "v=spf1 -all" || result.Records[0].TTL != 3600 {
+		t.Errorf("Unexpected first record: %+v", result.Records[0])
+	}
+}
+
+func TestDiagnosticsService_DNSLookupDefault(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.RawQuery != "" {
+			t.Errorf("Expected no query string for the default lookup, got %q", r.URL.RawQuery)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{"success":true,"hostname":"example.com","addresses":["93.184.216.34"]}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	result, err := client.Diagnostics.DNSLookup("example.com", false)
+	if err != nil {
+		t.Fatalf("DNSLookup failed: %v", err)
+	}
+
+	if len(result.Addresses) != 1 || len(result.Records) != 0 {
+		t.Errorf("Expected addresses only, got %+v", result)
+	}
+
+	if _, err := client.Diagnostics.DNSLookupWithType("example.com", "SRV", false); err == nil {
+		t.Error("Expected an error for an unsupported record type")
+	}
+}
+
+func TestDiagnosticsService_TraceRouteWithOptions(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/diagnostics/trace-route/8.8.8.8" {
+			t.Errorf("Expected path /api/v1/diagnostics/trace-route/8.8.8.8, got %s", r.URL.Path)
+		}
+		query := r.URL.Query()
+		if query.Get("maxHops") != "5" || query.Get("timeout") != "2" || query.Get("resolveAddress") != "true" {
+			t.Errorf("Unexpected query string %q", r.URL.RawQuery)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{"success":true,"target":"8.8.8.8","hops":[
+			{"number":1,"address":"192.168.1.1","hostname":"router.lan","rtt":0.52},
+			{"number":2,"address":"8.8.8.8","rtt":12.3}
+		]}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	result, err := client.Diagnostics.TraceRouteWithOptions("8.8.8.8", &TraceRouteOptions{
+		ResolveAddress: true,
+		MaxHops:        5,
+		Timeout:        2,
+	})
+	if err != nil {
+		t.Fatalf("TraceRouteWithOptions failed: %v", err)
+	}
+
+	if len(result.Hops) != 2 || result.Hops[0].Hostname != "router.lan" {
+		t.Errorf("Unexpected hops: %+v", result.Hops)
+	}
+}
+
+func TestTraceRouteResultJSONFieldNames(t *testing.T) {
+	result := TraceRouteResult{
+		Success: true,
+		Target:  "8.8.8.8",
+		Hops: []TraceHop{
+			{Number: 1, Address: "192.168.1.1", Hostname: "router.lan", RTT: 0.5},
+		},
+	}
+
+	data, err := json.Marshal(result)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+
+	for _, key := range []string{"success", "target", "hops"} {
+		if _, ok := decoded[key]; !ok {
+			t.Errorf("Expected key %q in %s", key, data)
+		}
+	}
+
+	hops, ok := decoded["hops"].([]interface{})
+	if !ok || len(hops) != 1 {
+		t.Fatalf("Expected hops to be a JSON array with 1 entry, got %s", data)
+	}
+
+	hop := hops[0].(map[string]interface{})
+	for _, key := range []string{"number", "address", "hostname", "rtt"} {
+		if _, ok := hop[key]; !ok {
+			t.Errorf("Expected hop key %q in %s", key, data)
+		}
+	}
+}
+
+func TestDiagnosticsService_SetNetworkConfiguration(t *testing.T) {
+	tests := []struct {
+		name      string
+		body      string
+		reachable bool
+		ip        string
+		errText   string
+	}{
+		{
+			name:      "reachable",
+			body:      `{"data":{"result":{"reachable":true,"config":{"interface":"eth0","dhcp":false,"ip":"192.168.1.50","netmask":"255.255.255.0","gateway":"192.168.1.1"}}}}`,
+			reachable: true,
+			ip:        "192.168.1.50",
+		},
+		{
+			name:      "unreachable",
+			body:      `{"data":{"result":{"reachable":false,"error":"gateway 10.0.0.1 not reachable"}}}`,
+			reachable: false,
+			ip:        "10.0.0.50",
+			errText:   "gateway 10.0.0.1 not reachable",
+		},
+		{
+			name:      "success field",
+			body:      `{"data":{"result":{"success":false}}}`,
+			reachable: false,
+			ip:        "10.0.0.50",
+		},
+		{
+			name:      "empty body",
+			body:      "",
+			reachable: true,
+			ip:        "10.0.0.50",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != "PUT" || r.URL.Path != "/api/v1/diagnostics/network-configuration/eth0/" {
+					t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+				}
+
+				var config NetworkConfig
+				if err := json.NewDecoder(r.Body).Decode(&config); err != nil {
+					t.Errorf("Failed to decode request body: %v", err)
+				}
+
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(test.body))
+			}))
+			defer server.Close()
+
+			client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+			client.baseURL = server.URL + "/api/v1"
+
+			result, err := client.Diagnostics.SetNetworkConfiguration("eth0", NetworkConfig{
+				Interface: "eth0",
+				IP:        "10.0.0.50",
+				Netmask:   "255.255.255.0",
+				Gateway:   "10.0.0.1",
+			})
+			if err != nil {
+				t.Fatalf("SetNetworkConfiguration failed: %v", err)
+			}
+
+			if result.Reachable != test.reachable {
+				t.Errorf("Expected reachable %v, got %v", test.reachable, result.Reachable)
+			}
+			if result.AppliedConfig.IP != test.ip {
+				t.Errorf("Expected applied IP %s, got %s", test.ip, result.AppliedConfig.IP)
+			}
+			if result.Error != test.errText {
+				t.Errorf("Expected error %q, got %q", test.errText, result.Error)
+			}
+		})
+	}
+}
+
+func TestDiagnosticsService_SetNetworkConfigurationNotJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.Write([]byte("<html>Proxy login</html>"))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	_, err := client.Diagnostics.SetNetworkConfiguration("eth0", NetworkConfig{Interface: "eth0", DHCP: true})
+	if !errors.Is(err, ErrInvalidResponse) {
+		t.Fatalf("Expected ErrInvalidResponse, got %v", err)
+	}
+}
+
+func TestDiagnosticsService_RunDiagnostics(t *testing.T) {
+	tests := []struct {
+		name     string
+		result   string
+		expected []DiagnosticResult
+	}{
+		{
+			"array",
+			`[{"test":"ethernet","status":"pass","duration":12},{"test":"dns","status":"fail","message":"timeout","time":"1.5s"}]`,
+			[]DiagnosticResult{
+				{Test: "ethernet", Status: "pass", DurationMs: 12},
+				{Test: "dns", Status: "fail", Message: "timeout", DurationMs: 1500},
+			},
+		},
+		{
+			"single object",
+			`{"test":"internet","status":"pass","message":"reachable"}`,
+			[]DiagnosticResult{{Test: "internet", Status: "pass", Message: "reachable"}},
+		},
+		{
+			"keyed by test",
+			`{"wifi":{"result":false,"details":"no signal"},"ethernet":"pass"}`,
+			[]DiagnosticResult{
+				{Test: "ethernet", Status: "pass"},
+				{Test: "wifi", Status: "fail", Message: "no signal"},
+			},
+		},
+		{
+			"unknown shape",
+			`{"interfaces":{"eth0":"up"}}`,
+			nil,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/v1/diagnostics/" {
+					t.Errorf("Expected path /api/v1/diagnostics/, got %s", r.URL.Path)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"data":{"result":` + tt.result + `}}`))
+			}))
+			defer server.Close()
+
+			client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+			client.baseURL = server.URL + "/api/v1"
+
+			report, err := client.Diagnostics.RunDiagnostics()
+			if err != nil {
+				t.Fatalf("RunDiagnostics failed: %v", err)
+			}
+
+			if !reflect.DeepEqual(report.Results, tt.expected) {
+				t.Errorf("Expected results %+v, got %+v", tt.expected, report.Results)
+			}
+			if report.Raw == nil {
+				t.Error("Expected the raw response to be kept")
+			}
+		})
+	}
+}
+
+func TestDiagnosticResult_Passed(t *testing.T) {
+	for status, expected := range map[string]bool{"pass": true, "PASSED": true, "ok": true, "fail": false, "": false, "warning": false} {
+		if got := (DiagnosticResult{Status: status}).Passed(); got != expected {
+			t.Errorf("Passed() for status %q = %v, expected %v", status, got, expected)
+		}
+	}
+}
+
+func TestDiagnosticsService_WiFiScan(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{
+			name: "list",
+			body: `{"data":{"result":[{"ssid":"lobby","bssid":"aa:bb:cc:dd:ee:01","signal":-71,"channel":6,"security":"WPA2"},{"ssid":"office","bssid":"aa:bb:cc:dd:ee:02","signal":-48,"channel":36,"security":"WPA2"}]}}`,
+		},
+		{
+			name: "wrapped",
+			body: `{"data":{"result":{"networks":[{"ssid":"lobby","bssid":"aa:bb:cc:dd:ee:01","signal":-71,"channel":6,"security":"WPA2"},{"ssid":"office","bssid":"aa:bb:cc:dd:ee:02","signal":-48,"channel":36,"security":"WPA2"}]}}}`,
+		},
+	}
+
+	expected := []WiFiNetwork{
+		{SSID: "office", BSSID: "aa:bb:cc:dd:ee:02", Signal: -48, Channel: 36, Security: "WPA2"},
+		{SSID: "lobby", BSSID: "aa:bb:cc:dd:ee:01", Signal: -71, Channel: 6, Security: "WPA2"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != "GET" || r.URL.Path != "/api/v1/diagnostics/wifi-scan/" {
+					t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(test.body))
+			}))
+			defer server.Close()
+
+			client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+			client.baseURL = server.URL + "/api/v1"
+
+			networks, err := client.Diagnostics.WiFiScan()
+			if err != nil {
+				t.Fatalf("WiFiScan failed: %v", err)
+			}
+			if !reflect.DeepEqual(networks, expected) {
+				t.Errorf("Expected %+v, got %+v", expected, networks)
+			}
+		})
+	}
+}
+
+func TestDiagnosticsService_PingIPv6(t *testing.T) {
+	tests := []struct {
+		address string
+		path    string
+	}{
+		{"::1", "/api/v1/diagnostics/ping/%3A%3A1"},
+		{"[fe80::1%eth0]", "/api/v1/diagnostics/ping/fe80%3A%3A1%25eth0"},
+		{"8.8.8.8", "/api/v1/diagnostics/ping/8.8.8.8"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.EscapedPath() != tt.path {
+					t.Errorf("Expected path %s, got %s", tt.path, r.URL.EscapedPath())
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"data":{"result":{"success":true}}}`))
+			}))
+			defer server.Close()
+
+			client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+			client.baseURL = server.URL + "/api/v1"
+
+			if _, err := client.Diagnostics.Ping(tt.address); err != nil {
+				t.Fatalf("Ping failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestValidateTarget(t *testing.T) {
+	valid := []string{"8.8.8.8", "::1", "[2001:db8::1]", "fe80::1%eth0", "example.com", "player-1.local", "_dmarc.example.com"}
+	for _, address := range valid {
+		if err := ValidateTarget(address); err != nil {
+			t.Errorf("ValidateTarget(%q) failed: %v", address, err)
+		}
+	}
+
+	invalid := []string{"", "bad host", "a/b", "foo:bar", "-leading.example.com", "example..com", "host?x=1"}
+	for _, address := range invalid {
+		if err := ValidateTarget(address); err == nil {
+			t.Errorf("ValidateTarget(%q) succeeded, expected an error", address)
+		}
+	}
+}
+
+func TestFilterDiagnostics(t *testing.T) {
+	results := []DiagnosticResult{
+		{Test: "ethernet", Status: "pass"},
+		{Test: "dns", Status: "fail"},
+		{Test: "internet", Status: "pass"},
+	}
+
+	names := func(results []DiagnosticResult) []string {
+		var out []string
+		for _, result := range results {
+			out = append(out, result.Test)
+		}
+		return out
+	}
+
+	tests := []struct {
+		name string
+		only []string
+		skip []string
+		want []string
+	}{
+		{"all", nil, nil, []string{"ethernet", "dns", "internet"}},
+		{"only", []string{"DNS", "internet"}, nil, []string{"dns", "internet"}},
+		{"skip", nil, []string{"dns"}, []string{"ethernet", "internet"}},
+		{"only and skip", []string{"dns", "internet"}, []string{"dns"}, []string{"internet"}},
+		{"no match", []string{"wifi"}, nil, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := names(FilterDiagnostics(results, tt.only, tt.skip))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestNetworkConfigPayload(t *testing.T) {
+	tests := []struct {
+		name   string
+		config NetworkConfig
+		want   string
+	}{
+		{
+			name:   "unchanged DNS and VLAN",
+			config: NetworkConfig{Interface: "eth0", DHCP: true},
+			want:   `{"interface":"eth0","dhcp":true}`,
+		},
+		{
+			name:   "multiple DNS servers",
+			config: NetworkConfig{Interface: "eth0", IP: "10.0.0.5", DNS: []string{"10.0.0.2", "10.0.0.3"}, VLANID: 20},
+			want:   `{"interface":"eth0","dhcp":false,"ip":"10.0.0.5","dns":["10.0.0.2","10.0.0.3"],"vlanId":20}`,
+		},
+		{
+			name:   "clear VLAN",
+			config: NetworkConfig{Interface: "eth0", DHCP: true, VLANID: 20, ClearVLAN: true},
+			want:   `{"interface":"eth0","dhcp":true,"vlanId":0}`,
+		},
+		{
+			name:   "clear DNS",
+			config: NetworkConfig{Interface: "eth0", DHCP: true, ClearDNS: true},
+			want:   `{"interface":"eth0","dhcp":true,"dns":[]}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ = io.ReadAll(r.Body)
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"data":{"result":{"reachable":true}}}`))
+			}))
+			defer server.Close()
+
+			client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+			client.baseURL = server.URL + "/api/v1"
+
+			if _, err := client.Diagnostics.SetNetworkConfiguration("eth0", tt.config); err != nil {
+				t.Fatalf("SetNetworkConfiguration failed: %v", err)
+			}
+			if got := strings.TrimSpace(string(body)); got != tt.want {
+				t.Errorf("Expected payload %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package brightsign
+
+import (
+	"fmt"
+	"sort"
+)
+
+// FieldDiff is a field whose value differs between two players
+type FieldDiff struct {
+	Field string `json:"field"`
+	A     string `json:"a"`
+	B     string `json:"b"`
+}
+
+// DiffDeviceInfo returns the fields that differ between a and b. Fields that
+// are unique to every player (serial, uptime, MAC addresses) are not compared.
+// Interfaces are matched by name; one missing on a side has empty values there.
+func DiffDeviceInfo(a, b *DeviceInfo) []FieldDiff {
+	var diffs []FieldDiff
+	compare := func(field, va, vb string) {
+		if va != vb {
+			diffs = append(diffs, FieldDiff{Field: field, A: va, B: vb})
+		}
+	}
+
+	compare("model", a.Model, b.Model)
+	compare("family", a.Family, b.Family)
+	compare("bootVersion", a.BootVersion, b.BootVersion)
+	compare("fwVersion", a.FWVersion, b.FWVersion)
+	compare("network.hostname", a.Network.Hostname, b.Network.Hostname)
+
+	ifacesA := interfacesByName(a.Network.Interfaces)
+	ifacesB := interfacesByName(b.Network.Interfaces)
+
+	var names []string
+	for name := range ifacesA {
+		names = append(names, name)
+	}
+	for name := range ifacesB {
+		if _, ok := ifacesA[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		ia, okA := ifacesA[name]
+		ib, okB := ifacesB[name]
+		prefix := fmt.Sprintf("network.interfaces[%s]", name)
+
+		if okA != okB {
+			compare(prefix, presence(okA), presence(okB))
+		}
+		compare(prefix+".type", ia.Type, ib.Type)
+		compare(prefix+".proto", ia.Proto, ib.Proto)
+		compare(prefix+".ip", ia.IP, ib.IP)
+		compare(prefix+".netmask", ia.Netmask, ib.Netmask)
+		compare(prefix+".gateway", ia.Gateway, ib.Gateway)
+		compare(prefix+".dns", ia.DNS, ib.DNS)
+		compare(prefix+".metric", fmt.Sprint(ia.Metric), fmt.Sprint(ib.Metric))
+	}
+
+	return diffs
+}
+
+// interfacesByName indexes interfaces by name
+func interfacesByName(interfaces []NetworkInterface) map[string]NetworkInterface {
+	byName := make(map[string]NetworkInterface, len(interfaces))
+	for _, iface := range interfaces {
+		byName[iface.Name] = iface
+	}
+	return byName
+}
+
+// presence describes whether an interface exists on a player
+func presence(ok bool) string {
+	if ok {
+		return "present"
+	}
+	return "missing"
+}
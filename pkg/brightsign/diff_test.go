@@ -0,0 +1,59 @@
+package brightsign
+
+import (
+	"reflect"
+	"testing"
+)
+
+func sampleDeviceInfo() *DeviceInfo {
+	return &DeviceInfo{
+		Model:         "XT1144",
+		Serial:        "D7E8A1000001",
+		Family:        "malibu",
+		BootVersion:   "8.0.20",
+		FWVersion:     "9.0.110",
+		Uptime:        "1 day",
+		UptimeSeconds: 86400,
+		Network: NetworkInfo{
+			Hostname: "lobby",
+			Interfaces: []NetworkInterface{
+				{Name: "eth0", Type: "ethernet", Proto: "dhcp", IP: "192.168.1.10", Netmask: "255.255.255.0", Gateway: "192.168.1.1", MAC: "90:ac:3f:00:00:01"},
+			},
+		},
+	}
+}
+
+func TestDiffDeviceInfoIdentical(t *testing.T) {
+	a := sampleDeviceInfo()
+	b := sampleDeviceInfo()
+
+	// Identity fields are not compared
+	b.Serial = "D7E8A1000002"
+	b.Uptime = "3 days"
+	b.UptimeSeconds = 259200
+	b.Network.Interfaces[0].MAC = "90:ac:3f:00:00:02"
+
+	if diffs := DiffDeviceInfo(a, b); len(diffs) != 0 {
+		t.Errorf("Expected no differences, got %+v", diffs)
+	}
+}
+
+func TestDiffDeviceInfoDiffering(t *testing.T) {
+	a := sampleDeviceInfo()
+	b := sampleDeviceInfo()
+	b.FWVersion = "8.5.47"
+	b.Network.Interfaces[0].IP = "192.168.1.11"
+	b.Network.Interfaces = append(b.Network.Interfaces, NetworkInterface{Name: "wlan0", Type: "wifi", Proto: "dhcp"})
+
+	expected := []FieldDiff{
+		{Field: "fwVersion", A: "9.0.110", B: "8.5.47"},
+		{Field: "network.interfaces[eth0].ip", A: "192.168.1.10", B: "192.168.1.11"},
+		{Field: "network.interfaces[wlan0]", A: "missing", B: "present"},
+		{Field: "network.interfaces[wlan0].type", A: "", B: "wifi"},
+		{Field: "network.interfaces[wlan0].proto", A: "", B: "dhcp"},
+	}
+
+	if diffs := DiffDeviceInfo(a, b); !reflect.DeepEqual(diffs, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, diffs)
+	}
+}
@@ -0,0 +1,173 @@
+package brightsign
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// discoverConcurrency bounds the number of hosts probed at once
+const discoverConcurrency = 64
+
+// maxDiscoverHosts keeps sweeps to a /16 or smaller
+const maxDiscoverHosts = 1 << 16
+
+// DiscoveredPlayer describes a player found by DiscoverPlayers
+type DiscoveredPlayer struct {
+	Host      string `json:"host"`
+	Model     string `json:"model,omitempty"`
+	Serial    string `json:"serial,omitempty"`
+	Family    string `json:"family,omitempty"`
+	FWVersion string `json:"fwVersion,omitempty"`
+
+	// AuthRequired is set when the player answered with a digest challenge,
+	// so model and serial could not be read without credentials
+	AuthRequired bool `json:"authRequired"`
+}
+
+// DiscoverPlayers sweeps every address in cidr (e.g. "192.168.1.0/24") and
+// returns the hosts whose DWS answers /api/v1/info/. timeout applies to each
+// probe. Results are sorted by address.
+func DiscoverPlayers(cidr string, timeout time.Duration) ([]DiscoveredPlayer, error) {
+	hosts, err := expandCIDR(cidr)
+	if err != nil {
+		return nil, err
+	}
+	return discoverHosts(hosts, timeout), nil
+}
+
+// expandCIDR lists the host addresses in an IPv4 CIDR range, excluding the
+// network and broadcast addresses where they exist
+func expandCIDR(cidr string) ([]string, error) {
+	ip, ipNet, err := net.ParseCIDR(cidr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid subnet %q: %w", cidr, err)
+	}
+	if ip.To4() == nil {
+		return nil, fmt.Errorf("invalid subnet %q: only IPv4 is supported", cidr)
+	}
+
+	ones, bits := ipNet.Mask.Size()
+	size := 1 << (bits - ones)
+	if size > maxDiscoverHosts {
+		return nil, fmt.Errorf("subnet %q is too large to sweep (limit is /16)", cidr)
+	}
+
+	start := ipNet.IP.To4()
+	base := uint32(start[0])<<24 | uint32(start[1])<<16 | uint32(start[2])<<8 | uint32(start[3])
+
+	first, last := 0, size-1
+	if size > 2 {
+		first, last = 1, size-2
+	}
+
+	hosts := make([]string, 0, last-first+1)
+	for i := first; i <= last; i++ {
+		n := base + uint32(i)
+		hosts = append(hosts, net.IPv4(byte(n>>24), byte(n>>16), byte(n>>8), byte(n)).String())
+	}
+	return hosts, nil
+}
+
+// discoverHosts probes hosts (address or address:port) with bounded concurrency
+func discoverHosts(hosts []string, timeout time.Duration) []DiscoveredPlayer {
+	httpClient := &http.Client{Timeout: timeout}
+
+	var mu sync.Mutex
+	var players []DiscoveredPlayer
+
+	jobs := make(chan string)
+	var wg sync.WaitGroup
+	for w := 0; w < discoverConcurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for host := range jobs {
+				if player, ok := probePlayer(httpClient, host); ok {
+					mu.Lock()
+					players = append(players, player)
+					mu.Unlock()
+				}
+			}
+		}()
+	}
+
+	for _, host := range hosts {
+		jobs <- host
+	}
+	close(jobs)
+	wg.Wait()
+
+	sort.Slice(players, func(i, j int) bool {
+		return hostLess(players[i].Host, players[j].Host)
+	})
+	return players
+}
+
+// probePlayer checks whether host runs a DWS
+func probePlayer(httpClient *http.Client, host string) (DiscoveredPlayer, bool) {
+	resp, err := httpClient.Get(fmt.Sprintf("http://%s/api/v1/info/", host))
+	if err != nil {
+		return DiscoveredPlayer{}, false
+	}
+	defer resp.Body.Close()
+
+	player := DiscoveredPlayer{Host: host}
+
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		if !strings.HasPrefix(resp.Header.Get("WWW-Authenticate"), "Digest") {
+			return DiscoveredPlayer{}, false
+		}
+		player.AuthRequired = true
+		return player, true
+	case http.StatusOK:
+		var result struct {
+			Data struct {
+				Result DeviceInfo `json:"result"`
+			} `json:"data"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&result); err != nil || result.Data.Result.Model == "" {
+			return DiscoveredPlayer{}, false
+		}
+		info := result.Data.Result
+		player.Model = info.Model
+		player.Serial = info.Serial
+		player.Family = info.Family
+		player.FWVersion = info.FWVersion
+		return player, true
+	}
+
+	return DiscoveredPlayer{}, false
+}
+
+// hostLess orders hosts numerically by address, then by port
+func hostLess(a, b string) bool {
+	hostA, portA := splitHostPort(a)
+	hostB, portB := splitHostPort(b)
+
+	ipA, ipB := net.ParseIP(hostA).To4(), net.ParseIP(hostB).To4()
+	if ipA != nil && ipB != nil {
+		for i := range ipA {
+			if ipA[i] != ipB[i] {
+				return ipA[i] < ipB[i]
+			}
+		}
+		return portA < portB
+	}
+	return a < b
+}
+
+// splitHostPort splits host:port, returning the port zero-padded for ordering
+func splitHostPort(hostport string) (string, string) {
+	host, port, err := net.SplitHostPort(hostport)
+	if err != nil {
+		return hostport, ""
+	}
+	return host, fmt.Sprintf("%05s", port)
+}
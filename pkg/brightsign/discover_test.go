@@ -0,0 +1,77 @@
+package brightsign
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestDiscoverHosts(t *testing.T) {
+	open := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/info/" {
+			t.Errorf("Expected path /api/v1/info/, got %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{"model":"XT1144","serial":"D7E8A1000001","family":"malibu","fwVersion":"9.0.110"}}}`))
+	}))
+	defer open.Close()
+
+	protected := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("WWW-Authenticate", `Digest realm="BrightSign", nonce="abc123", qop="auth"`)
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer protected.Close()
+
+	other := httptest.NewServer(http.NotFoundHandler())
+	defer other.Close()
+
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closedHost := closed.URL[7:]
+	closed.Close()
+
+	players := discoverHosts([]string{open.URL[7:], protected.URL[7:], other.URL[7:], closedHost}, time.Second)
+
+	if len(players) != 2 {
+		t.Fatalf("Expected 2 players, got %d: %+v", len(players), players)
+	}
+
+	found := make(map[string]DiscoveredPlayer)
+	for _, player := range players {
+		found[player.Host] = player
+	}
+
+	if p := found[open.URL[7:]]; p.Model != "XT1144" || p.Serial != "D7E8A1000001" || p.AuthRequired {
+		t.Errorf("Unexpected open player: %+v", p)
+	}
+
+	if p, ok := found[protected.URL[7:]]; !ok || !p.AuthRequired {
+		t.Errorf("Expected protected player to require auth: %+v", p)
+	}
+}
+
+func TestExpandCIDR(t *testing.T) {
+	hosts, err := expandCIDR("192.168.1.0/30")
+	if err != nil {
+		t.Fatalf("expandCIDR failed: %v", err)
+	}
+	if len(hosts) != 2 || hosts[0] != "192.168.1.1" || hosts[1] != "192.168.1.2" {
+		t.Errorf("Expected usable hosts of /30, got %v", hosts)
+	}
+
+	hosts, err = expandCIDR("10.0.0.7/32")
+	if err != nil || len(hosts) != 1 || hosts[0] != "10.0.0.7" {
+		t.Errorf("Expected single host for /32, got %v, %v", hosts, err)
+	}
+
+	hosts, err = expandCIDR("10.0.0.0/24")
+	if err != nil || len(hosts) != 254 {
+		t.Errorf("Expected 254 hosts for /24, got %d, %v", len(hosts), err)
+	}
+
+	for _, bad := range []string{"10.0.0.0/8", "not-a-cidr", "fe80::/64"} {
+		if _, err := expandCIDR(bad); err == nil {
+			t.Errorf("Expected an error for %s", bad)
+		}
+	}
+}
@@ -1,9 +1,5 @@
 package brightsign
 
-import (
-	"fmt"
-)
-
 // DisplayService handles display control endpoints (Moka displays, BOS 9.0.189+)
 type DisplayService struct {
 	client *Client
@@ -135,13 +131,9 @@ func (s *DisplayService) SetBrightness(value int) error {
 	if err != nil {
 		return err
 	}
-	resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("failed to set brightness: status %d", resp.StatusCode)
-	}
+	defer resp.Body.Close()
 
-	return nil
+	return checkResponse(resp, "failed to set brightness")
 }
 
 // GetContrast returns contrast settings
@@ -171,13 +163,9 @@ func (s *DisplayService) SetContrast(value int) error {
 	if err != nil {
 		return err
 	}
-	resp.Body.Close()
+	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("failed to set contrast: status %d", resp.StatusCode)
-	}
-
-	return nil
+	return checkResponse(resp, "failed to set contrast")
 }
 
 // GetVolume returns volume settings
@@ -207,13 +195,9 @@ func (s *DisplayService) SetVolume(value int) error {
 	if err != nil {
 		return err
 	}
-	resp.Body.Close()
+	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("failed to set volume: status %d", resp.StatusCode)
-	}
-
-	return nil
+	return checkResponse(resp, "failed to set volume")
 }
 
 // GetPowerSettings returns power settings
@@ -243,13 +227,9 @@ func (s *DisplayService) SetPowerSettings(state string) error {
 	if err != nil {
 		return err
 	}
-	resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("failed to set power settings: status %d", resp.StatusCode)
-	}
+	defer resp.Body.Close()
 
-	return nil
+	return checkResponse(resp, "failed to set power settings")
 }
 
 // GetInfo returns display information
@@ -279,11 +259,7 @@ func (s *DisplayService) UpdateFirmware(filepathOrURL string) error {
 	if err != nil {
 		return err
 	}
-	resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("failed to update firmware: status %d", resp.StatusCode)
-	}
+	defer resp.Body.Close()
 
-	return nil
+	return checkResponse(resp, "failed to update firmware")
 }
\ No newline at end of file
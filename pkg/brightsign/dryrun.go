@@ -0,0 +1,46 @@
+package brightsign
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// ErrDryRun is returned in place of a response for mutating requests that
+// were logged instead of sent because Config.DryRun is set. It is not a
+// failure; callers should stop, or move on to the next request, without
+// reporting success.
+var ErrDryRun = errors.New("dry run: request not sent")
+
+// isMutating reports whether a request changes player state. The DWS starts
+// firmware downloads with a GET, so that endpoint is treated as mutating too.
+func isMutating(method, path string) bool {
+	if method == http.MethodGet || method == http.MethodHead {
+		return strings.Contains(path, "/download-firmware/")
+	}
+	return true
+}
+
+// dryRunResponse logs a request that would have been sent and returns
+// ErrDryRun in place of a response
+func (c *Client) dryRunResponse(req *http.Request, body io.Reader, contentType string) (*http.Response, error) {
+	fmt.Fprintf(c.logger, "DRY RUN: would send %s %s\n", req.Method, redact(req.URL.String()))
+
+	if body != nil {
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read request body: %w", err)
+		}
+		switch {
+		case len(data) == 0:
+		case strings.HasPrefix(contentType, "application/json"):
+			fmt.Fprintf(c.logger, "DRY RUN: body: %s\n", redact(string(data)))
+		default:
+			fmt.Fprintf(c.logger, "DRY RUN: body: %d bytes of %s\n", len(data), contentType)
+		}
+	}
+
+	return nil, ErrDryRun
+}
@@ -0,0 +1,112 @@
+package brightsign
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDryRunSkipsMutatingRequests(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{"status":"running"}}}`))
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	client := NewClient(Config{Host: server.URL[7:], Password: "password", DryRun: true, Logger: &logs})
+	client.baseURL = server.URL + "/api/v1"
+
+	if err := client.Storage.DeleteFile("/storage/sd/video.mp4"); !errors.Is(err, ErrDryRun) {
+		t.Fatalf("Expected ErrDryRun from DeleteFile, got %v", err)
+	}
+	if err := client.Registry.SetValue("networking", "ssh", "22"); !errors.Is(err, ErrDryRun) {
+		t.Fatalf("Expected ErrDryRun from SetValue, got %v", err)
+	}
+	if err := client.Control.SetDWSPassword(DWSPassword{Password: "hunter2"}); !errors.Is(err, ErrDryRun) {
+		t.Fatalf("Expected ErrDryRun from SetDWSPassword, got %v", err)
+	}
+	if err := client.Control.DownloadFirmware("http://fw.example.com/update.bsfw"); !errors.Is(err, ErrDryRun) {
+		t.Fatalf("Expected ErrDryRun from DownloadFirmware, got %v", err)
+	}
+
+	if len(requests) != 0 {
+		t.Errorf("Expected no requests in dry-run mode, got %v", requests)
+	}
+
+	output := logs.String()
+	for _, expected := range []string{
+		"DRY RUN: would send DELETE " + server.URL + "/api/v1/files/sd/video.mp4",
+		"DRY RUN: would send PUT " + server.URL + "/api/v1/registry/networking/ssh/",
+		`"value":"22"`,
+		"DRY RUN: would send GET " + server.URL + "/api/v1/download-firmware/",
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("Expected %q in dry-run output:\n%s", expected, output)
+		}
+	}
+	if strings.Contains(output, "hunter2") {
+		t.Errorf("Dry-run output leaked the password:\n%s", output)
+	}
+
+	// Reads are still sent
+	health, err := client.Info.GetHealth()
+	if err != nil {
+		t.Fatalf("GetHealth failed: %v", err)
+	}
+	if health.Status != "running" || len(requests) != 1 {
+		t.Errorf("Expected the read to reach the server, got status %q and requests %v", health.Status, requests)
+	}
+}
+
+func TestDryRunLogsEveryStepOfBatchOperations(t *testing.T) {
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/files/sd/media/":
+			w.Write([]byte(`{"data":{"result":[{"name":"a.mp4","type":"file"},{"name":"b.mp4","type":"file"}]}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	var logs bytes.Buffer
+	client := NewClient(Config{Host: server.URL[7:], Password: "password", DryRun: true, Logger: &logs})
+	client.baseURL = server.URL + "/api/v1"
+
+	written, err := client.Registry.SetBatch("networking", map[string]string{"a": "1", "b": "2"})
+	if !errors.Is(err, ErrDryRun) || len(written) != 0 {
+		t.Errorf("Expected ErrDryRun and nothing written, got %v and %v", err, written)
+	}
+
+	deleted, err := client.Storage.DeleteRecursive("/storage/sd/media")
+	if !errors.Is(err, ErrDryRun) || deleted != 0 {
+		t.Errorf("Expected ErrDryRun and nothing deleted, got %v and %d", err, deleted)
+	}
+
+	output := logs.String()
+	for _, expected := range []string{
+		"PUT " + server.URL + "/api/v1/registry/networking/a/",
+		"PUT " + server.URL + "/api/v1/registry/networking/b/",
+		"DELETE " + server.URL + "/api/v1/files/sd/media/b.mp4",
+		"DELETE " + server.URL + "/api/v1/files/sd/media/a.mp4",
+		"DELETE " + server.URL + "/api/v1/files/sd/media/",
+	} {
+		if !strings.Contains(output, "DRY RUN: would send "+expected+"\n") {
+			t.Errorf("Expected %q in dry-run output:\n%s", expected, output)
+		}
+	}
+	for _, request := range requests {
+		if !strings.HasPrefix(request, "GET ") {
+			t.Errorf("Expected only reads to be sent, got %v", requests)
+		}
+	}
+}
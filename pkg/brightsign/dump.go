@@ -0,0 +1,44 @@
+package brightsign
+
+import (
+	"io"
+	"net/http"
+	"os"
+)
+
+// maxDumpDrain limits how much of a body left unread by the caller is still
+// copied to the dump file on close, so an abandoned stream does not block
+const maxDumpDrain = 1 << 20
+
+// dumpBody copies a response body to the dump file as it is read and closes
+// both together. Closing first copies what the caller did not read, such as
+// the rest of a body whose decoding failed early.
+type dumpBody struct {
+	io.Reader
+	body io.ReadCloser
+	file *os.File
+}
+
+func (d *dumpBody) Close() error {
+	io.CopyN(d.file, d.body, maxDumpDrain)
+	d.file.Close()
+	return d.body.Close()
+}
+
+// dumpResponse arranges for resp's body to be written to the configured dump
+// file as the caller reads it. The file is replaced for every response, so
+// after a command it holds the body of the last request. Failing to create it
+// only logs a debug message.
+func (c *Client) dumpResponse(resp *http.Response) {
+	if c.dumpPath == "" || resp.Body == nil {
+		return
+	}
+
+	file, err := os.Create(c.dumpPath)
+	if err != nil {
+		c.debugf("response not dumped: %v", err)
+		return
+	}
+	c.debugf("dumping response body to %s", c.dumpPath)
+	resp.Body = &dumpBody{Reader: io.TeeReader(resp.Body, file), body: resp.Body, file: file}
+}
@@ -0,0 +1,85 @@
+package brightsign
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDumpResponse(t *testing.T) {
+	// An unexpected shape, as a firmware change might send
+	const body = "{\"data\":{\"result\":[\"not\",\"an\",\"object\"]}}\r\n\x00trailing"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	dumpPath := filepath.Join(t.TempDir(), "response.bin")
+	client := NewClient(Config{Host: server.URL[7:], Password: "password", DumpResponse: dumpPath})
+	client.baseURL = server.URL + "/api/v1"
+
+	if _, err := client.Info.GetInfo(); err == nil {
+		t.Fatal("Expected the unexpected shape to fail to parse")
+	}
+
+	data, err := os.ReadFile(dumpPath)
+	if err != nil {
+		t.Fatalf("Dump file not written: %v", err)
+	}
+	if string(data) != body {
+		t.Errorf("Expected the exact response bytes %q, got %q", body, data)
+	}
+}
+
+func TestDumpResponseUnwritable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{"model":"XD1034"}}}`))
+	}))
+	defer server.Close()
+
+	// A dump that cannot be written must not break the request
+	client := NewClient(Config{Host: server.URL[7:], Password: "password", DumpResponse: filepath.Join(t.TempDir(), "missing", "response.bin")})
+	client.baseURL = server.URL + "/api/v1"
+
+	info, err := client.Info.GetInfo()
+	if err != nil {
+		t.Fatalf("GetInfo failed: %v", err)
+	}
+	if info.Model != "XD1034" {
+		t.Errorf("Expected model XD1034, got %s", info.Model)
+	}
+}
+
+func TestDumpResponseUnreadRemainder(t *testing.T) {
+	const body = `{"data":{"result":{"model":"XD1034"}}} plus a remainder the decoder never reads`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	dumpPath := filepath.Join(t.TempDir(), "response.bin")
+	client := NewClient(Config{Host: server.URL[7:], Password: "password", DumpResponse: dumpPath})
+	client.baseURL = server.URL + "/api/v1"
+
+	resp, err := client.doRequest("GET", "/info/", nil)
+	if err != nil {
+		t.Fatalf("Request failed: %v", err)
+	}
+	buf := make([]byte, 10)
+	resp.Body.Read(buf)
+	resp.Body.Close()
+
+	data, err := os.ReadFile(dumpPath)
+	if err != nil {
+		t.Fatalf("Dump file not written: %v", err)
+	}
+	if string(data) != body {
+		t.Errorf("Expected the whole body to be dumped on close, got %q", data)
+	}
+}
@@ -0,0 +1,196 @@
+package brightsign
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// edidBlockSize is the size of the EDID base block and of each extension block
+const edidBlockSize = 128
+
+var edidHeader = []byte{0x00, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x00}
+
+// establishedTimings lists the modes flagged by bytes 35-37 of the base block,
+// most significant bit first
+var establishedTimings = [][]string{
+	{"720x400x70p", "720x400x88p", "640x480x60p", "640x480x67p", "640x480x72p", "640x480x75p", "800x600x56p", "800x600x60p"},
+	{"800x600x72p", "800x600x75p", "832x624x75p", "1024x768x87i", "1024x768x60p", "1024x768x70p", "1024x768x75p", "1280x1024x75p"},
+	{"1152x870x75p"},
+}
+
+// ParseEDIDHex converts a hex dump of an EDID blob to raw bytes. Whitespace,
+// colons and "0x" prefixes are ignored so the output of most tools can be pasted as is.
+func ParseEDIDHex(s string) ([]byte, error) {
+	s = strings.ReplaceAll(s, "0x", "")
+	s = strings.ReplaceAll(s, "0X", "")
+	s = strings.Map(func(r rune) rune {
+		if r == ':' || r == ' ' || r == '\t' || r == '\n' || r == '\r' {
+			return -1
+		}
+		return r
+	}, s)
+
+	raw, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EDID hex: %w", err)
+	}
+	return raw, nil
+}
+
+// DecodeEDID parses a raw EDID blob. Only the 128-byte base block is decoded;
+// extension blocks are ignored. Width and Height are the physical screen size in cm.
+func DecodeEDID(raw []byte) (*EDIDInfo, error) {
+	if len(raw) < edidBlockSize {
+		return nil, fmt.Errorf("EDID too short: %d bytes, need at least %d", len(raw), edidBlockSize)
+	}
+
+	block := raw[:edidBlockSize]
+	for i, b := range edidHeader {
+		if block[i] != b {
+			return nil, fmt.Errorf("invalid EDID header")
+		}
+	}
+
+	var sum byte
+	for _, b := range block {
+		sum += b
+	}
+	if sum != 0 {
+		return nil, fmt.Errorf("invalid EDID checksum")
+	}
+
+	// Manufacturer ID is three 5-bit letters, 'A' = 1
+	id := uint16(block[8])<<8 | uint16(block[9])
+	manufacturer := string([]byte{
+		byte('A' - 1 + (id>>10)&0x1F),
+		byte('A' - 1 + (id>>5)&0x1F),
+		byte('A' - 1 + id&0x1F),
+	})
+
+	productCode := uint16(block[10]) | uint16(block[11])<<8
+	serial := uint32(block[12]) | uint32(block[13])<<8 | uint32(block[14])<<16 | uint32(block[15])<<24
+
+	info := &EDIDInfo{
+		Manufacturer:      manufacturer,
+		Product:           fmt.Sprintf("0x%04X", productCode),
+		WeekOfManufacture: int(block[16]),
+		YearOfManufacture: int(block[17]) + 1990,
+		Version:           fmt.Sprintf("%d.%d", block[18], block[19]),
+		Digital:           block[20]&0x80 != 0,
+		Width:             int(block[21]),
+		Height:            int(block[22]),
+	}
+	if serial != 0 {
+		info.SerialNumber = fmt.Sprintf("%d", serial)
+	}
+
+	seen := make(map[string]bool)
+	addMode := func(mode string) {
+		if !seen[mode] {
+			seen[mode] = true
+			info.SupportedModes = append(info.SupportedModes, mode)
+		}
+	}
+
+	// Descriptors: detailed timings come first since the first one is the preferred mode
+	for offset := 54; offset < 126; offset += 18 {
+		d := block[offset : offset+18]
+
+		if d[0] != 0 || d[1] != 0 {
+			if mode := decodeDetailedTiming(d); mode != "" {
+				addMode(mode)
+			}
+			continue
+		}
+
+		text := descriptorText(d)
+		if text == "" {
+			continue
+		}
+		switch d[3] {
+		case 0xFC:
+			info.Product = text
+		case 0xFF:
+			info.SerialNumber = text
+		}
+	}
+
+	// Standard timings
+	for offset := 38; offset < 54; offset += 2 {
+		if mode := decodeStandardTiming(block[offset], block[offset+1], block[18], block[19]); mode != "" {
+			addMode(mode)
+		}
+	}
+
+	// Established timings
+	for i, modes := range establishedTimings {
+		for bit, mode := range modes {
+			if block[35+i]&(0x80>>bit) != 0 {
+				addMode(mode)
+			}
+		}
+	}
+
+	return info, nil
+}
+
+// decodeDetailedTiming converts an 18-byte detailed timing descriptor to a mode string
+func decodeDetailedTiming(d []byte) string {
+	pixelClock := (int(d[0]) | int(d[1])<<8) * 10000
+	hActive := int(d[2]) | int(d[4]&0xF0)<<4
+	hBlank := int(d[3]) | int(d[4]&0x0F)<<8
+	vActive := int(d[5]) | int(d[7]&0xF0)<<4
+	vBlank := int(d[6]) | int(d[7]&0x0F)<<8
+
+	total := (hActive + hBlank) * (vActive + vBlank)
+	if hActive == 0 || vActive == 0 || total == 0 {
+		return ""
+	}
+
+	scan := "p"
+	refresh := (pixelClock + total/2) / total
+	if d[17]&0x80 != 0 {
+		// Interlaced timings describe a single field; the mode is named by field rate
+		scan = "i"
+		vActive *= 2
+	}
+
+	return fmt.Sprintf("%dx%dx%d%s", hActive, vActive, refresh, scan)
+}
+
+// decodeStandardTiming converts a 2-byte standard timing entry to a mode string
+func decodeStandardTiming(b1, b2, version, revision byte) string {
+	if (b1 == 0x01 && b2 == 0x01) || b1 == 0x00 {
+		return ""
+	}
+
+	width := (int(b1) + 31) * 8
+	var height int
+	switch b2 >> 6 {
+	case 0:
+		// 16:10 from EDID 1.3 onwards, 1:1 before
+		if version > 1 || revision >= 3 {
+			height = width * 10 / 16
+		} else {
+			height = width
+		}
+	case 1:
+		height = width * 3 / 4
+	case 2:
+		height = width * 4 / 5
+	case 3:
+		height = width * 9 / 16
+	}
+
+	return fmt.Sprintf("%dx%dx%dp", width, height, int(b2&0x3F)+60)
+}
+
+// descriptorText returns the text stored in a display descriptor
+func descriptorText(d []byte) string {
+	text := string(d[5:18])
+	if idx := strings.IndexByte(text, '\n'); idx != -1 {
+		text = text[:idx]
+	}
+	return strings.TrimSpace(text)
+}
@@ -0,0 +1,157 @@
+package brightsign
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+// EDID samples: a digital LCD with a preferred detailed timing, an analog CRT
+// without detailed timings, and a TV with an interlaced timing and a CEA extension block
+const (
+	edidDigital = `00ffffffffffff0010ac7ba0414e4c300c1a010380351e78eaee95a3544c99260f50542108008180d1c0010101010101010101010101023a801871382d40582c4500132b2100001e000000ff00434656394e32414c304b554c0a000000fc0044454c4c2055323431324d0a20000000fd00384c1e5311000a20202020202000ad`
+
+	edidAnalog = `00ffffffffffff004c2d900178563412280e01030e201878eaee95a3544c99260f505421080061590101010101010101010101010101000000ff000a202020202020202020202020000000fc0053796e634d61737465720a2020000000fd00384c1e5311000a2020202020200000001000000000000000000000000000000028`
+
+	edidInterlaced = `00ffffffffffff004dd9010c000000000116010380000078eaee95a3544c99260f505420000001010101010101010101010101010101011d007251d01e20582c4500132b2100001e011d8018711c1620582c4500132b2100009e000000fc00534f4e592054560a2020202020000000fd00384c1e5311000a202020202020007402030000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000fb`
+)
+
+func TestDecodeEDID(t *testing.T) {
+	tests := []struct {
+		name     string
+		hex      string
+		expected EDIDInfo
+	}{
+		{
+			name: "digital with detailed timing",
+			hex:  edidDigital,
+			expected: EDIDInfo{
+				Manufacturer:      "DEL",
+				Product:           "DELL U2412M",
+				SerialNumber:      "CFV9N2AL0KUL",
+				WeekOfManufacture: 12,
+				YearOfManufacture: 2016,
+				Version:           "1.3",
+				Digital:           true,
+				Width:             53,
+				Height:            30,
+				SupportedModes:    []string{"1920x1080x60p", "1280x1024x60p", "640x480x60p", "800x600x60p", "1024x768x60p"},
+			},
+		},
+		{
+			name: "analog without detailed timing",
+			hex:  edidAnalog,
+			expected: EDIDInfo{
+				Manufacturer:      "SAM",
+				Product:           "SyncMaster",
+				SerialNumber:      "305419896",
+				WeekOfManufacture: 40,
+				YearOfManufacture: 2004,
+				Version:           "1.3",
+				Digital:           false,
+				Width:             32,
+				Height:            24,
+				SupportedModes:    []string{"1024x768x85p", "640x480x60p", "800x600x60p", "1024x768x60p"},
+			},
+		},
+		{
+			name: "interlaced with extension block",
+			hex:  edidInterlaced,
+			expected: EDIDInfo{
+				Manufacturer:      "SNY",
+				Product:           "SONY TV",
+				WeekOfManufacture: 1,
+				YearOfManufacture: 2012,
+				Version:           "1.3",
+				Digital:           true,
+				SupportedModes:    []string{"1280x720x60p", "1920x1080x60i", "640x480x60p"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw, err := ParseEDIDHex(tt.hex)
+			if err != nil {
+				t.Fatalf("ParseEDIDHex failed: %v", err)
+			}
+
+			edid, err := DecodeEDID(raw)
+			if err != nil {
+				t.Fatalf("DecodeEDID failed: %v", err)
+			}
+
+			if !reflect.DeepEqual(*edid, tt.expected) {
+				t.Errorf("Expected %+v, got %+v", tt.expected, *edid)
+			}
+		})
+	}
+}
+
+func TestDecodeEDIDErrors(t *testing.T) {
+	valid, _ := ParseEDIDHex(edidDigital)
+
+	badHeader := append([]byte(nil), valid...)
+	badHeader[0] = 0x01
+
+	badChecksum := append([]byte(nil), valid...)
+	badChecksum[127]++
+
+	tests := []struct {
+		name string
+		raw  []byte
+	}{
+		{"too short", valid[:64]},
+		{"bad header", badHeader},
+		{"bad checksum", badChecksum},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := DecodeEDID(tt.raw); err == nil {
+				t.Error("Expected an error, got nil")
+			}
+		})
+	}
+}
+
+func TestParseEDIDHexFormats(t *testing.T) {
+	raw, err := ParseEDIDHex("0x00 0xFF\nff:ff\tFF")
+	if err != nil {
+		t.Fatalf("ParseEDIDHex failed: %v", err)
+	}
+
+	expected := []byte{0x00, 0xFF, 0xFF, 0xFF, 0xFF}
+	if !reflect.DeepEqual(raw, expected) {
+		t.Errorf("Expected %x, got %x", expected, raw)
+	}
+
+	if _, err := ParseEDIDHex("zz"); err == nil {
+		t.Error("Expected an error for invalid hex")
+	}
+}
+
+func TestVideoService_GetEDIDRawHex(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/video/hdmi/output/0/edid/" {
+			t.Errorf("Expected path /api/v1/video/hdmi/output/0/edid/, got %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":"` + edidDigital + `"}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	edid, err := client.Video.GetEDID("hdmi", "0")
+	if err != nil {
+		t.Fatalf("GetEDID failed: %v", err)
+	}
+
+	if edid.Product != "DELL U2412M" {
+		t.Errorf("Expected product DELL U2412M, got %s", edid.Product)
+	}
+}
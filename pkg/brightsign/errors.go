@@ -0,0 +1,159 @@
+package brightsign
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Sentinel errors for common failure modes. An *APIError matches the sentinel
+// for its status code, so callers can use errors.Is(err, ErrNotFound).
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrForbidden    = errors.New("forbidden")
+	ErrNotFound     = errors.New("not found")
+	ErrServer       = errors.New("server error")
+
+	// ErrBasicAuthDisabled is returned, along with ErrUnauthorized, when the
+	// player asks for Basic authentication and Config.AllowBasic is not set
+	ErrBasicAuthDisabled = errors.New("player requested basic authentication, which is disabled")
+
+	// ErrPlayerBusy matches a 503 or 409 response whose message says the
+	// player is busy with another operation, once retries are exhausted
+	ErrPlayerBusy = errors.New("player busy")
+
+	// ErrInvalidResponse is returned when a successful response does not
+	// carry JSON, e.g. an HTML page from a proxy or an empty body
+	ErrInvalidResponse = errors.New("invalid response")
+)
+
+// maxSnippetLength limits how much of an unexpected body is quoted in errors
+const maxSnippetLength = 200
+
+// APIError is returned when the player responds with a non-2xx status
+type APIError struct {
+	StatusCode int
+	Body       string
+	Action     string // What was being attempted, e.g. "failed to set brightness"
+
+	// Method and Path identify the request, when known
+	Method string
+	Path   string
+}
+
+// Error implements the error interface
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("request failed with status %d", e.StatusCode)
+	if e.Action != "" {
+		msg = fmt.Sprintf("%s: status %d", e.Action, e.StatusCode)
+	}
+	if e.Method != "" {
+		msg = fmt.Sprintf("%s %s: status %d", e.Method, e.Path, e.StatusCode)
+		if e.Action != "" {
+			msg = e.Action + ": " + msg
+		}
+	}
+	if e.Body != "" {
+		msg += ": " + e.Body
+	}
+	return msg
+}
+
+// Is reports whether the error matches one of the sentinel errors
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrServer:
+		return e.StatusCode >= 500
+	case ErrPlayerBusy:
+		return isBusyResponse(e.StatusCode, e.Body)
+	}
+	return false
+}
+
+// CheckResponse returns an *APIError describing resp if its status is not
+// 2xx, with credentials in the body redacted. It is meant for responses from
+// DoRaw. The body is read for the error message but not closed.
+func CheckResponse(resp *http.Response, action string) error {
+	return checkResponse(resp, action)
+}
+
+// checkResponse returns an *APIError describing resp if its status is not 2xx.
+// The body is read for the error message but not closed.
+func checkResponse(resp *http.Response, action string) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	body, _ := io.ReadAll(resp.Body)
+	return &APIError{
+		StatusCode: resp.StatusCode,
+		Body:       redact(strings.TrimSpace(string(body))),
+		Action:     action,
+	}
+}
+
+// httpError is checkResponse for requests whose errors should also name the
+// method and path, with the player's message collapsed to a snippet. Storage
+// operations use it since firmware differs most in how they fail.
+func httpError(resp *http.Response, action string) error {
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxSnippetLength))
+	apiErr := &APIError{
+		StatusCode: resp.StatusCode,
+		Body:       redact(snippet(bytes.TrimSpace(body))),
+		Action:     action,
+	}
+	if resp.Request != nil {
+		apiErr.Method = resp.Request.Method
+		apiErr.Path = redact(resp.Request.URL.Path)
+	}
+	return apiErr
+}
+
+// jsonBody returns a reader for resp's body after checking that it looks like
+// JSON. HTML pages and empty bodies produce an ErrInvalidResponse error with
+// the status and a snippet of the body instead of a cryptic decode error.
+func jsonBody(resp *http.Response) (io.Reader, error) {
+	reader := bufio.NewReader(resp.Body)
+	peek, _ := reader.Peek(maxSnippetLength)
+
+	trimmed := bytes.TrimSpace(peek)
+	contentType := resp.Header.Get("Content-Type")
+
+	switch {
+	case len(trimmed) == 0:
+		return nil, fmt.Errorf("%w: empty body (status %d); check the host, port and endpoint", ErrInvalidResponse, resp.StatusCode)
+	case strings.Contains(contentType, "html") || !looksLikeJSON(trimmed[0]):
+		return nil, fmt.Errorf("%w: expected JSON but got %q (status %d): %s; the address may point at a proxy, login page or another web server rather than the player DWS",
+			ErrInvalidResponse, contentType, resp.StatusCode, redact(snippet(trimmed)))
+	}
+
+	return reader, nil
+}
+
+// looksLikeJSON reports whether b can start a JSON value
+func looksLikeJSON(b byte) bool {
+	return b == '{' || b == '[' || b == '"' || b == '-' || (b >= '0' && b <= '9') || b == 't' || b == 'f' || b == 'n'
+}
+
+// snippet collapses whitespace in a body prefix for use in an error message
+func snippet(body []byte) string {
+	text := strings.Join(strings.Fields(string(body)), " ")
+	if len(body) >= maxSnippetLength {
+		text += "..."
+	}
+	return text
+}
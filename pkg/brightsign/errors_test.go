@@ -0,0 +1,228 @@
+package brightsign
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAPIErrorStatusMapping(t *testing.T) {
+	tests := []struct {
+		status   int
+		sentinel error
+	}{
+		{http.StatusUnauthorized, ErrUnauthorized},
+		{http.StatusNotFound, ErrNotFound},
+		{http.StatusInternalServerError, ErrServer},
+	}
+
+	for _, test := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if test.status == http.StatusUnauthorized {
+				w.Header().Set("WWW-Authenticate", `Digest realm="BrightSign", nonce="abc123", qop="auth"`)
+			}
+			w.WriteHeader(test.status)
+			w.Write([]byte("player message"))
+		}))
+
+		client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+		client.baseURL = server.URL + "/api/v1"
+
+		_, err := client.Info.GetHealth()
+		server.Close()
+
+		if err == nil {
+			t.Errorf("Status %d: expected error, got nil", test.status)
+			continue
+		}
+
+		if !errors.Is(err, test.sentinel) {
+			t.Errorf("Status %d: expected errors.Is(err, %v) to be true, got %v", test.status, test.sentinel, err)
+		}
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			t.Errorf("Status %d: expected errors.As to find *APIError in %v", test.status, err)
+			continue
+		}
+
+		if apiErr.StatusCode != test.status {
+			t.Errorf("Expected status code %d, got %d", test.status, apiErr.StatusCode)
+		}
+
+		if apiErr.Body != "player message" {
+			t.Errorf("Expected body 'player message', got %q", apiErr.Body)
+		}
+	}
+}
+
+func TestAPIErrorFromSetter(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	err := client.Display.SetBrightness(50)
+	if !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Expected ErrNotFound, got %v", err)
+	}
+
+	if errors.Is(err, ErrUnauthorized) {
+		t.Error("404 error should not match ErrUnauthorized")
+	}
+
+	expected := "failed to set brightness: status 404"
+	if err.Error() != expected {
+		t.Errorf("Expected error %q, got %q", expected, err.Error())
+	}
+}
+
+func TestAPIErrorMessage(t *testing.T) {
+	tests := []struct {
+		err      APIError
+		expected string
+	}{
+		{APIError{StatusCode: 500}, "request failed with status 500"},
+		{APIError{StatusCode: 404, Action: "failed to get file"}, "failed to get file: status 404"},
+		{APIError{StatusCode: 404, Method: "GET", Path: "/files/sd/a.txt"}, "GET /files/sd/a.txt: status 404"},
+		{APIError{StatusCode: 404, Action: "failed to get file", Method: "GET", Path: "/files/sd/a.txt", Body: "missing"},
+			"failed to get file: GET /files/sd/a.txt: status 404: missing"},
+	}
+
+	for _, test := range tests {
+		if got := test.err.Error(); got != test.expected {
+			t.Errorf("Expected %q, got %q", test.expected, got)
+		}
+	}
+}
+
+func TestParseJSONRejectsNonJSONBodies(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		contains    []string
+	}{
+		{
+			name:        "HTML",
+			contentType: "text/html; charset=utf-8",
+			body:        "<!DOCTYPE html>\n<html><head><title>Sign in</title></head><body>Proxy login</body></html>",
+			contains:    []string{"expected JSON", "text/html", "status 200", "<html><head><title>Sign in</title>", "proxy"},
+		},
+		{
+			name:        "HTMLWithoutContentType",
+			contentType: "",
+			body:        "  <html>Gateway</html>",
+			contains:    []string{"expected JSON", "<html>Gateway</html>"},
+		},
+		{
+			name:        "Empty",
+			contentType: "application/json",
+			body:        "",
+			contains:    []string{"empty body", "status 200"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header()["Content-Type"] = []string{test.contentType}
+				w.Write([]byte(test.body))
+			}))
+			defer server.Close()
+
+			client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+			client.baseURL = server.URL + "/api/v1"
+
+			_, err := client.Info.GetHealth()
+			if !errors.Is(err, ErrInvalidResponse) {
+				t.Fatalf("Expected ErrInvalidResponse, got %v", err)
+			}
+
+			for _, text := range test.contains {
+				if !strings.Contains(err.Error(), text) {
+					t.Errorf("Expected error to contain %q, got %q", text, err.Error())
+				}
+			}
+		})
+	}
+}
+
+func TestParseJSONAcceptsJSONWithWrongContentType(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.Write([]byte(`{"data":{"result":{"status":"active"}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	health, err := client.Info.GetHealth()
+	if err != nil {
+		t.Fatalf("GetHealth failed: %v", err)
+	}
+	if health.Status != "active" {
+		t.Errorf("Expected status active, got %s", health.Status)
+	}
+}
+
+func TestStorageErrorsIncludeRequest(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		call     func(client *Client) error
+		expected string
+	}{
+		{
+			name:   "delete forbidden",
+			status: http.StatusForbidden,
+			body:   "{\n  \"error\": \"file is in use\"\n}",
+			call: func(client *Client) error {
+				return client.Storage.DeleteFile("/storage/sd/autorun.brs")
+			},
+			expected: `delete failed: DELETE /api/v1/files/sd/autorun.brs: status 403: { "error": "file is in use" }`,
+		},
+		{
+			name:   "upload insufficient storage",
+			status: http.StatusInsufficientStorage,
+			body:   "No space left on device",
+			call: func(client *Client) error {
+				return client.Storage.UploadReader(strings.NewReader("data"), 4, "/storage/sd/video.mp4")
+			},
+			expected: "upload failed: PUT /api/v1/files/sd/: status 507: No space left on device",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.status)
+				w.Write([]byte(test.body))
+			}))
+			defer server.Close()
+
+			client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+			client.baseURL = server.URL + "/api/v1"
+
+			err := test.call(client)
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+			if err.Error() != test.expected {
+				t.Errorf("Expected error %q, got %q", test.expected, err.Error())
+			}
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != test.status {
+				t.Errorf("Expected *APIError with status %d, got %#v", test.status, err)
+			}
+		})
+	}
+}
@@ -1,9 +1,14 @@
 package brightsign
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
-	"os"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
 // InfoService handles player information endpoints
@@ -43,6 +48,14 @@ type NetworkInterface struct {
 	Metric    int    `json:"metric"`
 }
 
+// APIEndpoint describes an endpoint listed by the player. Method is empty
+// when the firmware lists paths only.
+type APIEndpoint struct {
+	Method      string `json:"method,omitempty"`
+	Path        string `json:"path"`
+	Description string `json:"description,omitempty"`
+}
+
 // HealthInfo represents player health status
 type HealthInfo struct {
 	Status     string `json:"status"`
@@ -56,6 +69,63 @@ type TimeInfo struct {
 	Timezone string      `json:"timezone,omitempty"`
 }
 
+// maxEpochSeconds separates epoch seconds from milliseconds; 1e11 seconds is
+// thousands of years away
+const maxEpochSeconds = 1e11
+
+// Epoch returns Date as a time when the player reported it as a Unix
+// timestamp, either a number or a string of digits, in seconds or
+// milliseconds. It reports false for dates like "2025-06-01".
+func (t TimeInfo) Epoch() (time.Time, bool) {
+	var value float64
+	switch date := t.Date.(type) {
+	case float64:
+		value = date
+	case int:
+		value = float64(date)
+	case int64:
+		value = float64(date)
+	case string:
+		// Short digit strings are more likely dates like "20250601"
+		digits := strings.TrimSpace(date)
+		n, err := strconv.ParseInt(digits, 10, 64)
+		if err != nil || len(digits) < 9 {
+			return time.Time{}, false
+		}
+		value = float64(n)
+	default:
+		return time.Time{}, false
+	}
+
+	if value > maxEpochSeconds {
+		return time.UnixMilli(int64(value)).UTC(), true
+	}
+	return time.Unix(int64(value), 0).UTC(), true
+}
+
+// TimeInfoFromTime formats t for SetTime, using the IANA name of t's
+// location, such as "Europe/Berlin", for the timezone. Abbreviations like
+// "PDT" are ambiguous, so a location without an IANA name, such as a fixed
+// offset or time.Local, is sent as the same instant in UTC.
+func TimeInfoFromTime(t time.Time) TimeInfo {
+	zone := t.Location().String()
+	if _, err := time.LoadLocation(zone); err != nil || zone == "Local" {
+		t = t.UTC()
+		zone = "UTC"
+	}
+	return TimeInfo{
+		Date:     t.Format("2006-01-02"),
+		Time:     t.Format("15:04:05"),
+		Timezone: zone,
+	}
+}
+
+// NTPConfig represents network time synchronization settings
+type NTPConfig struct {
+	Server  string `json:"server"`
+	Enabled bool   `json:"enabled"`
+}
+
 // VideoMode represents video output mode
 type VideoMode struct {
 	Resolution       string `json:"resolution"`
@@ -86,7 +156,7 @@ func (s *InfoService) GetInfo() (*DeviceInfo, error) {
 			resp2, _ := s.client.doRequest("GET", "/info/", nil)
 			if resp2 != nil {
 				body, _ := io.ReadAll(resp2.Body)
-				fmt.Fprintf(os.Stderr, "DEBUG: Failed to parse GetInfo response: %s\n", string(body))
+				s.client.debugf("Failed to parse GetInfo response: %s", string(body))
 				resp2.Body.Close()
 			}
 		}
@@ -142,15 +212,81 @@ func (s *InfoService) SetTime(info TimeInfo) error {
 	if err != nil {
 		return err
 	}
-	resp.Body.Close()
+	defer resp.Body.Close()
+
+	return checkResponse(resp, "failed to set time")
+}
+
+// GetNTP returns the player's NTP settings
+func (s *InfoService) GetNTP() (*NTPConfig, error) {
+	resp, err := s.client.doRequest("GET", "/time/ntp/", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data struct {
+			Result NTPConfig `json:"result"`
+		} `json:"data"`
+	}
+
+	if err := parseJSON(resp, &result); err != nil {
+		return nil, err
+	}
+
+	return &result.Data.Result, nil
+}
+
+// SetNTP points the player at an NTP server, or disables NTP. The server may
+// be empty when disabling.
+func (s *InfoService) SetNTP(server string, enabled bool) error {
+	if enabled || server != "" {
+		if err := ValidateNTPServer(server); err != nil {
+			return err
+		}
+	}
+
+	resp, err := s.client.doRequest("PUT", "/time/ntp/", NTPConfig{Server: server, Enabled: enabled})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return checkResponse(resp, "failed to set NTP configuration")
+}
+
+// ValidateNTPServer checks that server is an IP address or a valid hostname
+func ValidateNTPServer(server string) error {
+	if net.ParseIP(server) != nil {
+		return nil
+	}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("failed to set time: status %d", resp.StatusCode)
+	name := strings.TrimSuffix(server, ".")
+	if name == "" || len(name) > 253 {
+		return fmt.Errorf("invalid NTP server %q: must be a hostname or IP address", server)
 	}
 
+	for _, label := range strings.Split(name, ".") {
+		if !validHostLabel(label) {
+			return fmt.Errorf("invalid NTP server %q: must be a hostname or IP address", server)
+		}
+	}
 	return nil
 }
 
+// validHostLabel reports whether label is a valid DNS label (RFC 1123)
+func validHostLabel(label string) bool {
+	if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+		return false
+	}
+	for _, r := range label {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
+
 // GetVideoMode retrieves current video mode
 func (s *InfoService) GetVideoMode() (*VideoMode, error) {
 	resp, err := s.client.doRequest("GET", "/video-mode/", nil)
@@ -171,8 +307,54 @@ func (s *InfoService) GetVideoMode() (*VideoMode, error) {
 	return &result.Data.Result, nil
 }
 
-// ListAPIs returns list of all available APIs
-func (s *InfoService) ListAPIs() (interface{}, error) {
+// FullStatus combines device info, health, time and video mode. Sections that
+// could not be read are nil and their error is recorded in Errors, keyed by
+// the section's JSON name.
+type FullStatus struct {
+	Device    *DeviceInfo       `json:"device,omitempty"`
+	Health    *HealthInfo       `json:"health,omitempty"`
+	Time      *TimeInfo         `json:"time,omitempty"`
+	VideoMode *VideoMode        `json:"videoMode,omitempty"`
+	Errors    map[string]string `json:"errors,omitempty"`
+}
+
+// GetFullStatus reads every section of FullStatus. A failing section does not
+// stop the others; an error is returned only if all of them failed.
+func (s *InfoService) GetFullStatus() (*FullStatus, error) {
+	status := &FullStatus{}
+	var firstErr error
+	record := func(section string, err error) {
+		if err == nil {
+			return
+		}
+		if status.Errors == nil {
+			status.Errors = make(map[string]string)
+		}
+		status.Errors[section] = err.Error()
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+
+	var err error
+	status.Device, err = s.GetInfo()
+	record("device", err)
+	status.Health, err = s.GetHealth()
+	record("health", err)
+	status.Time, err = s.GetTime()
+	record("time", err)
+	status.VideoMode, err = s.GetVideoMode()
+	record("videoMode", err)
+
+	if len(status.Errors) == 4 {
+		return nil, firstErr
+	}
+	return status, nil
+}
+
+// ListAPIs returns the endpoints the player exposes, sorted by path. A null
+// result is returned as an empty list.
+func (s *InfoService) ListAPIs() ([]APIEndpoint, error) {
 	resp, err := s.client.doRequest("GET", "/", nil)
 	if err != nil {
 		return nil, err
@@ -180,7 +362,7 @@ func (s *InfoService) ListAPIs() (interface{}, error) {
 
 	var result struct {
 		Data struct {
-			Result interface{} `json:"result"`
+			Result json.RawMessage `json:"result"`
 		} `json:"data"`
 	}
 
@@ -188,5 +370,99 @@ func (s *InfoService) ListAPIs() (interface{}, error) {
 		return nil, err
 	}
 
-	return result.Data.Result, nil
-}
\ No newline at end of file
+	return parseAPIList(result.Data.Result)
+}
+
+// parseAPIList accepts the shapes firmware versions use for the API list: an
+// array of paths ("/info/" or "GET /info/"), an array of endpoint objects, or
+// an object keyed by path whose values are a description, a list of methods
+// or an endpoint object
+func parseAPIList(raw json.RawMessage) ([]APIEndpoint, error) {
+	var endpoints []APIEndpoint
+
+	var items []json.RawMessage
+	var byPath map[string]json.RawMessage
+
+	switch {
+	case len(raw) == 0 || string(raw) == "null":
+		return []APIEndpoint{}, nil
+	case json.Unmarshal(raw, &items) == nil:
+		for _, item := range items {
+			var path string
+			if json.Unmarshal(item, &path) == nil {
+				endpoints = append(endpoints, parseAPIString(path))
+				continue
+			}
+			endpoint, err := parseAPIObject("", item)
+			if err != nil {
+				return nil, err
+			}
+			endpoints = append(endpoints, endpoint)
+		}
+	case json.Unmarshal(raw, &byPath) == nil:
+		for path, value := range byPath {
+			var description string
+			var methods []string
+			switch {
+			case json.Unmarshal(value, &description) == nil:
+				endpoints = append(endpoints, APIEndpoint{Path: path, Description: description})
+			case json.Unmarshal(value, &methods) == nil:
+				for _, method := range methods {
+					endpoints = append(endpoints, APIEndpoint{Method: strings.ToUpper(method), Path: path})
+				}
+			default:
+				endpoint, err := parseAPIObject(path, value)
+				if err != nil {
+					return nil, err
+				}
+				endpoints = append(endpoints, endpoint)
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unexpected API list format")
+	}
+
+	sort.SliceStable(endpoints, func(i, j int) bool {
+		if endpoints[i].Path != endpoints[j].Path {
+			return endpoints[i].Path < endpoints[j].Path
+		}
+		return endpoints[i].Method < endpoints[j].Method
+	})
+
+	return endpoints, nil
+}
+
+// parseAPIString splits an optional method prefix from a path
+func parseAPIString(s string) APIEndpoint {
+	fields := strings.Fields(s)
+	if len(fields) == 2 {
+		return APIEndpoint{Method: strings.ToUpper(fields[0]), Path: fields[1]}
+	}
+	return APIEndpoint{Path: strings.TrimSpace(s)}
+}
+
+// parseAPIObject decodes an endpoint object, using path when it has none
+func parseAPIObject(path string, raw json.RawMessage) (APIEndpoint, error) {
+	var obj struct {
+		Method      string `json:"method"`
+		Path        string `json:"path"`
+		Route       string `json:"route"`
+		Endpoint    string `json:"endpoint"`
+		Description string `json:"description"`
+	}
+	if err := json.Unmarshal(raw, &obj); err != nil {
+		return APIEndpoint{}, fmt.Errorf("unexpected API entry: %w", err)
+	}
+
+	endpoint := APIEndpoint{
+		Method:      strings.ToUpper(obj.Method),
+		Path:        obj.Path,
+		Description: obj.Description,
+	}
+	for _, candidate := range []string{obj.Route, obj.Endpoint, path} {
+		if endpoint.Path == "" {
+			endpoint.Path = candidate
+		}
+	}
+	return endpoint, nil
+}
@@ -2,9 +2,13 @@ package brightsign
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestInfoService_GetInfo(t *testing.T) {
@@ -198,67 +202,277 @@ func TestInfoService_GetVideoMode(t *testing.T) {
 }
 
 func TestInfoService_ListAPIs(t *testing.T) {
-	expectedAPIs := []string{
-		"/info/",
-		"/health/",
-		"/time/",
-		"/control/reboot/",
-		"/files/sd/",
+	tests := []struct {
+		name     string
+		result   string
+		expected []APIEndpoint
+	}{
+		{
+			name:   "PathArray",
+			result: `["/info/","/health/","/control/reboot/"]`,
+			expected: []APIEndpoint{
+				{Path: "/control/reboot/"},
+				{Path: "/health/"},
+				{Path: "/info/"},
+			},
+		},
+		{
+			name:   "MethodPathArray",
+			result: `["GET /info/","put /control/reboot/"]`,
+			expected: []APIEndpoint{
+				{Method: "PUT", Path: "/control/reboot/"},
+				{Method: "GET", Path: "/info/"},
+			},
+		},
+		{
+			name:   "ObjectArray",
+			result: `[{"method":"GET","path":"/info/","description":"Device info"},{"method":"PUT","route":"/control/reboot/"}]`,
+			expected: []APIEndpoint{
+				{Method: "PUT", Path: "/control/reboot/"},
+				{Method: "GET", Path: "/info/", Description: "Device info"},
+			},
+		},
+		{
+			name:   "DescriptionMap",
+			result: `{"/info/":"Device info","/health/":"Player health"}`,
+			expected: []APIEndpoint{
+				{Path: "/health/", Description: "Player health"},
+				{Path: "/info/", Description: "Device info"},
+			},
+		},
+		{
+			name:   "MethodsMap",
+			result: `{"/time/":["get","put"]}`,
+			expected: []APIEndpoint{
+				{Method: "GET", Path: "/time/"},
+				{Method: "PUT", Path: "/time/"},
+			},
+		},
+		{
+			name:   "ObjectMap",
+			result: `{"/info/":{"method":"GET","description":"Device info"}}`,
+			expected: []APIEndpoint{
+				{Method: "GET", Path: "/info/", Description: "Device info"},
+			},
+		},
 	}
 
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/v1/" {
+					t.Errorf("Expected path /api/v1/, got %s", r.URL.Path)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"data":{"result":` + test.result + `}}`))
+			}))
+			defer server.Close()
+
+			client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+			client.baseURL = server.URL + "/api/v1"
+
+			apis, err := client.Info.ListAPIs()
+			if err != nil {
+				t.Fatalf("ListAPIs failed: %v", err)
+			}
+
+			if !reflect.DeepEqual(apis, test.expected) {
+				t.Errorf("Expected %+v, got %+v", test.expected, apis)
+			}
+		})
+	}
+}
+
+func TestParseAPIListRejectsUnknownShape(t *testing.T) {
+	if _, err := parseAPIList([]byte(`"not a list"`)); err == nil {
+		t.Error("Expected an error for a string result")
+	}
+}
+
+func TestInfoService_GetNTP(t *testing.T) {
 	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		if r.URL.Path != "/api/v1/" {
-			t.Errorf("Expected path /api/v1/, got %s", r.URL.Path)
+		if r.Method != "GET" || r.URL.Path != "/api/v1/time/ntp/" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
 		}
-		
-		response := struct {
-			Data struct {
-				Result interface{} `json:"result"`
-			} `json:"data"`
-		}{}
-		response.Data.Result = expectedAPIs
-
 		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(response)
+		w.Write([]byte(`{"data":{"result":{"server":"ntp.example.com","enabled":true}}}`))
 	}))
 	defer server.Close()
 
-	config := Config{
-		Host:     server.URL[7:],
-		Username: "admin",
-		Password: "password",
-	}
-	client := NewClient(config)
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
 	client.baseURL = server.URL + "/api/v1"
 
-	apis, err := client.Info.ListAPIs()
+	config, err := client.Info.GetNTP()
 	if err != nil {
-		t.Fatalf("ListAPIs failed: %v", err)
+		t.Fatalf("GetNTP failed: %v", err)
+	}
+
+	if config.Server != "ntp.example.com" || !config.Enabled {
+		t.Errorf("Unexpected NTP config: %+v", config)
 	}
+}
 
-	// Convert interface{} to []interface{} for testing
-	apiList, ok := apis.([]interface{})
-	if !ok {
-		t.Errorf("Expected APIs to be []interface{}, got %T", apis)
-		return
+func TestInfoService_SetNTP(t *testing.T) {
+	tests := []struct {
+		name     string
+		server   string
+		enabled  bool
+		expected string
+	}{
+		{"Hostname", "ntp.corp.example.com", true, `{"server":"ntp.corp.example.com","enabled":true}`},
+		{"IP", "10.0.0.1", true, `{"server":"10.0.0.1","enabled":true}`},
+		{"Disable", "", false, `{"server":"","enabled":false}`},
 	}
 
-	if len(apiList) != len(expectedAPIs) {
-		t.Errorf("Expected %d APIs, got %d", len(expectedAPIs), len(apiList))
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != "PUT" || r.URL.Path != "/api/v1/time/ntp/" {
+					t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+				}
+				body, _ = io.ReadAll(r.Body)
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer server.Close()
+
+			client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+			client.baseURL = server.URL + "/api/v1"
+
+			if err := client.Info.SetNTP(test.server, test.enabled); err != nil {
+				t.Fatalf("SetNTP failed: %v", err)
+			}
+
+			if string(body) != test.expected {
+				t.Errorf("Expected body %s, got %s", test.expected, body)
+			}
+		})
 	}
+}
 
-	for i, expectedAPI := range expectedAPIs {
-		if i >= len(apiList) {
-			t.Errorf("Expected API %s at index %d, but got fewer APIs", expectedAPI, i)
-			continue
+func TestValidateNTPServer(t *testing.T) {
+	for _, server := range []string{"pool.ntp.org", "time-1.corp", "ntp.example.com.", "192.168.1.1", "fe80::1"} {
+		if err := ValidateNTPServer(server); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", server, err)
 		}
-		apiStr, ok := apiList[i].(string)
-		if !ok {
-			t.Errorf("Expected API at index %d to be string, got %T", i, apiList[i])
-			continue
+	}
+
+	for _, server := range []string{"", "ntp://pool.ntp.org", "-bad.example.com", "bad_host", "a..b", "pool.ntp.org:123"} {
+		if err := ValidateNTPServer(server); err == nil {
+			t.Errorf("Expected %q to be invalid", server)
 		}
-		if apiStr != expectedAPI {
-			t.Errorf("Expected API %s at index %d, got %s", expectedAPI, i, apiStr)
+	}
+}
+
+func TestInfoService_GetFullStatus(t *testing.T) {
+	videoModeFails := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/api/v1/info/":
+			w.Write([]byte(`{"data":{"result":{"model":"XT1144","serial":"ABC123","fwVersion":"9.0.110"}}}`))
+		case "/api/v1/health/":
+			w.Write([]byte(`{"data":{"result":{"status":"running","statusTime":"2024-01-01 12:00:00"}}}`))
+		case "/api/v1/time/":
+			w.Write([]byte(`{"data":{"result":{"date":"2024-01-01","time":"12:00:00","timezone":"UTC"}}}`))
+		case "/api/v1/video-mode/":
+			if videoModeFails {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(`{"data":{"result":{"resolution":"1920x1080","frameRate":60,"scanMethod":"progressive"}}}`))
 		}
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	status, err := client.Info.GetFullStatus()
+	if err != nil {
+		t.Fatalf("GetFullStatus failed: %v", err)
+	}
+
+	if status.Device == nil || status.Device.Serial != "ABC123" {
+		t.Errorf("Expected device info, got %+v", status.Device)
+	}
+	if status.Health == nil || status.Health.Status != "running" {
+		t.Errorf("Expected health, got %+v", status.Health)
+	}
+	if status.Time == nil || status.Time.Timezone != "UTC" {
+		t.Errorf("Expected time, got %+v", status.Time)
+	}
+	if status.VideoMode != nil {
+		t.Errorf("Expected no video mode, got %+v", status.VideoMode)
+	}
+	if len(status.Errors) != 1 || status.Errors["videoMode"] == "" {
+		t.Errorf("Expected only videoMode to fail, got %v", status.Errors)
+	}
+
+	// The failed section is omitted from JSON and reported under errors
+	data, _ := json.Marshal(status)
+	var decoded map[string]interface{}
+	json.Unmarshal(data, &decoded)
+	if _, ok := decoded["videoMode"]; ok {
+		t.Errorf("Expected videoMode to be omitted, got %s", data)
+	}
+	if _, ok := decoded["errors"].(map[string]interface{})["videoMode"]; !ok {
+		t.Errorf("Expected errors.videoMode in %s", data)
+	}
+
+	videoModeFails = false
+	status, err = client.Info.GetFullStatus()
+	if err != nil {
+		t.Fatalf("GetFullStatus failed: %v", err)
+	}
+	if status.VideoMode == nil || status.VideoMode.Resolution != "1920x1080" || status.Errors != nil {
+		t.Errorf("Expected a complete status, got %+v", status)
+	}
+}
+
+func TestInfoService_GetFullStatusAllFailed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	if _, err := client.Info.GetFullStatus(); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected ErrForbidden when every section fails, got %v", err)
+	}
+}
+
+func TestTimeInfoEpoch(t *testing.T) {
+	want := time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		body string
+		ok   bool
+	}{
+		{"seconds", `{"date":1748772000}`, true},
+		{"milliseconds", `{"date":1748772000000}`, true},
+		{"digit string", `{"date":"1748772000"}`, true},
+		{"date string", `{"date":"2025-06-01"}`, false},
+		{"compact date string", `{"date":"20250601"}`, false},
+		{"missing", `{}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var info TimeInfo
+			if err := json.Unmarshal([]byte(tt.body), &info); err != nil {
+				t.Fatalf("Unmarshal failed: %v", err)
+			}
+			got, ok := info.Epoch()
+			if ok != tt.ok {
+				t.Fatalf("Epoch() ok = %v, want %v", ok, tt.ok)
+			}
+			if ok && !got.Equal(want) {
+				t.Errorf("Epoch() = %v, want %v", got, want)
+			}
+		})
 	}
 }
\ No newline at end of file
@@ -1,5 +1,17 @@
 package brightsign
 
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
 // LogsService handles log retrieval
 type LogsService struct {
 	client *Client
@@ -63,7 +75,238 @@ func (s *LogsService) SetSupervisorLoggingLevel(level int) error {
 	if err != nil {
 		return err
 	}
-	resp.Body.Close()
+	defer resp.Body.Close()
+
+	return checkResponse(resp, "failed to set supervisor logging level")
+}
+
+// StreamLogs writes the player serial logs to w as plain text. The log string
+// is unescaped while it is read from the response, so multi-megabyte logs are
+// never held in memory in full.
+func (s *LogsService) StreamLogs(w io.Writer) error {
+	resp, err := s.client.doRequest("GET", "/logs/", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := checkResponse(resp, "failed to get logs"); err != nil {
+		return err
+	}
+	if err := s.client.decompress(resp); err != nil {
+		return err
+	}
+
+	out := bufio.NewWriter(w)
+	if err := streamResult(resp.Body, out); err != nil {
+		return err
+	}
+	return out.Flush()
+}
+
+// streamResult finds data.result in a {"data":{"result":...}} body and
+// writes it to w. A string result is written unquoted; anything else is
+// written as JSON.
+func streamResult(body io.Reader, w *bufio.Writer) error {
+	dec := json.NewDecoder(body)
+
+	for _, key := range []string{"data", "result"} {
+		if err := seekKey(dec, key); err != nil {
+			return err
+		}
+	}
+
+	// The decoder has consumed the key; continue reading raw bytes after it
+	r := bufio.NewReader(io.MultiReader(dec.Buffered(), body))
+
+	c, err := skipSpace(r)
+	if err != nil {
+		return fmt.Errorf("failed to read logs: %w", err)
+	}
+	if c == ':' {
+		if c, err = skipSpace(r); err != nil {
+			return fmt.Errorf("failed to read logs: %w", err)
+		}
+	}
+
+	if c != '"' {
+		// Not a string, so decode the value normally
+		r.UnreadByte()
+		var value interface{}
+		if err := json.NewDecoder(r).Decode(&value); err != nil {
+			return fmt.Errorf("failed to parse logs: %w", err)
+		}
+		return json.NewEncoder(w).Encode(value)
+	}
+
+	return unescapeJSONString(r, w)
+}
+
+// seekKey advances dec into the next object until just past the given key
+func seekKey(dec *json.Decoder, key string) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("failed to parse logs: %w", err)
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return fmt.Errorf("failed to parse logs: expected object containing %q", key)
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("failed to parse logs: %w", err)
+		}
+		if tok == key {
+			return nil
+		}
+
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return fmt.Errorf("failed to parse logs: %w", err)
+		}
+	}
 
+	return fmt.Errorf("failed to parse logs: missing %q", key)
+}
+
+// skipSpace returns the next non-whitespace byte
+func skipSpace(r *bufio.Reader) (byte, error) {
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return 0, err
+		}
+		if c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+			return c, nil
+		}
+	}
+}
+
+// unescapeJSONString copies a JSON string body (after the opening quote) to w,
+// decoding escape sequences, up to the closing quote
+func unescapeJSONString(r *bufio.Reader, w *bufio.Writer) error {
+	for {
+		c, err := r.ReadByte()
+		if err != nil {
+			return fmt.Errorf("failed to read logs: unterminated string: %w", err)
+		}
+
+		switch c {
+		case '"':
+			return nil
+		case '\\':
+			if err := unescapeSequence(r, w); err != nil {
+				return err
+			}
+		default:
+			w.WriteByte(c)
+		}
+	}
+}
+
+// unescapeSequence decodes one escape sequence following a backslash
+func unescapeSequence(r *bufio.Reader, w *bufio.Writer) error {
+	c, err := r.ReadByte()
+	if err != nil {
+		return fmt.Errorf("failed to read logs: %w", err)
+	}
+
+	switch c {
+	case '"', '\\', '/':
+		w.WriteByte(c)
+	case 'b':
+		w.WriteByte('\b')
+	case 'f':
+		w.WriteByte('\f')
+	case 'n':
+		w.WriteByte('\n')
+	case 'r':
+		w.WriteByte('\r')
+	case 't':
+		w.WriteByte('\t')
+	case 'u':
+		r1, err := readHex4(r)
+		if err != nil {
+			return err
+		}
+		if utf16.IsSurrogate(r1) {
+			// Surrogate pairs are written as two consecutive \u escapes
+			if next, _ := r.Peek(2); string(next) == "\\u" {
+				r.Discard(2)
+				r2, err := readHex4(r)
+				if err != nil {
+					return err
+				}
+				r1 = utf16.DecodeRune(r1, r2)
+			} else {
+				r1 = '\uFFFD'
+			}
+		}
+		w.WriteRune(r1)
+	default:
+		return fmt.Errorf("failed to parse logs: invalid escape \\%c", c)
+	}
 	return nil
-}
\ No newline at end of file
+}
+
+// readHex4 reads the four hex digits of a \u escape
+func readHex4(r *bufio.Reader) (rune, error) {
+	var digits [4]byte
+	if _, err := io.ReadFull(r, digits[:]); err != nil {
+		return 0, fmt.Errorf("failed to read logs: %w", err)
+	}
+	n, err := strconv.ParseUint(string(digits[:]), 16, 16)
+	if err != nil {
+		return 0, fmt.Errorf("failed to parse logs: invalid escape \\u%s", digits)
+	}
+	return rune(n), nil
+}
+
+// CrashDumpDir is where the player writes crash dumps
+const CrashDumpDir = "/storage/sd/brightsign-dumps/"
+
+// CrashDump describes a crash dump stored on the player. ID is the file name.
+type CrashDump struct {
+	ID        string `json:"id"`
+	Timestamp string `json:"timestamp,omitempty"`
+	Size      int64  `json:"size"`
+}
+
+// GetCrashDumps lists the crash dumps in CrashDumpDir, newest first. A missing
+// dump directory means the player has not crashed and returns an empty list.
+func (s *LogsService) GetCrashDumps() ([]CrashDump, error) {
+	files, err := s.client.Storage.ListFiles(CrashDumpDir, nil)
+	if errors.Is(err, ErrNotFound) {
+		return []CrashDump{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	dumps := []CrashDump{}
+	for _, file := range files {
+		if file.Name == "" || file.Type == "directory" {
+			continue
+		}
+		dumps = append(dumps, CrashDump{ID: file.Name, Timestamp: file.Modified, Size: file.Size})
+	}
+
+	sort.SliceStable(dumps, func(i, j int) bool {
+		if dumps[i].Timestamp != dumps[j].Timestamp {
+			return dumps[i].Timestamp > dumps[j].Timestamp
+		}
+		return dumps[i].ID < dumps[j].ID
+	})
+
+	return dumps, nil
+}
+
+// DownloadCrashDump saves the crash dump with the given ID to localPath
+func (s *LogsService) DownloadCrashDump(id, localPath string) error {
+	if id == "" || id == "." || id == ".." || strings.ContainsAny(id, `/\`) {
+		return fmt.Errorf("invalid crash dump id %q", id)
+	}
+
+	return s.client.Storage.DownloadFile(CrashDumpDir+id, localPath)
+}
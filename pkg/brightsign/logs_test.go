@@ -0,0 +1,147 @@
+package brightsign
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLogsService_StreamLogsMatchesDecodedContent(t *testing.T) {
+	logText := "boot: \"ok\"\n\tpath C:\\autorun.brs </script> caf\u00e9 \U0001F600 \u0001 end\r\n" +
+		strings.Repeat("line of log output\n", 10000)
+
+	body, err := json.Marshal(map[string]interface{}{
+		"data": map[string]interface{}{"result": logText},
+	})
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/logs/" {
+			t.Errorf("Expected path /api/v1/logs/, got %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	var out bytes.Buffer
+	if err := client.Logs.StreamLogs(&out); err != nil {
+		t.Fatalf("StreamLogs failed: %v", err)
+	}
+
+	if out.String() != logText {
+		t.Errorf("Streamed logs differ from decoded content (got %d bytes, expected %d)", out.Len(), len(logText))
+	}
+}
+
+func TestLogsService_StreamLogsEscapes(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data" : {"other": [1, {"x": "y"}], "result" : "a\/b \u00e9 \ud83d\ude00 \"q\""}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	var out bytes.Buffer
+	if err := client.Logs.StreamLogs(&out); err != nil {
+		t.Fatalf("StreamLogs failed: %v", err)
+	}
+
+	expected := "a/b é 😀 \"q\""
+	if out.String() != expected {
+		t.Errorf("Expected %q, got %q", expected, out.String())
+	}
+}
+
+func TestLogsService_GetCrashDumps(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/files/sd/brightsign-dumps/" {
+			t.Errorf("Expected path /api/v1/files/sd/brightsign-dumps/, got %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":[` +
+			`{"name":"dump-1.tar.gz","type":"file","size":2048,"lastModified":"2025-06-01T10:00:00Z"},` +
+			`{"name":"old","type":"directory"},` +
+			`{"name":"dump-2.tar.gz","type":"file","size":4096,"lastModified":"2025-06-02T08:30:00Z"}]}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	dumps, err := client.Logs.GetCrashDumps()
+	if err != nil {
+		t.Fatalf("GetCrashDumps failed: %v", err)
+	}
+
+	expected := []CrashDump{
+		{ID: "dump-2.tar.gz", Timestamp: "2025-06-02T08:30:00Z", Size: 4096},
+		{ID: "dump-1.tar.gz", Timestamp: "2025-06-01T10:00:00Z", Size: 2048},
+	}
+	if !reflect.DeepEqual(dumps, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, dumps)
+	}
+}
+
+func TestLogsService_GetCrashDumpsNoDirectory(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	dumps, err := client.Logs.GetCrashDumps()
+	if err != nil {
+		t.Fatalf("GetCrashDumps failed: %v", err)
+	}
+	if len(dumps) != 0 {
+		t.Errorf("Expected no crash dumps, got %+v", dumps)
+	}
+}
+
+func TestLogsService_DownloadCrashDump(t *testing.T) {
+	content := []byte("\x1f\x8bcrash dump contents")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/files/sd/brightsign-dumps/dump-1.tar.gz" {
+			t.Errorf("Expected path /api/v1/files/sd/brightsign-dumps/dump-1.tar.gz, got %s", r.URL.Path)
+		}
+		w.Write(content)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	localPath := filepath.Join(t.TempDir(), "dump-1.tar.gz")
+	if err := client.Logs.DownloadCrashDump("dump-1.tar.gz", localPath); err != nil {
+		t.Fatalf("DownloadCrashDump failed: %v", err)
+	}
+
+	data, err := os.ReadFile(localPath)
+	if err != nil {
+		t.Fatalf("Failed to read downloaded dump: %v", err)
+	}
+	if !bytes.Equal(data, content) {
+		t.Errorf("Expected %q, got %q", content, data)
+	}
+
+	for _, id := range []string{"", "..", "../autorun.brs", "dir/dump"} {
+		if err := client.Logs.DownloadCrashDump(id, localPath); err == nil {
+			t.Errorf("Expected an error for id %q", id)
+		}
+	}
+}
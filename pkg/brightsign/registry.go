@@ -1,7 +1,13 @@
 package brightsign
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 // RegistryService handles registry operations
@@ -14,7 +20,8 @@ type RegistryValue struct {
 	Value string `json:"value"`
 }
 
-// GetAll returns entire registry dump (excludes hidden sections)
+// GetAll returns entire registry dump (excludes hidden sections). An empty
+// registry is returned as an empty map, also when the player sends null.
 func (s *RegistryService) GetAll() (interface{}, error) {
 	resp, err := s.client.doRequest("GET", "/registry/", nil)
 	if err != nil {
@@ -31,12 +38,93 @@ func (s *RegistryService) GetAll() (interface{}, error) {
 		return nil, err
 	}
 
+	// Some firmware answers an empty registry with a null result
+	if result.Data.Result == nil {
+		return map[string]interface{}{}, nil
+	}
+
 	return result.Data.Result, nil
 }
 
+// RegistrySnapshot is a registry dump keyed by section, then key
+type RegistrySnapshot map[string]map[string]string
+
+// GetSnapshot returns the whole registry with values as strings
+func (s *RegistryService) GetSnapshot() (RegistrySnapshot, error) {
+	registry, err := s.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	sections, ok := registry.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("unexpected registry format: %T", registry)
+	}
+
+	snapshot := make(RegistrySnapshot)
+	for section, sectionData := range sections {
+		keys, ok := sectionData.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		snapshot[section] = make(map[string]string, len(keys))
+		for key, value := range keys {
+			snapshot[section][key] = fmt.Sprintf("%v", value)
+		}
+	}
+	return snapshot, nil
+}
+
+// ListSections returns the registry section names, sorted
+func (s *RegistryService) ListSections() ([]string, error) {
+	snapshot, err := s.GetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	sections := make([]string, 0, len(snapshot))
+	for section := range snapshot {
+		sections = append(sections, section)
+	}
+	sort.Strings(sections)
+	return sections, nil
+}
+
+// ListKeys returns the keys in a registry section, sorted. A section that
+// does not exist returns an error matching ErrNotFound.
+func (s *RegistryService) ListKeys(section string) ([]string, error) {
+	snapshot, err := s.GetSnapshot()
+	if err != nil {
+		return nil, err
+	}
+
+	values, ok := snapshot[section]
+	if !ok {
+		return nil, fmt.Errorf("registry section %q: %w", section, ErrNotFound)
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// registryPath builds the endpoint path for a section or section key. Each
+// part is escaped as a single path segment, so a "/" in a key is sent as %2F
+// rather than splitting it into two segments.
+func registryPath(parts ...string) string {
+	path := "/registry/"
+	for _, part := range parts {
+		path += url.PathEscape(part) + "/"
+	}
+	return path
+}
+
 // GetValue returns specific registry key value
 func (s *RegistryService) GetValue(section, key string) (string, error) {
-	path := fmt.Sprintf("/registry/%s/%s/", section, key)
+	path := registryPath(section, key)
 
 	resp, err := s.client.doRequest("GET", path, nil)
 	if err != nil {
@@ -56,56 +144,174 @@ func (s *RegistryService) GetValue(section, key string) (string, error) {
 	return result.Data.Result.Value, nil
 }
 
+// Exists reports whether a registry key is set. A 404 from the player means the
+// key is absent; any other failure is returned as an error.
+func (s *RegistryService) Exists(section, key string) (bool, error) {
+	_, err := s.GetValue(section, key)
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
 // SetValue creates or updates registry value
 func (s *RegistryService) SetValue(section, key, value string) error {
-	path := fmt.Sprintf("/registry/%s/%s/", section, key)
+	path := registryPath(section, key)
 
 	payload := RegistryValue{Value: value}
 	resp, err := s.client.doRequest("PUT", path, payload)
 	if err != nil {
 		return err
 	}
-	resp.Body.Close()
+	defer resp.Body.Close()
+
+	return checkResponse(resp, "failed to set registry value")
+}
+
+// SetBatch writes values into section one key at a time, in sorted key
+// order. It stops at the first failure and returns the keys written so far
+// along with the error, so callers can report a partial write.
+func (s *RegistryService) SetBatch(section string, values map[string]string) ([]string, error) {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// In dry-run mode every write is still logged before ErrDryRun is returned
+	written := make([]string, 0, len(keys))
+	dryRun := false
+	for _, key := range keys {
+		err := s.SetValue(section, key, values[key])
+		if errors.Is(err, ErrDryRun) {
+			dryRun = true
+			continue
+		}
+		if err != nil {
+			return written, fmt.Errorf("registry %s/%s: %w", section, key, err)
+		}
+		written = append(written, key)
+	}
+	if dryRun {
+		return written, ErrDryRun
+	}
+	return written, nil
+}
+
+// GetInt returns a registry value parsed as an integer
+func (s *RegistryService) GetInt(section, key string) (int64, error) {
+	value, err := s.GetValue(section, key)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := ParseRegistryInt(value)
+	if err != nil {
+		return 0, fmt.Errorf("registry %s/%s: %w", section, key, err)
+	}
+	return n, nil
+}
+
+// SetInt stores an integer registry value
+func (s *RegistryService) SetInt(section, key string, value int64) error {
+	return s.SetValue(section, key, strconv.FormatInt(value, 10))
+}
+
+// GetBool returns a registry value parsed as a boolean
+func (s *RegistryService) GetBool(section, key string) (bool, error) {
+	value, err := s.GetValue(section, key)
+	if err != nil {
+		return false, err
+	}
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("failed to set registry value: status %d", resp.StatusCode)
+	b, err := ParseRegistryBool(value)
+	if err != nil {
+		return false, fmt.Errorf("registry %s/%s: %w", section, key, err)
 	}
+	return b, nil
+}
 
+// SetBool stores a boolean registry value as "true" or "false"
+func (s *RegistryService) SetBool(section, key string, value bool) error {
+	return s.SetValue(section, key, strconv.FormatBool(value))
+}
+
+// GetJSON unmarshals a registry value holding JSON into out
+func (s *RegistryService) GetJSON(section, key string, out interface{}) error {
+	value, err := s.GetValue(section, key)
+	if err != nil {
+		return err
+	}
+
+	if err := json.Unmarshal([]byte(value), out); err != nil {
+		return fmt.Errorf("registry %s/%s: value is not valid JSON: %w", section, key, err)
+	}
 	return nil
 }
 
+// SetJSON marshals value and stores it as a registry string
+func (s *RegistryService) SetJSON(section, key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal registry value: %w", err)
+	}
+	return s.SetValue(section, key, string(data))
+}
+
+// ParseRegistryInt parses an integer registry value
+func ParseRegistryInt(value string) (int64, error) {
+	n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("value %q is not an integer", value)
+	}
+	return n, nil
+}
+
+// ParseRegistryBool parses a boolean registry value. Besides the forms
+// accepted by strconv.ParseBool, "yes"/"no" and "on"/"off" are recognized
+// since the player firmware uses them for many keys.
+func ParseRegistryBool(value string) (bool, error) {
+	switch strings.ToLower(strings.TrimSpace(value)) {
+	case "yes", "on":
+		return true, nil
+	case "no", "off":
+		return false, nil
+	}
+
+	b, err := strconv.ParseBool(strings.TrimSpace(value))
+	if err != nil {
+		return false, fmt.Errorf("value %q is not a boolean (use true/false, yes/no, on/off or 1/0)", value)
+	}
+	return b, nil
+}
+
 // DeleteValue removes specific registry value
 func (s *RegistryService) DeleteValue(section, key string) error {
-	path := fmt.Sprintf("/registry/%s/%s/", section, key)
+	path := registryPath(section, key)
 
 	resp, err := s.client.doRequest("DELETE", path, nil)
 	if err != nil {
 		return err
 	}
-	resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("failed to delete registry value: status %d", resp.StatusCode)
-	}
+	defer resp.Body.Close()
 
-	return nil
+	return checkResponse(resp, "failed to delete registry value")
 }
 
 // DeleteSection deletes entire registry section
 func (s *RegistryService) DeleteSection(section string) error {
-	path := fmt.Sprintf("/registry/%s/", section)
+	path := registryPath(section)
 
 	resp, err := s.client.doRequest("DELETE", path, nil)
 	if err != nil {
 		return err
 	}
-	resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("failed to delete registry section: status %d", resp.StatusCode)
-	}
+	defer resp.Body.Close()
 
-	return nil
+	return checkResponse(resp, "failed to delete registry section")
 }
 
 // GetRecoveryURL retrieves recovery URL from player registry
@@ -138,13 +344,9 @@ func (s *RegistryService) SetRecoveryURL(url string) error {
 	if err != nil {
 		return err
 	}
-	resp.Body.Close()
+	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("failed to set recovery URL: status %d", resp.StatusCode)
-	}
-
-	return nil
+	return checkResponse(resp, "failed to set recovery URL")
 }
 
 // Flush flushes registry contents to persistent storage (BOS 9.0.107+)
@@ -153,11 +355,7 @@ func (s *RegistryService) Flush() error {
 	if err != nil {
 		return err
 	}
-	resp.Body.Close()
+	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("failed to flush registry: status %d", resp.StatusCode)
-	}
-
-	return nil
+	return checkResponse(resp, "failed to flush registry")
 }
\ No newline at end of file
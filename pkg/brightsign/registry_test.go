@@ -0,0 +1,315 @@
+package brightsign
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// newRegistryServer returns a mock player that stores registry values in memory
+func newRegistryServer(t *testing.T) (*httptest.Server, map[string]string) {
+	values := make(map[string]string)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := strings.TrimPrefix(r.URL.Path, "/api/v1/registry/")
+
+		switch r.Method {
+		case "PUT":
+			var payload RegistryValue
+			if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+				t.Errorf("Failed to decode request body: %v", err)
+			}
+			values[key] = payload.Value
+			w.WriteHeader(http.StatusOK)
+		case "GET":
+			value, ok := values[key]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"data": map[string]interface{}{"result": RegistryValue{Value: value}},
+			})
+		}
+	}))
+
+	return server, values
+}
+
+func TestRegistryService_IntRoundTrip(t *testing.T) {
+	server, values := newRegistryServer(t)
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	if err := client.Registry.SetInt("networking", "port", -8080); err != nil {
+		t.Fatalf("SetInt failed: %v", err)
+	}
+
+	if values["networking/port/"] != "-8080" {
+		t.Errorf("Expected stored string -8080, got %q", values["networking/port/"])
+	}
+
+	n, err := client.Registry.GetInt("networking", "port")
+	if err != nil {
+		t.Fatalf("GetInt failed: %v", err)
+	}
+	if n != -8080 {
+		t.Errorf("Expected -8080, got %d", n)
+	}
+
+	values["networking/bad/"] = "eighty"
+	if _, err := client.Registry.GetInt("networking", "bad"); err == nil || !strings.Contains(err.Error(), "networking/bad") {
+		t.Errorf("Expected a descriptive parse error, got %v", err)
+	}
+}
+
+func TestRegistryService_BoolRoundTrip(t *testing.T) {
+	server, values := newRegistryServer(t)
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	for _, expected := range []bool{true, false} {
+		if err := client.Registry.SetBool("networking", "dhcp", expected); err != nil {
+			t.Fatalf("SetBool failed: %v", err)
+		}
+
+		b, err := client.Registry.GetBool("networking", "dhcp")
+		if err != nil {
+			t.Fatalf("GetBool failed: %v", err)
+		}
+		if b != expected {
+			t.Errorf("Expected %v, got %v", expected, b)
+		}
+	}
+
+	values["networking/dhcp/"] = "yes"
+	if b, err := client.Registry.GetBool("networking", "dhcp"); err != nil || !b {
+		t.Errorf("Expected yes to parse as true, got %v, %v", b, err)
+	}
+
+	values["networking/dhcp/"] = "maybe"
+	if _, err := client.Registry.GetBool("networking", "dhcp"); err == nil {
+		t.Error("Expected a parse error for maybe")
+	}
+}
+
+func TestRegistryService_JSONRoundTrip(t *testing.T) {
+	server, values := newRegistryServer(t)
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	type schedule struct {
+		Start string   `json:"start"`
+		Days  []string `json:"days"`
+	}
+	expected := schedule{Start: "08:00", Days: []string{"mon", "tue"}}
+
+	if err := client.Registry.SetJSON("autorun", "schedule", expected); err != nil {
+		t.Fatalf("SetJSON failed: %v", err)
+	}
+
+	var decoded schedule
+	if err := client.Registry.GetJSON("autorun", "schedule", &decoded); err != nil {
+		t.Fatalf("GetJSON failed: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, expected) {
+		t.Errorf("Expected %+v, got %+v", expected, decoded)
+	}
+
+	values["autorun/schedule/"] = "{not json"
+	if err := client.Registry.GetJSON("autorun", "schedule", &decoded); err == nil {
+		t.Error("Expected a parse error for invalid JSON")
+	}
+}
+
+func TestRegistryService_Exists(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/registry/networking/present/":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"data":{"result":{"value":"1"}}}`))
+		case "/api/v1/registry/networking/absent/":
+			w.WriteHeader(http.StatusNotFound)
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	exists, err := client.Registry.Exists("networking", "present")
+	if err != nil || !exists {
+		t.Errorf("Expected present key to exist, got %v, %v", exists, err)
+	}
+
+	exists, err = client.Registry.Exists("networking", "absent")
+	if err != nil || exists {
+		t.Errorf("Expected absent key to not exist without error, got %v, %v", exists, err)
+	}
+
+	if _, err := client.Registry.Exists("networking", "broken"); !errors.Is(err, ErrServer) {
+		t.Errorf("Expected a server error, got %v", err)
+	}
+}
+
+// newSnapshotServer returns a mock player serving a multi-section registry dump
+func newSnapshotServer(t *testing.T) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/registry/" {
+			t.Errorf("Expected path /api/v1/registry/, got %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{` +
+			`"networking":{"ssh":"22","telnet":"0","dhcp":"yes"},` +
+			`"html":{"enable_web_inspector":1},` +
+			`"brightscript":{}}}}`))
+	}))
+}
+
+func TestRegistryService_GetSnapshot(t *testing.T) {
+	server := newSnapshotServer(t)
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	snapshot, err := client.Registry.GetSnapshot()
+	if err != nil {
+		t.Fatalf("GetSnapshot failed: %v", err)
+	}
+
+	if snapshot["html"]["enable_web_inspector"] != "1" {
+		t.Errorf("Expected numeric value as string \"1\", got %q", snapshot["html"]["enable_web_inspector"])
+	}
+	if _, ok := snapshot["brightscript"]; !ok {
+		t.Error("Expected empty section to be present")
+	}
+}
+
+func TestRegistryService_ListSectionsAndKeys(t *testing.T) {
+	server := newSnapshotServer(t)
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	sections, err := client.Registry.ListSections()
+	if err != nil {
+		t.Fatalf("ListSections failed: %v", err)
+	}
+	if expected := []string{"brightscript", "html", "networking"}; !reflect.DeepEqual(sections, expected) {
+		t.Errorf("Expected sections %v, got %v", expected, sections)
+	}
+
+	keys, err := client.Registry.ListKeys("networking")
+	if err != nil {
+		t.Fatalf("ListKeys failed: %v", err)
+	}
+	if expected := []string{"dhcp", "ssh", "telnet"}; !reflect.DeepEqual(keys, expected) {
+		t.Errorf("Expected keys %v, got %v", expected, keys)
+	}
+
+	keys, err = client.Registry.ListKeys("brightscript")
+	if err != nil || len(keys) != 0 {
+		t.Errorf("Expected no keys for empty section, got %v, %v", keys, err)
+	}
+
+	if _, err := client.Registry.ListKeys("missing"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Expected ErrNotFound for a missing section, got %v", err)
+	}
+}
+
+func TestRegistryService_EscapedPaths(t *testing.T) {
+	var methods, paths []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		paths = append(paths, r.URL.EscapedPath())
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{"value":"x"}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	if _, err := client.Registry.GetValue("my section", "a/b c"); err != nil {
+		t.Fatalf("GetValue failed: %v", err)
+	}
+	if err := client.Registry.SetValue("html", "url?x=1&y=#2", "x"); err != nil {
+		t.Fatalf("SetValue failed: %v", err)
+	}
+	if err := client.Registry.DeleteValue("html", "100%"); err != nil {
+		t.Fatalf("DeleteValue failed: %v", err)
+	}
+	if err := client.Registry.DeleteSection("a/b"); err != nil {
+		t.Fatalf("DeleteSection failed: %v", err)
+	}
+
+	expectedMethods := []string{"GET", "PUT", "DELETE", "DELETE"}
+	expectedPaths := []string{
+		"/api/v1/registry/my%20section/a%2Fb%20c/",
+		"/api/v1/registry/html/url%3Fx=1&y=%232/",
+		"/api/v1/registry/html/100%25/",
+		"/api/v1/registry/a%2Fb/",
+	}
+	if !reflect.DeepEqual(methods, expectedMethods) {
+		t.Errorf("Expected methods %v, got %v", expectedMethods, methods)
+	}
+	if !reflect.DeepEqual(paths, expectedPaths) {
+		t.Errorf("Expected paths %v, got %v", expectedPaths, paths)
+	}
+}
+
+func TestRegistryService_SetBatch(t *testing.T) {
+	server, values := newRegistryServer(t)
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	written, err := client.Registry.SetBatch("networking", map[string]string{"b": "2", "a": "1"})
+	if err != nil {
+		t.Fatalf("SetBatch failed: %v", err)
+	}
+	if !reflect.DeepEqual(written, []string{"a", "b"}) {
+		t.Errorf("Expected keys written in sorted order, got %v", written)
+	}
+	if values["networking/a/"] != "1" || values["networking/b/"] != "2" {
+		t.Errorf("Values not stored: %v", values)
+	}
+}
+
+func TestRegistryService_SetBatchPartialFailure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.Contains(r.URL.Path, "/networking/b") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	written, err := client.Registry.SetBatch("networking", map[string]string{"a": "1", "b": "2", "c": "3"})
+	if !errors.Is(err, ErrForbidden) {
+		t.Fatalf("Expected ErrForbidden, got %v", err)
+	}
+	if !reflect.DeepEqual(written, []string{"a"}) {
+		t.Errorf("Expected only the key before the failure to be reported, got %v", written)
+	}
+}
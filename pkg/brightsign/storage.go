@@ -3,13 +3,16 @@ package brightsign
 import (
 	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
 	"os"
+	"path"
 	"path/filepath"
 	"strings"
+	"sync"
 )
 
 // StorageService handles file and storage operations
@@ -26,6 +29,60 @@ type FileInfo struct {
 	Modified string `json:"lastModified,omitempty"`
 }
 
+// pathKind says what a storage path names, which decides its trailing slash
+type pathKind int
+
+const (
+	pathAny  pathKind = iota // Either; the caller's trailing slash is kept
+	pathFile                 // A file, sent without a trailing slash
+	pathDir                  // A directory, sent with a trailing slash
+)
+
+// storagePathToAPI converts a player path like "/storage/sd/file.txt" to the
+// DWS path "/files/sd/file.txt". The path must name a storage device; a
+// device-only path such as "/storage/sd" addresses the root of that device.
+//
+// Paths are normalized so that equivalent forms produce the same request:
+// repeated slashes and "." segments are removed, directories end with "/"
+// and files do not. The player treats "/files/sd" and "/files/sd/"
+// differently on some firmware, so callers should not have to guess.
+func storagePathToAPI(storagePath string, kind pathKind) (string, error) {
+	rest, ok := strings.CutPrefix(storagePath, "/storage/")
+	if !ok {
+		return "", fmt.Errorf("invalid storage path %q: must start with /storage/{device}/", storagePath)
+	}
+	device, _, _ := strings.Cut(rest, "/")
+	if device == "" {
+		return "", fmt.Errorf("invalid storage path %q: missing storage device", storagePath)
+	}
+
+	cleaned := path.Clean("/" + rest)
+	if !strings.HasPrefix(cleaned+"/", "/"+device+"/") {
+		return "", fmt.Errorf("invalid storage path %q: leaves storage device %s", storagePath, device)
+	}
+
+	if kind == pathDir || (kind == pathAny && strings.HasSuffix(storagePath, "/")) {
+		cleaned += "/"
+	}
+	return "/files" + cleaned, nil
+}
+
+// IsStorageDeviceRoot reports whether path names a whole storage device, in
+// any form such as "/storage/sd", "/storage/sd/" or "/storage/sd/media/..",
+// rather than something on it
+func IsStorageDeviceRoot(path string) bool {
+	apiPath, err := storagePathToAPI(path, pathDir)
+	// "/files/sd/" names a whole device
+	return err == nil && strings.Count(apiPath, "/") == 3
+}
+
+// ValidateStoragePath checks that path has the /storage/{device}/... shape
+// the storage calls accept, so callers can reject it before connecting
+func ValidateStoragePath(path string) error {
+	_, err := storagePathToAPI(path, pathAny)
+	return err
+}
+
 // ListOptions contains options for listing files
 type ListOptions struct {
 	Raw bool // If true, returns raw directory listing
@@ -38,9 +95,12 @@ func (s *StorageService) ListFiles(path string, options *ListOptions) ([]FileInf
 		path = "/" + path
 	}
 
-	// Convert path like "/storage/sd/" to API path "/files/sd/"
-	apiPath := strings.Replace(path, "/storage/", "/files/", 1)
-	
+	// Convert path like "/storage/sd" to API path "/files/sd/"
+	apiPath, err := storagePathToAPI(path, pathDir)
+	if err != nil {
+		return nil, err
+	}
+
 	if options != nil && options.Raw {
 		apiPath += "?raw"
 	}
@@ -51,57 +111,173 @@ func (s *StorageService) ListFiles(path string, options *ListOptions) ([]FileInf
 	}
 	defer resp.Body.Close()
 
+	if err := httpError(resp, "list failed"); err != nil {
+		return nil, err
+	}
+
 	// Read raw response to understand structure
 	bodyBytes, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	if s.client.debug {
-		fmt.Fprintf(os.Stderr, "DEBUG: ListFiles API response: %s\n", string(bodyBytes))
+	s.client.debugf("ListFiles API response: %s", string(bodyBytes))
+
+	// Raw listings carry names only; structured bodies fall through below
+	if options != nil && options.Raw {
+		if files, ok := parseRawListing(bodyBytes, path); ok {
+			return files, nil
+		}
 	}
 
-	// Try to parse as array first (directory listing)
-	var arrayResult struct {
+	var envelope struct {
 		Data struct {
-			Result []FileInfo `json:"result"`
+			Result json.RawMessage `json:"result"`
 		} `json:"data"`
 	}
+	if err := json.Unmarshal(bodyBytes, &envelope); err == nil {
+		result := bytes.TrimSpace(envelope.Data.Result)
+
+		// An empty directory may come back as a null or missing result
+		if len(result) == 0 || string(result) == "null" {
+			return []FileInfo{}, nil
+		}
+
+		// A directory listing is an array, or an object with a files array
+		var files []FileInfo
+		if err := json.Unmarshal(result, &files); err == nil {
+			return nonNilFiles(files), nil
+		}
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(result, &object); err == nil {
+			if raw, ok := object["files"]; ok {
+				if err := json.Unmarshal(raw, &files); err == nil {
+					return nonNilFiles(files), nil
+				}
+			}
+		}
+
+		// Anything else describes a single file
+		var file FileInfo
+		if err := json.Unmarshal(result, &file); err == nil {
+			return []FileInfo{file}, nil
+		}
+	}
 
-	if err := json.Unmarshal(bodyBytes, &arrayResult); err == nil && len(arrayResult.Data.Result) > 0 {
-		return arrayResult.Data.Result, nil
+	// If none of the above worked, return the parsing error
+	return nil, fmt.Errorf("failed to parse response as known format: %s", string(bodyBytes))
+}
+
+// nonNilFiles returns files, or an empty slice if it is nil, so an empty
+// directory is never reported as a missing result
+func nonNilFiles(files []FileInfo) []FileInfo {
+	if files == nil {
+		return []FileInfo{}
 	}
+	return files
+}
 
-	// Try to parse as single object (single file info)
-	var singleResult struct {
-		Data struct {
-			Result FileInfo `json:"result"`
-		} `json:"data"`
+// Walk lists root and every directory below it depth first, calling fn for
+// each entry. Entries are passed with Path set to their full path, e.g.
+// "/storage/sd/media/intro.mp4". Directories are passed before their contents.
+func (s *StorageService) Walk(root string, fn func(FileInfo) error) error {
+	dir := strings.TrimSuffix(root, "/") + "/"
+
+	files, err := s.ListFiles(dir, nil)
+	if err != nil {
+		return err
 	}
 
-	if err := json.Unmarshal(bodyBytes, &singleResult); err == nil {
-		return []FileInfo{singleResult.Data.Result}, nil
+	for _, file := range files {
+		if file.Name == "" {
+			continue
+		}
+		file.Path = dir + file.Name
+		if err := fn(file); err != nil {
+			return err
+		}
+		if file.Type == "directory" {
+			if err := s.Walk(file.Path, fn); err != nil {
+				return err
+			}
+		}
 	}
+	return nil
+}
+
+// parseRawListing parses a raw directory listing, which is either a JSON list
+// of names (possibly under a "files" key) or plain text with one name per line.
+// Names ending in "/" are directories.
+func parseRawListing(body []byte, dir string) ([]FileInfo, bool) {
+	var names []string
 
-	// Try to parse as object with files property
+	var arrayResult struct {
+		Data struct {
+			Result []string `json:"result"`
+		} `json:"data"`
+	}
 	var objectResult struct {
 		Data struct {
 			Result struct {
-				Files []FileInfo `json:"files"`
+				Files []string `json:"files"`
 			} `json:"result"`
 		} `json:"data"`
 	}
+	var textResult struct {
+		Data struct {
+			Result string `json:"result"`
+		} `json:"data"`
+	}
 
-	if err := json.Unmarshal(bodyBytes, &objectResult); err == nil {
-		return objectResult.Data.Result.Files, nil
+	switch {
+	case json.Unmarshal(body, &arrayResult) == nil:
+		names = arrayResult.Data.Result
+	case json.Unmarshal(body, &objectResult) == nil:
+		names = objectResult.Data.Result.Files
+	case json.Unmarshal(body, &textResult) == nil:
+		names = strings.Split(textResult.Data.Result, "\n")
+	case !json.Valid(body):
+		names = strings.Split(string(body), "\n")
+	default:
+		return nil, false
 	}
 
-	// If none of the above worked, return the parsing error
-	return nil, fmt.Errorf("failed to parse response as known format: %s", string(bodyBytes))
+	dir = strings.TrimSuffix(dir, "/") + "/"
+	files := make([]FileInfo, 0, len(names))
+	for _, name := range names {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		fileType := "file"
+		if strings.HasSuffix(name, "/") {
+			fileType = "directory"
+			name = strings.TrimSuffix(name, "/")
+		}
+
+		files = append(files, FileInfo{Name: name, Path: dir + name, Type: fileType})
+	}
+
+	return files, true
+}
+
+// UploadOptions contains options for uploading files
+type UploadOptions struct {
+	// Atomic uploads to a temporary name next to the destination and renames
+	// it once the transfer is complete, so readers on the player never see a
+	// partial file. The temporary file is deleted if either step fails.
+	Atomic bool
 }
 
-// UploadFile uploads a file to the specified path on the player
+// UploadFile uploads a file to the specified path on the player. A path
+// ending in "/" uploads into that directory under the local file name.
 func (s *StorageService) UploadFile(localPath, remotePath string) error {
+	return s.UploadFileWithOptions(localPath, remotePath, nil)
+}
+
+// UploadFileWithOptions is UploadFile with options
+func (s *StorageService) UploadFileWithOptions(localPath, remotePath string, options *UploadOptions) error {
 	// Open the local file
 	file, err := os.Open(localPath)
 	if err != nil {
@@ -115,10 +291,67 @@ func (s *StorageService) UploadFile(localPath, remotePath string) error {
 		return fmt.Errorf("failed to stat file: %w", err)
 	}
 
+	destination := UploadDestination(localPath, remotePath)
+	if options == nil || !options.Atomic {
+		return s.UploadReader(file, fileInfo.Size(), destination)
+	}
+
+	tempPath := atomicTempPath(destination)
+	if err := s.UploadReader(file, fileInfo.Size(), tempPath); err != nil {
+		s.removeTemp(tempPath)
+		return err
+	}
+	if err := s.RenameFile(tempPath, filepath.Base(destination)); err != nil {
+		s.removeTemp(tempPath)
+		return fmt.Errorf("failed to rename %s to %s: %w", tempPath, destination, err)
+	}
+	return nil
+}
+
+// atomicTempPath returns the temporary name an atomic upload to remotePath
+// is written to first, e.g. "/storage/sd/.video.mp4.tmp"
+func atomicTempPath(remotePath string) string {
+	return filepath.Dir(remotePath) + "/." + filepath.Base(remotePath) + ".tmp"
+}
+
+// removeTemp deletes the temporary file of a failed atomic upload. A failed
+// transfer may not have created it, so errors are only logged.
+func (s *StorageService) removeTemp(tempPath string) {
+	if err := s.DeleteFile(tempPath); err != nil {
+		s.client.debugf("failed to remove %s: %v", tempPath, err)
+	}
+}
+
+// UploadDestination returns the remote file path for uploading localPath to
+// remotePath. A remotePath ending in "/" names a directory and the local file
+// name is appended; otherwise remotePath is the remote file name.
+func UploadDestination(localPath, remotePath string) string {
+	if strings.HasSuffix(remotePath, "/") {
+		return remotePath + filepath.Base(localPath)
+	}
+	return remotePath
+}
+
+// UploadReader uploads the contents of r as a file at remotePath. When size
+// is known it is checked against the bytes read; pass -1 when it is unknown
+// (e.g. stdin). The body is buffered so it can be resent after the digest
+// challenge.
+func (s *StorageService) UploadReader(r io.Reader, size int64, remotePath string) error {
+	// Fetch the digest challenge first so the body is not sent twice
+	if s.client.preAuth {
+		if err := s.client.authenticate(); err != nil {
+			return fmt.Errorf("failed to pre-authenticate: %w", err)
+		}
+	}
+
 	// Create multipart form
 	var body bytes.Buffer
 	writer := multipart.NewWriter(&body)
 
+	if strings.HasSuffix(remotePath, "/") {
+		return fmt.Errorf("remote path %s must include a file name", remotePath)
+	}
+
 	// Add file field
 	filename := filepath.Base(remotePath)
 	part, err := writer.CreateFormFile("file", filename)
@@ -127,18 +360,25 @@ func (s *StorageService) UploadFile(localPath, remotePath string) error {
 	}
 
 	// Copy file content
-	if _, err := io.Copy(part, file); err != nil {
+	written, err := io.Copy(part, r)
+	if err != nil {
 		return fmt.Errorf("failed to copy file content: %w", err)
 	}
 
+	if size >= 0 && written != size {
+		return fmt.Errorf("short read: expected %d bytes, got %d", size, written)
+	}
+
 	contentType := writer.FormDataContentType()
 	if err := writer.Close(); err != nil {
 		return fmt.Errorf("failed to close writer: %w", err)
 	}
 
 	// Convert path like "/storage/sd/file.txt" to API path "/files/sd/"
-	dir := filepath.Dir(remotePath)
-	apiPath := strings.Replace(dir, "/storage/", "/files/", 1) + "/"
+	apiPath, err := storagePathToAPI(filepath.Dir(remotePath), pathDir)
+	if err != nil {
+		return err
+	}
 
 	// Make request
 	url := s.client.baseURL + apiPath
@@ -148,14 +388,11 @@ func (s *StorageService) UploadFile(localPath, remotePath string) error {
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("upload failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+	if err := httpError(resp, "upload failed"); err != nil {
+		return err
 	}
 
-	if s.client.debug {
-		fmt.Fprintf(os.Stderr, "DEBUG: Uploaded %s (%d bytes) to %s\n", localPath, fileInfo.Size(), remotePath)
-	}
+	s.client.debugf("Uploaded %d bytes to %s", written, remotePath)
 
 	return nil
 }
@@ -163,7 +400,11 @@ func (s *StorageService) UploadFile(localPath, remotePath string) error {
 // DownloadFile downloads a file from the player to local path
 func (s *StorageService) DownloadFile(remotePath, localPath string) error {
 	// Convert path like "/storage/sd/file.txt" to API path "/files/sd/file.txt?contents&stream"
-	apiPath := strings.Replace(remotePath, "/storage/", "/files/", 1) + "?contents&stream"
+	apiPath, err := storagePathToAPI(remotePath, pathFile)
+	if err != nil {
+		return err
+	}
+	apiPath += "?contents&stream"
 
 	resp, err := s.client.doRequest("GET", apiPath, nil)
 	if err != nil {
@@ -171,9 +412,11 @@ func (s *StorageService) DownloadFile(remotePath, localPath string) error {
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode != http.StatusOK {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("download failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+	if err := httpError(resp, "download failed"); err != nil {
+		return err
+	}
+	if err := s.client.decompress(resp); err != nil {
+		return err
 	}
 
 	// Create local file
@@ -189,8 +432,250 @@ func (s *StorageService) DownloadFile(remotePath, localPath string) error {
 		return fmt.Errorf("failed to write file: %w", err)
 	}
 
-	if s.client.debug {
-		fmt.Fprintf(os.Stderr, "DEBUG: Downloaded %s (%d bytes) to %s\n", remotePath, written, localPath)
+	s.client.debugf("Downloaded %s (%d bytes) to %s", remotePath, written, localPath)
+
+	return nil
+}
+
+// DownloadFileResume continues an interrupted download. If localPath already
+// holds part of the file, only the remaining bytes are requested with a Range
+// header and appended. If the player ignores the range the file is downloaded
+// again from the start. resumed reports whether bytes were appended.
+func (s *StorageService) DownloadFileResume(remotePath, localPath string) (resumed bool, err error) {
+	stat, err := os.Stat(localPath)
+	if err != nil || stat.Size() == 0 {
+		return false, s.DownloadFile(remotePath, localPath)
+	}
+	offset := stat.Size()
+
+	apiPath, err := storagePathToAPI(remotePath, pathFile)
+	if err != nil {
+		return false, err
+	}
+	apiPath += "?contents&stream"
+	header := http.Header{"Range": {fmt.Sprintf("bytes=%d-", offset)}}
+
+	resp, err := s.client.doRequestWithHeaders("GET", s.client.baseURL+apiPath, nil, "", header)
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		// Only trust the range if it starts where the local file ends
+		if !strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-", offset)) {
+			return false, fmt.Errorf("download failed: player returned range %q, expected bytes from %d", resp.Header.Get("Content-Range"), offset)
+		}
+		out, err := os.OpenFile(localPath, os.O_WRONLY|os.O_APPEND, 0)
+		if err != nil {
+			return false, fmt.Errorf("failed to open local file: %w", err)
+		}
+		defer out.Close()
+
+		written, err := io.Copy(out, resp.Body)
+		if err != nil {
+			return false, fmt.Errorf("failed to write file: %w", err)
+		}
+		s.client.debugf("Resumed %s at byte %d (%d bytes) to %s", remotePath, offset, written, localPath)
+		return true, nil
+
+	case http.StatusRequestedRangeNotSatisfiable:
+		// The local file is already complete if it matches the remote size
+		if resp.Header.Get("Content-Range") == fmt.Sprintf("bytes */%d", offset) {
+			return true, nil
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		return false, s.DownloadFile(remotePath, localPath)
+	}
+
+	if err := httpError(resp, "download failed"); err != nil {
+		return false, err
+	}
+	if err := s.client.decompress(resp); err != nil {
+		return false, err
+	}
+
+	// Ranges not supported: the body is the whole file
+	out, err := os.Create(localPath)
+	if err != nil {
+		return false, fmt.Errorf("failed to create local file: %w", err)
+	}
+	defer out.Close()
+
+	written, err := io.Copy(out, resp.Body)
+	if err != nil {
+		return false, fmt.Errorf("failed to write file: %w", err)
+	}
+	s.client.debugf("Player ignored range; downloaded %s (%d bytes) to %s", remotePath, written, localPath)
+	return false, nil
+}
+
+// maxDownloadParts caps the number of concurrent range requests
+const maxDownloadParts = idleConnsPerHost
+
+// errRangeIgnored signals that the player answered a range request with the
+// whole file
+var errRangeIgnored = errors.New("player does not support range requests")
+
+// DownloadFileParallel downloads a file with up to parts concurrent Range
+// requests, each writing its span of the local file. It falls back to a
+// single stream when parts < 2, the size is unknown or the player does not
+// support ranges.
+func (s *StorageService) DownloadFileParallel(remotePath, localPath string, parts int) error {
+	if parts > maxDownloadParts {
+		parts = maxDownloadParts
+	}
+	if parts < 2 {
+		return s.DownloadFile(remotePath, localPath)
+	}
+
+	apiPath, err := storagePathToAPI(remotePath, pathFile)
+	if err != nil {
+		return err
+	}
+	url := s.client.baseURL + apiPath + "?contents&stream"
+
+	resp, err := s.client.doRequestWithHeaders("HEAD", url, nil, "", nil)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode == http.StatusMethodNotAllowed || resp.StatusCode == http.StatusNotImplemented {
+		return s.DownloadFile(remotePath, localPath)
+	}
+	if err := httpError(resp, "download failed"); err != nil {
+		return err
+	}
+
+	// Ranges of a compressed body cannot be decompressed separately
+	if isGzipEncoded(resp) && !s.client.noDecompress {
+		s.client.debugf("%s is sent compressed, downloading in a single stream", remotePath)
+		return s.DownloadFile(remotePath, localPath)
+	}
+
+	size := resp.ContentLength
+	if size <= 0 || resp.Header.Get("Accept-Ranges") != "bytes" {
+		s.client.debugf("Ranges unavailable for %s, downloading in a single stream", remotePath)
+		return s.DownloadFile(remotePath, localPath)
+	}
+	if int64(parts) > size {
+		parts = int(size)
+	}
+
+	out, err := os.Create(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to create local file: %w", err)
+	}
+	defer out.Close()
+
+	if err := out.Truncate(size); err != nil {
+		return fmt.Errorf("failed to allocate local file: %w", err)
+	}
+
+	span := (size + int64(parts) - 1) / int64(parts)
+	errs := make([]error, parts)
+
+	var wg sync.WaitGroup
+	for i := 0; i < parts; i++ {
+		start := int64(i) * span
+		end := start + span - 1
+		if end >= size {
+			end = size - 1
+		}
+
+		wg.Add(1)
+		go func(i int, start, end int64) {
+			defer wg.Done()
+			errs[i] = s.downloadRange(url, out, start, end)
+		}(i, start, end)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if errors.Is(err, errRangeIgnored) {
+			out.Close()
+			s.client.debugf("Player ignored range for %s, downloading in a single stream", remotePath)
+			return s.DownloadFile(remotePath, localPath)
+		}
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+
+	s.client.debugf("Downloaded %s (%d bytes in %d parts) to %s", remotePath, size, parts, localPath)
+	return nil
+}
+
+// downloadRange writes bytes start-end (inclusive) of url to the same offset in out
+func (s *StorageService) downloadRange(url string, out io.WriterAt, start, end int64) error {
+	header := http.Header{"Range": {fmt.Sprintf("bytes=%d-%d", start, end)}}
+
+	resp, err := s.client.doRequestWithHeaders("GET", url, nil, "", header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return errRangeIgnored
+	}
+	if err := httpError(resp, "download failed"); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(resp.Header.Get("Content-Range"), fmt.Sprintf("bytes %d-%d/", start, end)) {
+		return fmt.Errorf("download failed: player returned range %q, expected bytes %d-%d", resp.Header.Get("Content-Range"), start, end)
+	}
+
+	written, err := io.Copy(io.NewOffsetWriter(out, start), resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to write file: %w", err)
+	}
+	if written != end-start+1 {
+		return fmt.Errorf("download failed: got %d bytes for range %d-%d", written, start, end)
+	}
+	return nil
+}
+
+// OpenFile opens a file on the player for reading. The caller must close the
+// returned reader. size is the content length reported by the player, or -1
+// if unknown.
+func (s *StorageService) OpenFile(remotePath string) (io.ReadCloser, int64, error) {
+	apiPath, err := storagePathToAPI(remotePath, pathFile)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	resp, err := s.client.doRequest("GET", apiPath+"?contents&stream", nil)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := httpError(resp, "read failed"); err != nil {
+		resp.Body.Close()
+		return nil, 0, err
+	}
+	if err := s.client.decompress(resp); err != nil {
+		resp.Body.Close()
+		return nil, 0, err
+	}
+
+	return resp.Body, resp.ContentLength, nil
+}
+
+// ReadFile writes the contents of a file on the player to w
+func (s *StorageService) ReadFile(remotePath string, w io.Writer) error {
+	body, _, err := s.OpenFile(remotePath)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	if _, err := io.Copy(w, body); err != nil {
+		return fmt.Errorf("failed to read file: %w", err)
 	}
 
 	return nil
@@ -199,7 +684,10 @@ func (s *StorageService) DownloadFile(remotePath, localPath string) error {
 // DeleteFile deletes a file or directory
 func (s *StorageService) DeleteFile(path string) error {
 	// Convert path like "/storage/sd/file.txt" to API path "/files/sd/file.txt"
-	apiPath := strings.Replace(path, "/storage/", "/files/", 1)
+	apiPath, err := storagePathToAPI(path, pathAny)
+	if err != nil {
+		return err
+	}
 
 	resp, err := s.client.doRequest("DELETE", apiPath, nil)
 	if err != nil {
@@ -207,22 +695,79 @@ func (s *StorageService) DeleteFile(path string) error {
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("delete failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+	if err := httpError(resp, "delete failed"); err != nil {
+		return err
 	}
 
 	return nil
 }
 
+// DeleteRecursive deletes the directory at path together with everything in
+// it, for firmware that refuses to delete a non-empty directory. Contents are
+// deleted depth first before the directory itself. It stops at the first
+// failure and returns how many entries were deleted, counting the directory.
+// Device roots such as "/storage/sd" are refused; use Format to erase a
+// whole device.
+func (s *StorageService) DeleteRecursive(path string) (int, error) {
+	if _, err := storagePathToAPI(path, pathDir); err != nil {
+		return 0, err
+	}
+	if IsStorageDeviceRoot(path) {
+		return 0, fmt.Errorf("refusing to recursively delete storage device root %s", path)
+	}
+
+	var entries []FileInfo
+	if err := s.Walk(path, func(file FileInfo) error {
+		entries = append(entries, file)
+		return nil
+	}); err != nil {
+		return 0, err
+	}
+
+	// Walk yields directories before their contents, so deleting in reverse
+	// empties every directory before it is removed. In dry-run mode every
+	// delete is still logged before ErrDryRun is returned.
+	targets := make([]string, 0, len(entries)+1)
+	for i := len(entries) - 1; i >= 0; i-- {
+		target := entries[i].Path
+		if entries[i].Type == "directory" {
+			target += "/"
+		}
+		targets = append(targets, target)
+	}
+	targets = append(targets, strings.TrimSuffix(path, "/")+"/")
+
+	deleted := 0
+	dryRun := false
+	for _, target := range targets {
+		err := s.DeleteFile(target)
+		if errors.Is(err, ErrDryRun) {
+			dryRun = true
+			continue
+		}
+		if err != nil {
+			return deleted, err
+		}
+		deleted++
+	}
+	if dryRun {
+		return deleted, ErrDryRun
+	}
+	return deleted, nil
+}
+
 // RenameFile renames a file
 func (s *StorageService) RenameFile(oldPath, newName string) error {
-	// Convert path like "/storage/sd/file.txt" to API path "/files/sd/"
-	dir := filepath.Dir(oldPath)
-	apiPath := strings.Replace(dir, "/storage/", "/files/", 1) + "/"
+	// Convert path like "/storage/sd/file.txt" to API path "/files/sd/",
+	// cleaning first so a trailing slash does not change the parent
+	oldPath = path.Clean(oldPath)
+	apiPath, err := storagePathToAPI(path.Dir(oldPath), pathDir)
+	if err != nil {
+		return err
+	}
 
 	payload := map[string]string{
-		"oldName": filepath.Base(oldPath),
+		"oldName": path.Base(oldPath),
 		"newName": newName,
 	}
 
@@ -232,20 +777,23 @@ func (s *StorageService) RenameFile(oldPath, newName string) error {
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("rename failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+	if err := httpError(resp, "rename failed"); err != nil {
+		return err
 	}
 
 	return nil
 }
 
 // CreateDirectory creates a new directory
-func (s *StorageService) CreateDirectory(path string) error {
-	// Convert path like "/storage/sd/newdir" to API path "/files/sd/"
-	dir := filepath.Dir(path)
-	dirName := filepath.Base(path)
-	apiPath := strings.Replace(dir, "/storage/", "/files/", 1) + "/"
+func (s *StorageService) CreateDirectory(dirPath string) error {
+	// Convert path like "/storage/sd/newdir" to API path "/files/sd/",
+	// cleaning first so a trailing slash does not change the parent
+	dirPath = path.Clean(dirPath)
+	apiPath, err := storagePathToAPI(path.Dir(dirPath), pathDir)
+	if err != nil {
+		return err
+	}
+	dirName := path.Base(dirPath)
 
 	// Create form data for directory creation
 	var body bytes.Buffer
@@ -267,17 +815,33 @@ func (s *StorageService) CreateDirectory(path string) error {
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("create directory failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+	if err := httpError(resp, "create directory failed"); err != nil {
+		return err
 	}
 
 	return nil
 }
 
-// FormatStorage formats a storage device
+// KnownStorageDevices lists the storage device names found on BrightSign players
+var KnownStorageDevices = []string{"sd", "sd2", "usb1", "usb2", "usb3", "ssd"}
+
+// IsKnownStorageDevice reports whether device is one of KnownStorageDevices
+func IsKnownStorageDevice(device string) bool {
+	for _, known := range KnownStorageDevices {
+		if device == known {
+			return true
+		}
+	}
+	return false
+}
+
+// FormatStorage formats a storage device. device is a bare device name such
+// as "sd" or "usb1"; names that could address another path are rejected.
 func (s *StorageService) FormatStorage(device string) error {
-	// device should be like "sd", "usb1", etc.
+	if device == "" || strings.ContainsAny(device, "/\\?#") || strings.Contains(device, "..") {
+		return fmt.Errorf("invalid storage device name %q", device)
+	}
+
 	apiPath := fmt.Sprintf("/storage/%s/", device)
 
 	resp, err := s.client.doRequest("DELETE", apiPath, nil)
@@ -286,10 +850,79 @@ func (s *StorageService) FormatStorage(device string) error {
 	}
 	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		bodyBytes, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("format failed with status %d: %s", resp.StatusCode, string(bodyBytes))
+	if err := httpError(resp, "format failed"); err != nil {
+		return err
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// StorageStats represents capacity information for a storage device
+type StorageStats struct {
+	Device         string `json:"device"`
+	FileSystemType string `json:"fileSystemType,omitempty"`
+	TotalBytes     int64  `json:"totalBytes"`
+	UsedBytes      int64  `json:"usedBytes"`
+	FreeBytes      int64  `json:"freeBytes"`
+	ReadOnly       bool   `json:"readOnly"`
+
+	// Estimated is set when the player did not report filesystem stats and
+	// UsedBytes was computed by summing the sizes of the top-level listing.
+	// TotalBytes and FreeBytes are unknown (0) in that case.
+	Estimated bool `json:"estimated"`
+}
+
+// GetStorageInfo returns capacity information for a storage device such as
+// "sd" or "usb1". The stats come from the storageInfo object that the DWS
+// includes in a directory listing of the device root.
+func (s *StorageService) GetStorageInfo(device string) (*StorageStats, error) {
+	device = strings.Trim(strings.TrimPrefix(device, "/storage/"), "/")
+	apiPath, err := storagePathToAPI("/storage/"+device, pathDir)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.client.doRequest("GET", apiPath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data struct {
+			Result struct {
+				Files       []FileInfo `json:"files"`
+				StorageInfo *struct {
+					FileSystemType string `json:"fileSystemType"`
+					Stats          struct {
+						SizeBytes  int64 `json:"sizeBytes"`
+						BytesFree  int64 `json:"bytesFree"`
+						IsReadOnly bool  `json:"isReadOnly"`
+					} `json:"stats"`
+				} `json:"storageInfo"`
+			} `json:"result"`
+		} `json:"data"`
+	}
+
+	if err := parseJSON(resp, &result); err != nil {
+		return nil, err
+	}
+
+	stats := &StorageStats{Device: device}
+
+	if info := result.Data.Result.StorageInfo; info != nil {
+		stats.FileSystemType = info.FileSystemType
+		stats.TotalBytes = info.Stats.SizeBytes
+		stats.FreeBytes = info.Stats.BytesFree
+		stats.UsedBytes = info.Stats.SizeBytes - info.Stats.BytesFree
+		stats.ReadOnly = info.Stats.IsReadOnly
+		return stats, nil
+	}
+
+	// Older firmware omits storageInfo, so fall back to summing the listing
+	stats.Estimated = true
+	for _, file := range result.Data.Result.Files {
+		stats.UsedBytes += file.Size
+	}
+
+	return stats, nil
+}
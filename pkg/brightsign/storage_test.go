@@ -0,0 +1,813 @@
+package brightsign
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestStorageService_GetStorageInfo(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/files/sd/" {
+			t.Errorf("Expected path /api/v1/files/sd/, got %s", r.URL.Path)
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{
+			"files":[{"name":"autorun.brs","type":"file","size":1024}],
+			"storageInfo":{"fileSystemType":"exfat","stats":{"sizeBytes":31914983424,"bytesFree":30000000000,"isReadOnly":false}}
+		}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	stats, err := client.Storage.GetStorageInfo("/storage/sd/")
+	if err != nil {
+		t.Fatalf("GetStorageInfo failed: %v", err)
+	}
+
+	if stats.Device != "sd" {
+		t.Errorf("Expected device sd, got %s", stats.Device)
+	}
+	if stats.FileSystemType != "exfat" {
+		t.Errorf("Expected filesystem exfat, got %s", stats.FileSystemType)
+	}
+	if stats.TotalBytes != 31914983424 {
+		t.Errorf("Expected total 31914983424, got %d", stats.TotalBytes)
+	}
+	if stats.FreeBytes != 30000000000 {
+		t.Errorf("Expected free 30000000000, got %d", stats.FreeBytes)
+	}
+	if stats.UsedBytes != 1914983424 {
+		t.Errorf("Expected used 1914983424, got %d", stats.UsedBytes)
+	}
+	if stats.Estimated {
+		t.Error("Expected reported stats, got an estimate")
+	}
+}
+
+func TestStorageService_GetStorageInfoFallback(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{"files":[
+			{"name":"autorun.brs","type":"file","size":1024},
+			{"name":"video.mp4","type":"file","size":4096}
+		]}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	stats, err := client.Storage.GetStorageInfo("usb1")
+	if err != nil {
+		t.Fatalf("GetStorageInfo failed: %v", err)
+	}
+
+	if !stats.Estimated {
+		t.Error("Expected an estimate when storageInfo is missing")
+	}
+	if stats.UsedBytes != 5120 {
+		t.Errorf("Expected used 5120, got %d", stats.UsedBytes)
+	}
+	if stats.TotalBytes != 0 {
+		t.Errorf("Expected unknown total (0), got %d", stats.TotalBytes)
+	}
+}
+
+func TestStorageService_ListFilesRaw(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{"name list", `{"data":{"result":["autorun.brs","video.mp4","media/"]}}`},
+		{"files key", `{"data":{"result":{"files":["autorun.brs","video.mp4","media/"]}}}`},
+		{"text result", `{"data":{"result":"autorun.brs\nvideo.mp4\nmedia/\n"}}`},
+		{"plain text", "autorun.brs\nvideo.mp4\nmedia/\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.RawQuery != "raw" {
+					t.Errorf("Expected raw query, got %q", r.URL.RawQuery)
+				}
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+			client.baseURL = server.URL + "/api/v1"
+
+			files, err := client.Storage.ListFiles("/storage/sd/", &ListOptions{Raw: true})
+			if err != nil {
+				t.Fatalf("ListFiles failed: %v", err)
+			}
+
+			if len(files) != 3 {
+				t.Fatalf("Expected 3 entries, got %d: %+v", len(files), files)
+			}
+
+			if files[0].Name != "autorun.brs" || files[0].Path != "/storage/sd/autorun.brs" || files[0].Type != "file" {
+				t.Errorf("Unexpected first entry: %+v", files[0])
+			}
+
+			if files[2].Name != "media" || files[2].Type != "directory" {
+				t.Errorf("Expected media directory, got %+v", files[2])
+			}
+		})
+	}
+}
+
+func TestStorageService_ListFilesShapes(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected []string
+	}{
+		{"array", `{"data":{"result":[{"name":"a.mp4","type":"file"},{"name":"media","type":"directory"}]}}`, []string{"a.mp4", "media"}},
+		{"files key", `{"data":{"result":{"files":[{"name":"a.mp4","type":"file"},{"name":"media","type":"directory"}]}}}`, []string{"a.mp4", "media"}},
+		{"empty array", `{"data":{"result":[]}}`, []string{}},
+		{"empty files key", `{"data":{"result":{"files":null}}}`, []string{}},
+		{"null result", `{"data":{"result":null}}`, []string{}},
+		{"missing result", `{"data":{}}`, []string{}},
+		{"single file", `{"data":{"result":{"name":"a.mp4","type":"file","size":10}}}`, []string{"a.mp4"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+			client.baseURL = server.URL + "/api/v1"
+
+			files, err := client.Storage.ListFiles("/storage/sd/", nil)
+			if err != nil {
+				t.Fatalf("ListFiles failed: %v", err)
+			}
+			if files == nil {
+				t.Fatal("Expected an empty slice, got nil")
+			}
+
+			names := []string{}
+			for _, file := range files {
+				names = append(names, file.Name)
+			}
+			if !reflect.DeepEqual(names, tt.expected) {
+				t.Errorf("Expected %v, got %v", tt.expected, names)
+			}
+		})
+	}
+}
+
+func TestStorageService_ReadFile(t *testing.T) {
+	content := "Sub Main()\n    print \"hello\"\nEnd Sub\n"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/files/sd/autorun.brs" {
+			t.Errorf("Expected path /api/v1/files/sd/autorun.brs, got %s", r.URL.Path)
+		}
+		if r.URL.RawQuery != "contents&stream" {
+			t.Errorf("Expected contents&stream query, got %q", r.URL.RawQuery)
+		}
+		w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	var buf bytes.Buffer
+	if err := client.Storage.ReadFile("/storage/sd/autorun.brs", &buf); err != nil {
+		t.Fatalf("ReadFile failed: %v", err)
+	}
+
+	if buf.String() != content {
+		t.Errorf("Expected %q, got %q", content, buf.String())
+	}
+}
+
+func TestStorageService_UploadReader(t *testing.T) {
+	const content = "config=1\n"
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PUT" || r.URL.Path != "/api/v1/files/sd/" {
+			t.Errorf("Expected PUT /api/v1/files/sd/, got %s %s", r.Method, r.URL.Path)
+		}
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			t.Fatalf("Expected multipart file upload: %v", err)
+		}
+		defer file.Close()
+
+		if header.Filename != "settings.txt" {
+			t.Errorf("Expected filename settings.txt, got %s", header.Filename)
+		}
+
+		var buf bytes.Buffer
+		buf.ReadFrom(file)
+		if buf.String() != content {
+			t.Errorf("Expected content %q, got %q", content, buf.String())
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	if err := client.Storage.UploadReader(strings.NewReader(content), -1, "/storage/sd/settings.txt"); err != nil {
+		t.Fatalf("UploadReader failed: %v", err)
+	}
+
+	if err := client.Storage.UploadReader(strings.NewReader(content), 100, "/storage/sd/settings.txt"); err == nil {
+		t.Error("Expected an error when fewer bytes than size are read")
+	}
+}
+
+func TestStorageService_UploadFileDestination(t *testing.T) {
+	tests := []struct {
+		name     string
+		remote   string
+		apiPath  string
+		filename string
+	}{
+		{"directory", "/storage/sd/", "/api/v1/files/sd/", "video.mp4"},
+		{"subdirectory", "/storage/sd/media/", "/api/v1/files/sd/media/", "video.mp4"},
+		{"renamed", "/storage/sd/clip.mp4", "/api/v1/files/sd/", "clip.mp4"},
+	}
+
+	localFile := filepath.Join(t.TempDir(), "video.mp4")
+	if err := os.WriteFile(localFile, []byte("video"), 0644); err != nil {
+		t.Fatalf("Failed to create local file: %v", err)
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != "PUT" || r.URL.Path != test.apiPath {
+					t.Errorf("Expected PUT %s, got %s %s", test.apiPath, r.Method, r.URL.Path)
+				}
+
+				_, header, err := r.FormFile("file")
+				if err != nil {
+					t.Fatalf("Expected multipart file upload: %v", err)
+				}
+				if header.Filename != test.filename {
+					t.Errorf("Expected filename %s, got %s", test.filename, header.Filename)
+				}
+			}))
+			defer server.Close()
+
+			client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+			client.baseURL = server.URL + "/api/v1"
+
+			if err := client.Storage.UploadFile(localFile, test.remote); err != nil {
+				t.Fatalf("UploadFile failed: %v", err)
+			}
+		})
+	}
+}
+
+func TestStorageService_DownloadFileResume(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+
+	tests := []struct {
+		name          string
+		honorRange    bool
+		partial       []byte
+		resumed       bool
+		expectedRange string
+	}{
+		{"Resume", true, content[:4321], true, "bytes=4321-"},
+		{"AlreadyComplete", true, content, true, "bytes=10000-"},
+		{"RangeIgnored", false, content[:4321], false, "bytes=4321-"},
+		{"RangeIgnoredStalePartial", false, []byte("stale data"), false, "bytes=10-"},
+		{"NoPartial", true, nil, false, ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var gotRange string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/api/v1/files/sd/video.mp4" {
+					t.Errorf("Expected path /api/v1/files/sd/video.mp4, got %s", r.URL.Path)
+				}
+				gotRange = r.Header.Get("Range")
+				if test.honorRange {
+					http.ServeContent(w, r, "video.mp4", time.Time{}, bytes.NewReader(content))
+					return
+				}
+				w.Write(content)
+			}))
+			defer server.Close()
+
+			client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+			client.baseURL = server.URL + "/api/v1"
+
+			localPath := filepath.Join(t.TempDir(), "video.mp4")
+			if test.partial != nil {
+				if err := os.WriteFile(localPath, test.partial, 0644); err != nil {
+					t.Fatalf("Failed to write partial file: %v", err)
+				}
+			}
+
+			resumed, err := client.Storage.DownloadFileResume("/storage/sd/video.mp4", localPath)
+			if err != nil {
+				t.Fatalf("DownloadFileResume failed: %v", err)
+			}
+
+			if resumed != test.resumed {
+				t.Errorf("Expected resumed %v, got %v", test.resumed, resumed)
+			}
+			if gotRange != test.expectedRange {
+				t.Errorf("Expected Range %q, got %q", test.expectedRange, gotRange)
+			}
+
+			data, err := os.ReadFile(localPath)
+			if err != nil {
+				t.Fatalf("Failed to read local file: %v", err)
+			}
+			if !bytes.Equal(data, content) {
+				t.Errorf("Expected %d bytes of original content, got %d bytes", len(content), len(data))
+			}
+		})
+	}
+}
+
+func TestStorageService_DownloadFileResumeSendsRangeAfterAuth(t *testing.T) {
+	content := []byte("abcdefghij")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !validDigest(r, "admin", "password", "abc123") {
+			w.Header().Set("WWW-Authenticate", `Digest realm="BrightSign", nonce="abc123", qop="auth"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		http.ServeContent(w, r, "file.txt", time.Time{}, bytes.NewReader(content))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	localPath := filepath.Join(t.TempDir(), "file.txt")
+	os.WriteFile(localPath, content[:6], 0644)
+
+	resumed, err := client.Storage.DownloadFileResume("/storage/sd/file.txt", localPath)
+	if err != nil || !resumed {
+		t.Fatalf("Expected a resumed download, got %v, %v", resumed, err)
+	}
+
+	if data, _ := os.ReadFile(localPath); !bytes.Equal(data, content) {
+		t.Errorf("Expected %q, got %q", content, data)
+	}
+}
+
+// newRangeServer serves content at /api/v1/files/sd/video.mp4, honoring Range
+// headers only if ranges is set. Without ranges it still advertises them, like
+// a proxy that strips range support. Each response is written in chunks with
+// a pause between them to simulate a slow link.
+func newRangeServer(content []byte, ranges bool, chunkDelay time.Duration) (*httptest.Server, *int32) {
+	var gets int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" {
+			atomic.AddInt32(&gets, 1)
+		}
+		if !ranges {
+			w.Header().Set("Accept-Ranges", "bytes")
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			if r.Method == "GET" {
+				w.Write(content)
+			}
+			return
+		}
+		http.ServeContent(&slowWriter{ResponseWriter: w, delay: chunkDelay}, r, "video.mp4", time.Time{}, bytes.NewReader(content))
+	}))
+	return server, &gets
+}
+
+// slowWriter pauses after each write
+type slowWriter struct {
+	http.ResponseWriter
+	delay time.Duration
+}
+
+func (w *slowWriter) Write(p []byte) (int, error) {
+	n, err := w.ResponseWriter.Write(p)
+	time.Sleep(w.delay)
+	return n, err
+}
+
+func TestStorageService_DownloadFileParallel(t *testing.T) {
+	content := make([]byte, 1<<20+12345)
+	for i := range content {
+		content[i] = byte(i * 7)
+	}
+
+	for _, ranges := range []bool{true, false} {
+		t.Run(fmt.Sprintf("ranges=%v", ranges), func(t *testing.T) {
+			server, gets := newRangeServer(content, ranges, 0)
+			defer server.Close()
+
+			client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+			client.baseURL = server.URL + "/api/v1"
+
+			dir := t.TempDir()
+			single := filepath.Join(dir, "single.mp4")
+			parallel := filepath.Join(dir, "parallel.mp4")
+
+			if err := client.Storage.DownloadFile("/storage/sd/video.mp4", single); err != nil {
+				t.Fatalf("DownloadFile failed: %v", err)
+			}
+			atomic.StoreInt32(gets, 0)
+
+			if err := client.Storage.DownloadFileParallel("/storage/sd/video.mp4", parallel, 5); err != nil {
+				t.Fatalf("DownloadFileParallel failed: %v", err)
+			}
+
+			// With ranges every part is its own GET; without, the parts give up
+			// and a single stream follows
+			if ranges && atomic.LoadInt32(gets) != 5 {
+				t.Errorf("Expected 5 range requests, got %d", atomic.LoadInt32(gets))
+			}
+
+			singleData, _ := os.ReadFile(single)
+			parallelData, _ := os.ReadFile(parallel)
+			if !bytes.Equal(singleData, content) || !bytes.Equal(parallelData, singleData) {
+				t.Errorf("Parallel download (%d bytes) does not match single-stream download (%d bytes)", len(parallelData), len(singleData))
+			}
+		})
+	}
+}
+
+func BenchmarkDownloadSingleStream(b *testing.B) {
+	benchmarkDownload(b, 1)
+}
+
+func BenchmarkDownloadParallel4(b *testing.B) {
+	benchmarkDownload(b, 4)
+}
+
+func benchmarkDownload(b *testing.B, parts int) {
+	content := bytes.Repeat([]byte("x"), 4<<20)
+	server, _ := newRangeServer(content, true, time.Millisecond)
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+	localPath := filepath.Join(b.TempDir(), "video.mp4")
+
+	b.SetBytes(int64(len(content)))
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := client.Storage.DownloadFileParallel("/storage/sd/video.mp4", localPath, parts); err != nil {
+			b.Fatalf("Download failed: %v", err)
+		}
+	}
+}
+
+func TestStorageService_FormatStorageRejectsPaths(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	for _, device := range []string{"", "sd/media", "..", "../files/sd", "sd?x", `sd\x`} {
+		if err := client.Storage.FormatStorage(device); err == nil {
+			t.Errorf("Expected FormatStorage(%q) to be rejected", device)
+		}
+	}
+}
+
+func TestStoragePathToAPI(t *testing.T) {
+	tests := []struct {
+		path    string
+		kind    pathKind
+		want    string
+		wantErr bool
+	}{
+		{path: "/storage/sd/file.txt", want: "/files/sd/file.txt"},
+		{path: "/storage/sd/dir/", want: "/files/sd/dir/"},
+		{path: "/storage/usb1/", want: "/files/usb1/"},
+		{path: "/storage/sd", want: "/files/sd"},
+		{path: "/storage/sd//media/./clip.mp4", want: "/files/sd/media/clip.mp4"},
+
+		// Directories always end with a slash, files never do
+		{path: "/storage/sd", kind: pathDir, want: "/files/sd/"},
+		{path: "/storage/sd/", kind: pathDir, want: "/files/sd/"},
+		{path: "/storage/sd/media//", kind: pathDir, want: "/files/sd/media/"},
+		{path: "/storage/sd/file.txt", kind: pathFile, want: "/files/sd/file.txt"},
+		{path: "/storage/sd/file.txt/", kind: pathFile, want: "/files/sd/file.txt"},
+
+		{path: "sd/file.txt", wantErr: true},
+		{path: "/sd/file.txt", wantErr: true},
+		{path: "/files/sd/file.txt", wantErr: true},
+		{path: "/storage", wantErr: true},
+		{path: "/storage/", wantErr: true},
+		{path: "/storage//file.txt", wantErr: true},
+		{path: "/storage/sd/../usb1/file.txt", wantErr: true},
+		{path: "/storage/../etc/passwd", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := storagePathToAPI(tt.path, tt.kind)
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("storagePathToAPI(%q) = %q, expected an error", tt.path, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("storagePathToAPI(%q) failed: %v", tt.path, err)
+		} else if got != tt.want {
+			t.Errorf("storagePathToAPI(%q, %d) = %q, want %q", tt.path, tt.kind, got, tt.want)
+		}
+	}
+}
+
+func TestStorageService_NormalizesPaths(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		if r.Method == "GET" && strings.HasSuffix(r.URL.Path, "/") {
+			w.Write([]byte(`{"data":{"result":[]}}`))
+			return
+		}
+		w.Write([]byte("content"))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	// Every form of a directory lists the same API path
+	for _, dir := range []string{"/storage/sd/media", "/storage/sd/media/", "/storage/sd//media"} {
+		if _, err := client.Storage.ListFiles(dir, nil); err != nil {
+			t.Fatalf("ListFiles(%q) failed: %v", dir, err)
+		}
+	}
+
+	// A file is requested without a trailing slash however it was given
+	for _, file := range []string{"/storage/sd/video.mp4", "/storage/sd/video.mp4/"} {
+		reader, _, err := client.Storage.OpenFile(file)
+		if err != nil {
+			t.Fatalf("OpenFile(%q) failed: %v", file, err)
+		}
+		reader.Close()
+	}
+
+	expected := []string{
+		"GET /api/v1/files/sd/media/",
+		"GET /api/v1/files/sd/media/",
+		"GET /api/v1/files/sd/media/",
+		"GET /api/v1/files/sd/video.mp4",
+		"GET /api/v1/files/sd/video.mp4",
+	}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("Expected requests %v, got %v", expected, requests)
+	}
+}
+
+func TestStorageService_RejectsMalformedPaths(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("Unexpected request for %s", r.URL.Path)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	if _, err := client.Storage.ListFiles("sd/", nil); err == nil {
+		t.Error("Expected ListFiles to reject a path without /storage/")
+	}
+	if err := client.Storage.DeleteFile("/storage/"); err == nil {
+		t.Error("Expected DeleteFile to reject a path without a device")
+	}
+	if err := client.Storage.UploadReader(strings.NewReader("x"), 1, "/sd/file.txt"); err == nil {
+		t.Error("Expected UploadReader to reject a path without /storage/")
+	}
+	for _, device := range []string{"", "..", "sd/../.."} {
+		if _, err := client.Storage.GetStorageInfo(device); err == nil {
+			t.Errorf("Expected GetStorageInfo to reject device %q", device)
+		}
+	}
+}
+
+func TestStorageService_UploadFileAtomic(t *testing.T) {
+	localPath := filepath.Join(t.TempDir(), "video.mp4")
+	if err := os.WriteFile(localPath, []byte("video"), 0644); err != nil {
+		t.Fatalf("Failed to create local file: %v", err)
+	}
+
+	// The fake player keeps the names in /storage/sd/; a failed upload still
+	// leaves a partial file behind, as an interrupted transfer would
+	var (
+		mu       sync.Mutex
+		files    map[string]bool
+		requests []string
+		failPut  bool
+	)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		switch {
+		case r.Method == "PUT" && r.URL.Path == "/api/v1/files/sd/":
+			_, header, err := r.FormFile("file")
+			if err != nil {
+				t.Errorf("Expected multipart file upload: %v", err)
+				return
+			}
+			requests = append(requests, "PUT "+header.Filename)
+			files[header.Filename] = true
+			if failPut {
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+		case r.Method == "POST" && r.URL.Path == "/api/v1/files/sd/":
+			var payload map[string]string
+			json.NewDecoder(r.Body).Decode(&payload)
+			requests = append(requests, "RENAME "+payload["oldName"]+" "+payload["newName"])
+			delete(files, payload["oldName"])
+			files[payload["newName"]] = true
+		case r.Method == "DELETE":
+			name := strings.TrimPrefix(r.URL.Path, "/api/v1/files/sd/")
+			requests = append(requests, "DELETE "+name)
+			delete(files, name)
+		default:
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	files, requests = map[string]bool{}, nil
+	if err := client.Storage.UploadFileWithOptions(localPath, "/storage/sd/video.mp4", &UploadOptions{Atomic: true}); err != nil {
+		t.Fatalf("Atomic upload failed: %v", err)
+	}
+	expected := []string{"PUT .video.mp4.tmp", "RENAME .video.mp4.tmp video.mp4"}
+	if strings.Join(requests, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected %q, got %q", expected, requests)
+	}
+	if len(files) != 1 || !files["video.mp4"] {
+		t.Errorf("Expected only video.mp4 on the player, got %v", files)
+	}
+
+	files, requests, failPut = map[string]bool{}, nil, true
+	if err := client.Storage.UploadFileWithOptions(localPath, "/storage/sd/", &UploadOptions{Atomic: true}); err == nil {
+		t.Fatal("Expected the failed upload to return an error")
+	}
+	expected = []string{"PUT .video.mp4.tmp", "DELETE .video.mp4.tmp"}
+	if strings.Join(requests, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("Expected %q, got %q", expected, requests)
+	}
+	if len(files) != 0 {
+		t.Errorf("Expected no files left after a failed upload, got %v", files)
+	}
+}
+
+// newTreeServer serves listings of a nested tree under /storage/sd/media and
+// records the DELETE requests it receives
+func newTreeServer(t *testing.T, deletes *[]string) *httptest.Server {
+	t.Helper()
+	listings := map[string]string{
+		"/api/v1/files/sd/":           `[{"name":"media","type":"directory"}]`,
+		"/api/v1/files/sd/media/":     `[{"name":"a.mp4","type":"file"},{"name":"sub","type":"directory"}]`,
+		"/api/v1/files/sd/media/sub/": `[{"name":"b.mp4","type":"file"}]`,
+	}
+
+	var mu sync.Mutex
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case "GET":
+			listing, ok := listings[r.URL.Path]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Write([]byte(`{"data":{"result":` + listing + `}}`))
+		case "DELETE":
+			mu.Lock()
+			*deletes = append(*deletes, r.URL.Path)
+			mu.Unlock()
+			w.Write([]byte(`{"data":{"result":{"success":true}}}`))
+		}
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestStorageService_DeleteRecursive(t *testing.T) {
+	var deletes []string
+	server := newTreeServer(t, &deletes)
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	deleted, err := client.Storage.DeleteRecursive("/storage/sd/media")
+	if err != nil {
+		t.Fatalf("DeleteRecursive failed: %v", err)
+	}
+	if deleted != 4 {
+		t.Errorf("Expected 4 entries deleted, got %d", deleted)
+	}
+
+	// Contents go before the directory holding them
+	expected := []string{
+		"/api/v1/files/sd/media/sub/b.mp4",
+		"/api/v1/files/sd/media/sub/",
+		"/api/v1/files/sd/media/a.mp4",
+		"/api/v1/files/sd/media/",
+	}
+	if !reflect.DeepEqual(deletes, expected) {
+		t.Errorf("Expected deletes %v, got %v", expected, deletes)
+	}
+}
+
+func TestStorageService_DeleteRecursiveRefusesDeviceRoot(t *testing.T) {
+	var deletes []string
+	server := newTreeServer(t, &deletes)
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	for _, root := range []string{"/storage/sd", "/storage/sd/", "/storage/sd//", "/storage/sd/media/.."} {
+		if _, err := client.Storage.DeleteRecursive(root); err == nil {
+			t.Errorf("Expected %s to be refused", root)
+		}
+	}
+	if len(deletes) != 0 {
+		t.Errorf("Expected nothing deleted, got %v", deletes)
+	}
+}
+
+func TestStorageService_RenameAndCreateDirectoryNormalizePaths(t *testing.T) {
+	var mu sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		name := ""
+		if r.Method == "POST" {
+			var payload map[string]string
+			json.NewDecoder(r.Body).Decode(&payload)
+			name = payload["oldName"]
+		} else {
+			name = r.FormValue("directory")
+		}
+		mu.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path+" "+name)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":{}}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	for _, dir := range []string{"/storage/sd/media/old", "/storage/sd/media/old/"} {
+		if err := client.Storage.RenameFile(dir, "new"); err != nil {
+			t.Fatalf("RenameFile(%q) failed: %v", dir, err)
+		}
+		if err := client.Storage.CreateDirectory(dir); err != nil {
+			t.Fatalf("CreateDirectory(%q) failed: %v", dir, err)
+		}
+	}
+
+	// Both forms address the parent directory and name the last element
+	expected := []string{
+		"POST /api/v1/files/sd/media/ old",
+		"PUT /api/v1/files/sd/media/ old",
+		"POST /api/v1/files/sd/media/ old",
+		"PUT /api/v1/files/sd/media/ old",
+	}
+	if !reflect.DeepEqual(requests, expected) {
+		t.Errorf("Expected requests %v, got %v", expected, requests)
+	}
+}
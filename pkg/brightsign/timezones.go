@@ -0,0 +1,131 @@
+package brightsign
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// defaultTimezones is used when the player does not list its timezones. It
+// covers the common IANA names and the POSIX zones older firmware expects.
+var defaultTimezones = []string{
+	"UTC", "GMT",
+	"EST", "CST", "MST", "PST", "AKST", "HST",
+	"EST5EDT", "CST6CDT", "MST7MDT", "PST8PDT",
+	"Africa/Cairo", "Africa/Johannesburg", "Africa/Lagos", "Africa/Nairobi",
+	"America/Anchorage", "America/Argentina/Buenos_Aires", "America/Bogota",
+	"America/Chicago", "America/Denver", "America/Halifax", "America/Lima",
+	"America/Los_Angeles", "America/Mexico_City", "America/New_York",
+	"America/Phoenix", "America/Santiago", "America/Sao_Paulo",
+	"America/St_Johns", "America/Toronto", "America/Vancouver",
+	"Asia/Bangkok", "Asia/Dubai", "Asia/Hong_Kong", "Asia/Jakarta",
+	"Asia/Jerusalem", "Asia/Karachi", "Asia/Kolkata", "Asia/Manila",
+	"Asia/Seoul", "Asia/Shanghai", "Asia/Singapore", "Asia/Taipei",
+	"Asia/Tokyo",
+	"Atlantic/Reykjavik",
+	"Australia/Adelaide", "Australia/Brisbane", "Australia/Darwin",
+	"Australia/Melbourne", "Australia/Perth", "Australia/Sydney",
+	"Europe/Amsterdam", "Europe/Athens", "Europe/Berlin", "Europe/Brussels",
+	"Europe/Dublin", "Europe/Helsinki", "Europe/Istanbul", "Europe/Lisbon",
+	"Europe/London", "Europe/Madrid", "Europe/Moscow", "Europe/Paris",
+	"Europe/Prague", "Europe/Rome", "Europe/Stockholm", "Europe/Vienna",
+	"Europe/Warsaw", "Europe/Zurich",
+	"Pacific/Auckland", "Pacific/Honolulu",
+}
+
+// ListTimezones returns the timezone identifiers the player accepts. Players
+// without a timezone endpoint get a bundled list of common zones.
+func (s *InfoService) ListTimezones() ([]string, error) {
+	resp, err := s.client.doRequest("GET", "/time/timezones/", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var result struct {
+		Data struct {
+			Result []string `json:"result"`
+		} `json:"data"`
+	}
+
+	if err := parseJSON(resp, &result); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return append([]string(nil), defaultTimezones...), nil
+		}
+		return nil, err
+	}
+
+	zones := result.Data.Result
+	sort.Strings(zones)
+	return zones, nil
+}
+
+// ValidateTimezone checks that zone is one of zones. The error suggests the
+// closest matches for likely typos.
+func ValidateTimezone(zone string, zones []string) error {
+	for _, z := range zones {
+		if z == zone {
+			return nil
+		}
+	}
+
+	msg := fmt.Sprintf("unknown timezone %q", zone)
+	if suggestions := suggestTimezones(zone, zones); len(suggestions) > 0 {
+		msg += fmt.Sprintf(" (did you mean %s?)", strings.Join(suggestions, ", "))
+	}
+	return errors.New(msg)
+}
+
+// suggestTimezones returns up to three zones within a small edit distance of
+// zone, closest first. A case-insensitive match is always suggested alone.
+func suggestTimezones(zone string, zones []string) []string {
+	type candidate struct {
+		zone     string
+		distance int
+	}
+
+	lower := strings.ToLower(zone)
+	maxDistance := len(zone)/4 + 1
+
+	var candidates []candidate
+	for _, z := range zones {
+		if strings.ToLower(z) == lower {
+			return []string{z}
+		}
+		if d := editDistance(lower, strings.ToLower(z)); d <= maxDistance {
+			candidates = append(candidates, candidate{z, d})
+		}
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].distance < candidates[j].distance
+	})
+
+	var suggestions []string
+	for i := 0; i < len(candidates) && i < 3; i++ {
+		suggestions = append(suggestions, candidates[i].zone)
+	}
+	return suggestions
+}
+
+// editDistance returns the Levenshtein distance between a and b
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
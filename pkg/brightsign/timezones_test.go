@@ -0,0 +1,74 @@
+package brightsign
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidateTimezone(t *testing.T) {
+	if err := ValidateTimezone("America/New_York", defaultTimezones); err != nil {
+		t.Errorf("Expected America/New_York to be valid, got %v", err)
+	}
+
+	err := ValidateTimezone("Not/AZone", defaultTimezones)
+	if err == nil {
+		t.Fatal("Expected Not/AZone to be rejected")
+	}
+	if strings.Contains(err.Error(), "did you mean") {
+		t.Errorf("Expected no suggestions for Not/AZone, got %v", err)
+	}
+
+	err = ValidateTimezone("Amerca/New_Yrok", defaultTimezones)
+	if err == nil || !strings.Contains(err.Error(), "did you mean America/New_York") {
+		t.Errorf("Expected a suggestion of America/New_York, got %v", err)
+	}
+
+	err = ValidateTimezone("europe/london", defaultTimezones)
+	if err == nil || !strings.HasSuffix(err.Error(), "(did you mean Europe/London?)") {
+		t.Errorf("Expected a single case-insensitive suggestion, got %v", err)
+	}
+}
+
+func TestInfoService_ListTimezones(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/time/timezones/" {
+			t.Errorf("Expected path /api/v1/time/timezones/, got %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"result":["UTC","Europe/Paris","America/Chicago"]}}`))
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	zones, err := client.Info.ListTimezones()
+	if err != nil {
+		t.Fatalf("ListTimezones failed: %v", err)
+	}
+
+	expected := []string{"America/Chicago", "Europe/Paris", "UTC"}
+	if !reflect.DeepEqual(zones, expected) {
+		t.Errorf("Expected %v, got %v", expected, zones)
+	}
+}
+
+func TestInfoService_ListTimezonesFallback(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	zones, err := client.Info.ListTimezones()
+	if err != nil {
+		t.Fatalf("ListTimezones failed: %v", err)
+	}
+
+	if len(zones) != len(defaultTimezones) {
+		t.Errorf("Expected the bundled list of %d zones, got %d", len(defaultTimezones), len(zones))
+	}
+}
@@ -0,0 +1,145 @@
+package brightsign
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// maxTraceBody is the number of body bytes included in trace output
+const maxTraceBody = 1024
+
+// redacted replaces secret values in debug output and errors
+const redacted = "[REDACTED]"
+
+// secretParams are query parameter names whose values are masked
+const secretParams = `password|passwd|pwd|token|access_token|secret|api_key|apikey|signature|sig`
+
+// redactions mask credentials wherever they can appear in a logged or returned string
+var redactions = []struct {
+	pattern *regexp.Regexp
+	repl    string
+}{
+	// JSON password fields
+	{regexp.MustCompile(`("[A-Za-z_]*[Pp]assword"\s*:\s*)"(?:[^"\\]|\\.)*"`), `$1"` + redacted + `"`},
+	// Authorization headers
+	{regexp.MustCompile(`(?i)(authorization:\s*)[^\r\n]+`), `$1` + redacted},
+	// URL userinfo, plain and percent-encoded inside another URL's query
+	{regexp.MustCompile(`([A-Za-z][A-Za-z0-9+.-]*://)[^/\s@"]+@`), `$1` + redacted + `@`},
+	{regexp.MustCompile(`(?i)(%3A%2F%2F)(?:[^/\s%&"]|%(?:[013-9A-F][0-9A-F]|2[0-9A-E]))+%40`), `$1` + redacted + `%40`},
+	// Secret query parameters, plain and percent-encoded inside another URL's query
+	{regexp.MustCompile(`(?i)([?&](?:` + secretParams + `)=)[^&\s#"]*`), `$1` + redacted},
+	{regexp.MustCompile(`(?i)((?:%3F|%26)(?:` + secretParams + `)%3D)(?:[^&\s#%"]|%(?:[013-9A-F][0-9A-F]|2[0-57-9A-F]))*`), `$1` + redacted},
+}
+
+// redact masks passwords, Authorization headers, URL credentials and secret
+// query parameters in s
+func redact(s string) string {
+	for _, r := range redactions {
+		s = r.pattern.ReplaceAllString(s, r.repl)
+	}
+	return s
+}
+
+// redactError masks the URL of a transport error, which Go includes in its
+// message along with any query string
+func redactError(err error) error {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		urlErr.URL = redact(urlErr.URL)
+	}
+	return err
+}
+
+// debugf writes a debug message to the configured logger when debug or trace is enabled
+func (c *Client) debugf(format string, args ...interface{}) {
+	if !c.debug && !c.trace {
+		return
+	}
+	fmt.Fprintln(c.logger, "DEBUG: "+redact(fmt.Sprintf(format, args...)))
+}
+
+// tracef writes a trace message to the configured logger when trace is enabled
+func (c *Client) tracef(format string, args ...interface{}) {
+	if !c.trace {
+		return
+	}
+	fmt.Fprintln(c.logger, "TRACE: "+redact(fmt.Sprintf(format, args...)))
+}
+
+// traceRequest logs the request line, headers and the start of the body
+func (c *Client) traceRequest(req *http.Request, body io.Reader) {
+	if !c.trace {
+		return
+	}
+
+	c.tracef("> %s %s", req.Method, req.URL)
+	c.traceHeaders(">", req.Header)
+
+	// Only in-memory bodies can be inspected without consuming them
+	if r, ok := body.(io.ReaderAt); ok && req.ContentLength > 0 {
+		size := req.ContentLength
+		if size > maxTraceBody {
+			size = maxTraceBody
+		}
+		buf := make([]byte, size)
+		n, _ := r.ReadAt(buf, 0)
+		c.tracef("> body (%d bytes): %s", req.ContentLength, traceBody(buf[:n], req.ContentLength))
+	}
+}
+
+// traceResponse logs the status, headers and the start of the body. The body
+// is peeked, so it remains fully readable by the caller.
+func (c *Client) traceResponse(resp *http.Response) {
+	if !c.trace {
+		return
+	}
+
+	c.tracef("< %s", resp.Status)
+	c.traceHeaders("<", resp.Header)
+
+	reader := bufio.NewReaderSize(resp.Body, maxTraceBody)
+	peek, _ := reader.Peek(maxTraceBody)
+	if len(peek) > 0 {
+		c.tracef("< body: %s", traceBody(peek, resp.ContentLength))
+	}
+	resp.Body = readCloser{Reader: reader, Closer: resp.Body}
+}
+
+// traceHeaders logs headers in sorted order with credentials redacted
+func (c *Client) traceHeaders(prefix string, header http.Header) {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value := strings.Join(header[name], ", ")
+		if strings.EqualFold(name, "Authorization") {
+			value = redacted
+		}
+		c.tracef("%s %s: %s", prefix, name, value)
+	}
+}
+
+// traceBody formats a body snippet for trace output, masking secrets
+func traceBody(snippet []byte, total int64) string {
+	text := redact(string(snippet))
+	if total > int64(len(snippet)) || len(snippet) == maxTraceBody {
+		text += "...(truncated)"
+	}
+	return text
+}
+
+// readCloser combines a reader with the closer of the original body
+type readCloser struct {
+	io.Reader
+	io.Closer
+}
@@ -0,0 +1,92 @@
+package brightsign
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Minimum BOS versions of version-gated endpoints
+const (
+	MinVersionRegistryFlush = "9.0.107"
+	MinVersionDisplay       = "9.0.189"
+)
+
+// APIVersion returns the firmware (BOS) version of the player, which
+// determines the DWS endpoints it offers. It is read once per client.
+func (c *Client) APIVersion() (string, error) {
+	c.versionMu.Lock()
+	defer c.versionMu.Unlock()
+	if c.version != "" {
+		return c.version, nil
+	}
+
+	info, err := c.Info.GetInfo()
+	if err != nil {
+		return "", err
+	}
+	c.version = info.FWVersion
+	return c.version, nil
+}
+
+// SupportsFeature reports whether the player runs minVersion or later. When
+// the version cannot be determined it returns true so the request itself
+// decides.
+func (c *Client) SupportsFeature(minVersion string) bool {
+	version, err := c.APIVersion()
+	if err != nil {
+		return true
+	}
+	if _, ok := parseVersion(version); !ok {
+		return true
+	}
+	return CompareVersions(version, minVersion) >= 0
+}
+
+// CompareVersions compares dotted version strings such as "9.0.189"
+// numerically and returns -1, 0 or 1. Missing components count as zero and
+// suffixes like "-rc1" are ignored.
+func CompareVersions(a, b string) int {
+	va, _ := parseVersion(a)
+	vb, _ := parseVersion(b)
+
+	for i := 0; i < len(va) || i < len(vb); i++ {
+		var x, y int
+		if i < len(va) {
+			x = va[i]
+		}
+		if i < len(vb) {
+			y = vb[i]
+		}
+		switch {
+		case x < y:
+			return -1
+		case x > y:
+			return 1
+		}
+	}
+	return 0
+}
+
+// parseVersion splits a version into its numeric components, stopping at the
+// first component that does not start with a digit
+func parseVersion(version string) ([]int, bool) {
+	var parts []int
+	for _, field := range strings.Split(strings.TrimPrefix(strings.TrimSpace(version), "v"), ".") {
+		end := 0
+		for end < len(field) && field[end] >= '0' && field[end] <= '9' {
+			end++
+		}
+		if end == 0 {
+			break
+		}
+		n, err := strconv.Atoi(field[:end])
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+		if end < len(field) {
+			break
+		}
+	}
+	return parts, len(parts) > 0
+}
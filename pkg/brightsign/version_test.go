@@ -0,0 +1,71 @@
+package brightsign
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"9.0.107", "9.0.107", 0},
+		{"9.0.106", "9.0.107", -1},
+		{"9.0.108", "9.0.107", 1},
+		{"9.0.189", "9.0.107", 1},
+		{"9.0.99", "9.0.107", -1},
+		{"8.5.47", "9.0.107", -1},
+		{"10.0.0", "9.0.189", 1},
+		{"9.0", "9.0.0", 0},
+		{"9.0.189.1", "9.0.189", 1},
+		{"9.0.189-rc1", "9.0.189", 0},
+		{"v9.0.190", "9.0.189", 1},
+	}
+
+	for _, test := range tests {
+		if got := CompareVersions(test.a, test.b); got != test.want {
+			t.Errorf("CompareVersions(%q, %q) = %d, want %d", test.a, test.b, got, test.want)
+		}
+	}
+}
+
+func TestClientSupportsFeature(t *testing.T) {
+	tests := []struct {
+		version string
+		want    bool
+	}{
+		{"9.0.106", false},
+		{"9.0.107", true},
+		{"9.0.110", true},
+		{"", true}, // unknown version does not block
+	}
+
+	for _, test := range tests {
+		t.Run(test.version, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if r.URL.Path != "/api/v1/info/" {
+					t.Errorf("Unexpected request %s", r.URL.Path)
+				}
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintf(w, `{"data":{"result":{"model":"XT1144","fwVersion":"%s"}}}`, test.version)
+			}))
+			defer server.Close()
+
+			client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+			client.baseURL = server.URL + "/api/v1"
+
+			if got := client.SupportsFeature(MinVersionRegistryFlush); got != test.want {
+				t.Errorf("SupportsFeature(%s) on %q = %v, want %v", MinVersionRegistryFlush, test.version, got, test.want)
+			}
+			client.SupportsFeature(MinVersionDisplay)
+			if test.version != "" && requests != 1 {
+				t.Errorf("Expected the version to be read once, got %d requests", requests)
+			}
+		})
+	}
+}
@@ -1,9 +1,19 @@
 package brightsign
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
 )
 
+// VideoConnectors are the connector names GetAllOutputInfo probes, since the
+// DWS has no endpoint listing a player's outputs
+var VideoConnectors = []string{"hdmi", "vga", "component"}
+
+// MaxVideoDevices is the number of device indexes GetAllOutputInfo tries on
+// each connector
+const MaxVideoDevices = 4
+
 // VideoService handles video output management
 type VideoService struct {
 	client *Client
@@ -19,6 +29,9 @@ type VideoOutputInfo struct {
 	RefreshRate  int    `json:"refreshRate"`
 	InterlaceMode string `json:"interlaceMode,omitempty"`
 	PreferredMode string `json:"preferredMode,omitempty"`
+
+	// Error is set by GetAllOutputInfo when this output could not be read
+	Error string `json:"error,omitempty"`
 }
 
 // EDIDInfo represents EDID information from connected display
@@ -73,6 +86,51 @@ func (s *VideoService) GetOutputInfo(connector, device string) (*VideoOutputInfo
 	return &result.Data.Result, nil
 }
 
+// GetAllOutputInfo returns the output information of every video output the
+// player has, found by probing VideoConnectors with device indexes from 0
+// until the player answers 404. An output that fails to read is returned
+// with only its address and Error set, so one bad output does not hide the
+// others; an error is returned only if no output could be read or the
+// player rejected the credentials. A player without outputs returns an
+// empty slice.
+func (s *VideoService) GetAllOutputInfo() ([]VideoOutputInfo, error) {
+	outputs := []VideoOutputInfo{}
+	var firstErr error
+	succeeded := 0
+	for _, connector := range VideoConnectors {
+		for device := 0; device < MaxVideoDevices; device++ {
+			info, err := s.GetOutputInfo(connector, fmt.Sprint(device))
+			if errors.Is(err, ErrNotFound) {
+				break
+			}
+			if errors.Is(err, ErrUnauthorized) || errors.Is(err, ErrForbidden) {
+				return nil, err
+			}
+			if err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				outputs = append(outputs, VideoOutputInfo{Connector: connector, Device: fmt.Sprint(device), Error: err.Error()})
+				continue
+			}
+
+			// Older firmware leaves out the address fields
+			if info.Connector == "" {
+				info.Connector = connector
+			}
+			if info.Device == "" {
+				info.Device = fmt.Sprint(device)
+			}
+			outputs = append(outputs, *info)
+			succeeded++
+		}
+	}
+	if succeeded == 0 && firstErr != nil {
+		return nil, firstErr
+	}
+	return outputs, nil
+}
+
 // GetEDID gets EDID information from connected display
 func (s *VideoService) GetEDID(connector, device string) (*EDIDInfo, error) {
 	path := fmt.Sprintf("/video/%s/output/%s/edid/", connector, device)
@@ -84,7 +142,7 @@ func (s *VideoService) GetEDID(connector, device string) (*EDIDInfo, error) {
 
 	var result struct {
 		Data struct {
-			Result EDIDInfo `json:"result"`
+			Result json.RawMessage `json:"result"`
 		} `json:"data"`
 	}
 
@@ -92,7 +150,22 @@ func (s *VideoService) GetEDID(connector, device string) (*EDIDInfo, error) {
 		return nil, err
 	}
 
-	return &result.Data.Result, nil
+	// Some firmware returns the raw EDID as a hex string rather than decoded fields
+	var rawHex string
+	if err := json.Unmarshal(result.Data.Result, &rawHex); err == nil {
+		raw, err := ParseEDIDHex(rawHex)
+		if err != nil {
+			return nil, err
+		}
+		return DecodeEDID(raw)
+	}
+
+	var edid EDIDInfo
+	if err := json.Unmarshal(result.Data.Result, &edid); err != nil {
+		return nil, fmt.Errorf("failed to parse EDID: %w", err)
+	}
+
+	return &edid, nil
 }
 
 // GetPowerSaveStatus returns power save status
@@ -126,13 +199,9 @@ func (s *VideoService) SetPowerSave(connector, device string, enabled bool) erro
 	if err != nil {
 		return err
 	}
-	resp.Body.Close()
+	defer resp.Body.Close()
 
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("failed to set power save: status %d", resp.StatusCode)
-	}
-
-	return nil
+	return checkResponse(resp, "failed to set power save")
 }
 
 // GetAvailableModes gets available video modes
@@ -154,6 +223,10 @@ func (s *VideoService) GetAvailableModes(connector, device string) ([]VideoModeI
 		return nil, err
 	}
 
+	if result.Data.Result == nil {
+		return []VideoModeInfo{}, nil
+	}
+
 	return result.Data.Result, nil
 }
 
@@ -188,13 +261,9 @@ func (s *VideoService) SetVideoMode(connector, device, mode string) error {
 	if err != nil {
 		return err
 	}
-	resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("failed to set video mode: status %d", resp.StatusCode)
-	}
+	defer resp.Body.Close()
 
-	return nil
+	return checkResponse(resp, "failed to set video mode")
 }
 
 // SendCEC sends CEC payload out of HDMI port (experimental)
@@ -205,11 +274,7 @@ func (s *VideoService) SendCEC(hexCommand string) error {
 	if err != nil {
 		return err
 	}
-	resp.Body.Close()
-
-	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
-		return fmt.Errorf("failed to send CEC command: status %d", resp.StatusCode)
-	}
+	defer resp.Body.Close()
 
-	return nil
+	return checkResponse(resp, "failed to send CEC command")
 }
\ No newline at end of file
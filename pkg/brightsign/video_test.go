@@ -0,0 +1,107 @@
+package brightsign
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestVideoService_GetAllOutputInfo(t *testing.T) {
+	// A video wall player with two HDMI outputs, one of them without a display
+	outputs := map[string]VideoOutputInfo{
+		"/api/v1/video/hdmi/output/0/": {Connector: "hdmi", Device: "0", Connected: true, Width: 1920, Height: 1080, RefreshRate: 60},
+		"/api/v1/video/hdmi/output/1/": {Connected: false},
+	}
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		info, ok := outputs[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"data": map[string]interface{}{"result": info},
+		})
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	result, err := client.Video.GetAllOutputInfo()
+	if err != nil {
+		t.Fatalf("GetAllOutputInfo failed: %v", err)
+	}
+
+	if len(result) != 2 {
+		t.Fatalf("Expected 2 outputs, got %d: %+v", len(result), result)
+	}
+	if result[0].Connector != "hdmi" || result[0].Device != "0" || result[0].Width != 1920 {
+		t.Errorf("Unexpected first output: %+v", result[0])
+	}
+	if result[1].Connector != "hdmi" || result[1].Device != "1" || result[1].Connected {
+		t.Errorf("Expected the missing address of the second output to be filled in, got %+v", result[1])
+	}
+
+	// hdmi 0-2, then the first device of each remaining connector
+	if expected := 3 + len(VideoConnectors) - 1; requests != expected {
+		t.Errorf("Expected probing to stop at the first missing device, got %d requests (want %d)", requests, expected)
+	}
+}
+
+func TestVideoService_GetAllOutputInfoError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	if _, err := client.Video.GetAllOutputInfo(); err == nil {
+		t.Error("Expected an error when the player fails")
+	}
+}
+
+func TestVideoService_GetAllOutputInfoPartialFailure(t *testing.T) {
+	status := http.StatusInternalServerError
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/api/v1/video/hdmi/output/0/":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"data":{"result":{"connector":"hdmi","device":"0","connected":true}}}`))
+		case "/api/v1/video/hdmi/output/1/":
+			w.WriteHeader(status)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClient(Config{Host: server.URL[7:], Password: "password"})
+	client.baseURL = server.URL + "/api/v1"
+
+	// The output that failed is reported alongside the one that worked
+	result, err := client.Video.GetAllOutputInfo()
+	if err != nil {
+		t.Fatalf("GetAllOutputInfo failed: %v", err)
+	}
+	if len(result) != 2 || !result[0].Connected || result[0].Error != "" {
+		t.Fatalf("Expected the working output first, got %+v", result)
+	}
+	if result[1].Connector != "hdmi" || result[1].Device != "1" || !strings.Contains(result[1].Error, "500") {
+		t.Errorf("Expected the failed output with its error, got %+v", result[1])
+	}
+
+	// Rejected credentials still fail the whole call
+	status = http.StatusForbidden
+	if _, err := client.Video.GetAllOutputInfo(); !errors.Is(err, ErrForbidden) {
+		t.Errorf("Expected ErrForbidden, got %v", err)
+	}
+}
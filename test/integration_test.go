@@ -270,14 +270,10 @@ func TestFileCommands(t *testing.T) {
 		// Wait a moment for file to be written
 		time.Sleep(500 * time.Millisecond)
 		
-		// Verify file exists by listing - try different paths
-		jsonOutput, err := runBSCLI(config, "--json", "file", "list", "/storage/sd/")
+		// Verify file exists by listing; /storage/sd and /storage/sd/ are the same request
+		jsonOutput, err := runBSCLI(config, "--json", "file", "list", "/storage/sd")
 		if err != nil {
-			// If that fails, try without trailing slash
-			jsonOutput, err = runBSCLI(config, "--json", "file", "list", "/storage/sd")
-			if err != nil {
-				t.Fatalf("file list after upload failed: %v", err)
-			}
+			t.Fatalf("file list after upload failed: %v", err)
 		}
 		var files []map[string]interface{}
 		json.Unmarshal(jsonOutput, &files)
@@ -658,17 +654,37 @@ func TestJSONConsistency(t *testing.T) {
 		{"diagnostics", "run"},
 		{"diagnostics", "ping", "8.8.8.8"},
 		{"diagnostics", "interfaces"},
+		{"diagnostics", "neighborhood"},
+		{"diagnostics", "pcap", "status"},
+		{"diagnostics", "telnet", "status"},
+		{"diagnostics", "ssh", "status"},
 		{"control", "dws-password", "status"},
 		{"control", "local-dws", "status"},
 		{"registry", "get-all"},
+		{"registry", "recovery-url", "get"},
 		{"logs", "get"},
 		{"logs", "supervisor", "get-level"},
+		{"video", "output-info", "hdmi", "0"},
+		{"video", "modes", "list", "hdmi", "0"},
+		{"video", "modes", "current", "hdmi", "0"},
+		{"video", "power-save", "get", "hdmi", "0"},
+		{"display", "get-all"},
+		{"display", "info"},
+		{"display", "brightness", "get"},
+		{"display", "contrast", "get"},
+		{"display", "volume", "get"},
+		{"display", "power", "get"},
 	}
 
 	for _, cmd := range jsonCommands {
 		t.Run(strings.Join(cmd, "_"), func(t *testing.T) {
 			jsonOutput, err := runBSCLI(config, append([]string{"--json"}, cmd...)...)
 			if err != nil {
+				// Display commands need a Moka display and video commands a
+				// connected output, so they may legitimately be unavailable
+				if cmd[0] == "display" || cmd[0] == "video" {
+					t.Skipf("%v not available on this player: %v", cmd, err)
+				}
 				t.Fatalf("JSON command failed: %v, output: %s", err, jsonOutput)
 			}
 
@@ -689,4 +705,26 @@ func TestJSONConsistency(t *testing.T) {
 			}
 		})
 	}
+	// Mutating commands answer with the success envelope. The registry key
+	// is written and removed again so the player is left unchanged.
+	t.Run("mutating_commands", func(t *testing.T) {
+		testKey := fmt.Sprintf("bscli_json_%d", time.Now().Unix())
+		mutating := []struct {
+			args   []string
+			action string
+		}{
+			{[]string{"registry", "set", "networking", testKey, "1"}, "set"},
+			{[]string{"registry", "delete", "-f", "networking", testKey}, "delete"},
+		}
+
+		for _, m := range mutating {
+			result, err := runBSCLIJSON(config, m.args...)
+			if err != nil {
+				t.Fatalf("%v failed: %v", m.args, err)
+			}
+			if result["success"] != true || result["action"] != m.action {
+				t.Errorf("Expected success envelope with action %q for %v, got %v", m.action, m.args, result)
+			}
+		}
+	})
 }
\ No newline at end of file